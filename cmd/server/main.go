@@ -7,8 +7,10 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -20,34 +22,46 @@ import (
 	s "main.go/internal/api/Struct"
 	"main.go/internal/api/employee"
 	"main.go/internal/api/get"
+	"main.go/internal/api/jobs"
+	"main.go/internal/api/notification"
 	"main.go/internal/api/response"
+	"main.go/internal/api/resumes"
+	"main.go/internal/api/skills"
+	"main.go/internal/api/stream"
 	candid "main.go/internal/api/user"
 	"main.go/internal/api/vacancy"
+	"main.go/internal/api/webhooks"
 	mailer "main.go/internal/email-sender"
+	"main.go/internal/events"
+	"main.go/internal/notify"
+	"main.go/internal/salary"
+	"main.go/internal/scheduler"
 	sqlp "main.go/internal/storage/postSQL"
+	"main.go/internal/webhook"
 )
 
 // @securityDefinitions.apikey ApiKeyAuth
 // @in header
 // @name Authorization
 func main() {
-	mailer := mailer.New(
-		os.Getenv("SMTP_HOSTING"),
-		465,
-		os.Getenv("SMTP_DOMEN"),
-		os.Getenv("SMTP_PASSWORD"),
-		os.Getenv("SMTP_DOMEN"),
-		2, // Количество горутин-воркеров
-	)
-
-	// Graceful shutdown: закрываем mailer при завершении
-	go func() {
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-		<-sigChan
-		mailer.Close()
-		os.Exit(0)
-	}()
+	// MAIL_TRANSPORT: smtp (по умолчанию), file - письма пишутся .eml файлами в MAIL_DIR, log - только в лог, memory - в память
+	transport, err := mailer.NewTransport(os.Getenv("MAIL_TRANSPORT"), mailer.TransportConfig{
+		Host:     os.Getenv("SMTP_HOSTING"),
+		Port:     envInt("SMTP_PORT", 465),
+		Username: os.Getenv("SMTP_DOMEN"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		Dir:      os.Getenv("MAIL_DIR"),
+	})
+	if err != nil {
+		log.Fatalln("Произошла ошибка в инициализации отправки писем: ", err.Error())
+	}
+	mailSender := mailer.New(transport, os.Getenv("SMTP_DOMEN"), mailer.Config{
+		Workers:        envInt("MAIL_WORKERS", 2),                                            // Количество горутин-воркеров, у каждого своё соединение
+		RatePerSecond:  float64(envInt("MAIL_RATE_PER_SECOND", 0)),                           // 0 - без ограничения
+		PerDomainLimit: envInt("MAIL_PER_DOMAIN_LIMIT", 0),                                   // 0 - без ограничения
+		IdleTimeout:    time.Duration(envInt("MAIL_IDLE_TIMEOUT_SECONDS", 30)) * time.Second, // после простоя соединение закрывается
+	})
+
 	host := os.Getenv("DB_DOMEN")
 	port := 5432
 	user := os.Getenv("DB_USER")
@@ -62,6 +76,45 @@ func main() {
 	}
 	defer storage.Close()
 
+	// Письма пишутся в таблицу email_outbox в транзакции запроса, а отсюда уже отправляются
+	outbox := mailer.NewOutbox(storage, mailSender, envInt("EMAIL_MAX_ATTEMPTS", 8))
+	go outbox.Run()
+
+	// События для вебхуков работодателей пишутся в webhook_deliveries в транзакции запроса, а отсюда доставляются
+	webhookDispatcher := webhook.NewDispatcher(storage, nil, envInt("WEBHOOK_MAX_ATTEMPTS", 10))
+	if webhook.PrivateTargetsAllowed() {
+		log.Printf("WEBHOOK_ALLOW_PRIVATE is on: webhooks may target loopback and private addresses")
+	}
+	go webhookDispatcher.Run()
+
+	// Периодические задачи. Каждую задачу регистрирует пакет, которому она принадлежит
+	jobScheduler := scheduler.New(storage, time.Duration(envInt("JOB_HISTORY_RETENTION_DAYS", 14))*24*time.Hour)
+	// Окончательное удаление записей, которые лежат в "корзине" дольше срока хранения
+	retentionDays := envInt("SOFT_DELETE_RETENTION_DAYS", 30)
+	jobScheduler.Register(scheduler.Job{
+		Name:        "purge_deleted",
+		Description: "Окончательно стирает записи, которые лежат в корзине дольше срока хранения",
+		Schedule:    "@hourly",
+		Run:         PurgeDeletedRecords(time.Duration(retentionDays) * 24 * time.Hour),
+	})
+	// Сводки новых откликов для работодателей и письма по сохранённым поискам соискателей
+	notify.RegisterJobs(jobScheduler)
+	// Снятие с публикации вакансий с истёкшим сроком и напоминания о продлении
+	vacancy.RegisterJobs(jobScheduler)
+	go jobScheduler.Run()
+
+	// Graceful shutdown: дожидаемся окончания запущенных задач, отправки текущей пачки писем и закрываем mailer при завершении
+	go func() {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+		<-sigChan
+		jobScheduler.Close()
+		webhookDispatcher.Close()
+		outbox.Close()
+		mailSender.Close()
+		os.Exit(0)
+	}()
+
 	gin.SetMode(gin.ReleaseMode)
 
 	router := gin.Default()
@@ -79,15 +132,42 @@ func main() {
 		// ! Удаление статуса
 		apiV1.DELETE("/adm/status", AuthMiddleWare(), MakeTransaction(storage), DeleteStatus(storage))
 
+		// * ----------------------- Доверенные статусы (вакансии публикуются без модерации) -----------------------
+		apiV1.GET("/adm/status/trusted", AuthMiddleWare(), MakeTransaction(storage), GetTrustedStatuses(storage))
+		apiV1.PATCH("/adm/status/trusted", AuthMiddleWare(), MakeTransaction(storage), PatchStatusTrusted(storage))
+
+		// * ----------------------- Модерация вакансий -----------------------
+		apiV1.GET("/adm/moderation", AuthMiddleWare(), MakeTransaction(storage), vacancy.GetModerationQueue(storage))
+		apiV1.POST("/adm/moderation/approve", AuthMiddleWare(), MakeTransaction(storage), vacancy.ApproveVacancy(storage))
+		apiV1.POST("/adm/moderation/reject", AuthMiddleWare(), MakeTransaction(storage), vacancy.RejectVacancy(storage))
+
 		// ! Удаление опыта
 		apiV1.DELETE("/adm/exp", AuthMiddleWare(), MakeTransaction(storage), DeleteExperience(storage))
 
+		// * ----------------------- Удалённые записи -----------------------
+		apiV1.GET("/adm/vac/deleted", AuthMiddleWare(), MakeTransaction(storage), vacancy.GetDeletedVacancies(storage))
+		apiV1.GET("/adm/user/deleted", AuthMiddleWare(), MakeTransaction(storage), candid.GetDeletedCandidates(storage))
+		apiV1.GET("/adm/emp/deleted", AuthMiddleWare(), MakeTransaction(storage), employee.GetDeletedEmployers(storage))
+
+		// ? ----------------------- Восстановление удалённых записей -----------------------
+		apiV1.PATCH("/adm/vac/restore", AuthMiddleWare(), MakeTransaction(storage), vacancy.RestoreVacancy(storage))
+		apiV1.PATCH("/adm/user/restore", AuthMiddleWare(), MakeTransaction(storage), candid.RestoreUser(storage))
+		apiV1.PATCH("/adm/emp/restore", AuthMiddleWare(), MakeTransaction(storage), employee.RestoreUser(storage))
+
 		// ? ----------------------- Обновить статус работодателя -----------------------
 		apiV1.PATCH("/adm/emp", AuthMiddleWare(), MakeTransaction(storage), employee.PatchEmployerStatus(storage))
 
 		// * ----------------------- Получить список всех работодателей -----------------------
 		apiV1.GET("/adm/emp", AuthMiddleWare(), MakeTransaction(storage), employee.GetAllEmployee(storage))
 
+		// * ----------------------- Предпросмотр шаблона письма -----------------------
+		apiV1.GET("/adm/email/preview", AuthMiddleWare(), PreviewEmailTemplate())
+
+		// * ----------------------- Фоновые задачи: список, история запусков и ручной запуск -----------------------
+		apiV1.GET("/adm/jobs", AuthMiddleWare(), MakeTransaction(storage), jobs.GetJobs(storage, jobScheduler))
+		apiV1.GET("/adm/jobs/runs", AuthMiddleWare(), MakeTransaction(storage), jobs.GetJobRuns(storage))
+		apiV1.POST("/adm/jobs/run", AuthMiddleWare(), jobs.RunJob(jobScheduler))
+
 		// * Проверка токена на валидность
 		apiV1.GET("/adm/token", CheckToken())
 
@@ -114,6 +194,36 @@ func main() {
 		// ? ----------------------- Обновить данные работодателя -----------------------
 		apiV1.PUT("/emp", AuthMiddleWare(), MakeTransaction(storage), employee.PutEmployeeInfo(storage))
 
+		// * ----------------------- Настройки уведомлений о новых откликах -----------------------
+		apiV1.GET("/emp/notify", AuthMiddleWare(), MakeTransaction(storage), employee.GetNotifySettings(storage))
+
+		// ? ----------------------- Обновить настройки уведомлений о новых откликах -----------------------
+		apiV1.PUT("/emp/notify", AuthMiddleWare(), MakeTransaction(storage), employee.PutNotifySettings(storage))
+
+		// * ----------------------- Вебхуки работодателя -----------------------
+		apiV1.GET("/emp/webhook", AuthMiddleWare(), MakeTransaction(storage), webhooks.GetWebhooks(storage))
+
+		// * ----------------------- Журнал доставок вебхуков -----------------------
+		apiV1.GET("/emp/webhook/deliveries", AuthMiddleWare(), MakeTransaction(storage), webhooks.GetDeliveries(storage))
+
+		// ^ ----------------------- Добавить вебхук -----------------------
+		apiV1.POST("/emp/webhook", AuthMiddleWare(), MakeTransaction(storage), webhooks.PostWebhook(storage))
+
+		// ^ ----------------------- Повторить доставку вебхука -----------------------
+		apiV1.POST("/emp/webhook/redeliver", AuthMiddleWare(), MakeTransaction(storage), webhooks.Redeliver(storage))
+
+		// ! ----------------------- Удалить вебхук -----------------------
+		apiV1.DELETE("/emp/webhook", AuthMiddleWare(), MakeTransaction(storage), webhooks.DeleteWebhook(storage))
+
+		// * ----------------------- Поиск резюме -----------------------
+		apiV1.GET("/emp/resume/search", AuthMiddleWare(), MakeTransaction(storage), resumes.SearchResumes(storage))
+
+		// * ----------------------- Запросы контактов соискателей -----------------------
+		apiV1.GET("/emp/contact", AuthMiddleWare(), MakeTransaction(storage), resumes.GetEmployerContactRequests(storage))
+
+		// ^ ----------------------- Запросить контакты соискателя -----------------------
+		apiV1.POST("/emp/contact", AuthMiddleWare(), MakeTransaction(storage), resumes.PostContactRequest(storage))
+
 		// ? ----------------------- Обновить статус отклика на вакансию -----------------------
 		apiV1.PATCH("/vac/response", AuthMiddleWare(), MakeTransaction(storage), response.PatchResponseStatus(storage))
 
@@ -124,11 +234,62 @@ func main() {
 		// ^ ----------------------- Добавить -----------------------
 		apiV1.POST("/exp", AuthMiddleWare(), MakeTransaction(storage), PostNewExperience(storage))
 
+		// & ---------------------------------------------- Валюты ----------------------------------------------
+		// * ----------------------- Все валюты и их курсы -----------------------
+		apiV1.GET("/currency", MakeTransaction(storage), GetAllCurrencies(storage))
+
+		// ? ----------------------- Добавить валюту или обновить курс -----------------------
+		apiV1.PUT("/adm/currency", AuthMiddleWare(), MakeTransaction(storage), PutCurrency(storage))
+
+		// ! ----------------------- Удалить валюту -----------------------
+		apiV1.DELETE("/adm/currency", AuthMiddleWare(), MakeTransaction(storage), DeleteCurrency(storage))
+
+		// & ---------------------------------------------- Атрибуты вакансий ----------------------------------------------
+		// * ----------------------- Типы занятости, графики и форматы работы -----------------------
+		apiV1.GET("/employment", MakeTransaction(storage), GetDictionary(storage, sqlp.EmploymentTypes))
+		apiV1.GET("/schedule", MakeTransaction(storage), GetDictionary(storage, sqlp.Schedules))
+		apiV1.GET("/format", MakeTransaction(storage), GetDictionary(storage, sqlp.WorkFormats))
+
+		// ^ ----------------------- Добавить значение -----------------------
+		apiV1.POST("/adm/employment", AuthMiddleWare(), MakeTransaction(storage), PostDictionaryValue(storage, sqlp.EmploymentTypes))
+		apiV1.POST("/adm/schedule", AuthMiddleWare(), MakeTransaction(storage), PostDictionaryValue(storage, sqlp.Schedules))
+		apiV1.POST("/adm/format", AuthMiddleWare(), MakeTransaction(storage), PostDictionaryValue(storage, sqlp.WorkFormats))
+
+		// ! ----------------------- Удалить значение -----------------------
+		apiV1.DELETE("/adm/employment", AuthMiddleWare(), MakeTransaction(storage), DeleteDictionaryValue(storage, sqlp.EmploymentTypes))
+		apiV1.DELETE("/adm/schedule", AuthMiddleWare(), MakeTransaction(storage), DeleteDictionaryValue(storage, sqlp.Schedules))
+		apiV1.DELETE("/adm/format", AuthMiddleWare(), MakeTransaction(storage), DeleteDictionaryValue(storage, sqlp.WorkFormats))
+
+		// & ---------------------------------------------- Навыки ----------------------------------------------
+		// * ----------------------- Все навыки с синонимами -----------------------
+		apiV1.GET("/skills", MakeTransaction(storage), skills.GetSkills(storage))
+
+		// * ----------------------- Автодополнение навыков -----------------------
+		apiV1.GET("/skills/suggest", MakeTransaction(storage), skills.SuggestSkills(storage))
+
+		// ^ ----------------------- Добавить навык -----------------------
+		apiV1.POST("/adm/skills", AuthMiddleWare(), MakeTransaction(storage), skills.PostSkill(storage))
+
+		// ? ----------------------- Переименовать навык -----------------------
+		apiV1.PATCH("/adm/skills", AuthMiddleWare(), MakeTransaction(storage), skills.RenameSkill(storage))
+
+		// ! ----------------------- Удалить навык -----------------------
+		apiV1.DELETE("/adm/skills", AuthMiddleWare(), MakeTransaction(storage), skills.DeleteSkill(storage))
+
+		// ^ ----------------------- Добавить синоним навыка -----------------------
+		apiV1.POST("/adm/skills/synonym", AuthMiddleWare(), MakeTransaction(storage), skills.PostSkillSynonym(storage))
+
+		// ! ----------------------- Удалить синоним навыка -----------------------
+		apiV1.DELETE("/adm/skills/synonym", AuthMiddleWare(), MakeTransaction(storage), skills.DeleteSkillSynonym(storage))
+
+		// ^ ----------------------- Объединить навыки -----------------------
+		apiV1.POST("/adm/skills/merge", AuthMiddleWare(), MakeTransaction(storage), skills.MergeSkills(storage))
+
 		// & ---------------------------------------------- Соискатели ----------------------------------------------
 
-		apiV1.GET("/user/recover", MakeTransaction(storage), candid.RecoverPassword(mailer))
+		apiV1.GET("/user/recover", MakeTransaction(storage), candid.RecoverPassword(storage))
 
-		apiV1.GET("/user/pr", MakeTransaction(storage), candid.ResetPasswordForUser(mailer))
+		apiV1.GET("/user/pr", MakeTransaction(storage), candid.ResetPasswordForUser(storage))
 
 		apiV1.GET("/user/confirm-email", MakeTransaction(storage), candid.CheckToken(storage))
 
@@ -147,8 +308,11 @@ func main() {
 		// * ----------------------- Все отклики пользователя -----------------------
 		apiV1.GET("/user/response", AuthMiddleWare(), MakeTransaction(storage), candid.GetAllUserResponse(storage))
 
+		// * ----------------------- Настройки уведомлений пользователя -----------------------
+		apiV1.GET("/user/notify", AuthMiddleWare(), MakeTransaction(storage), candid.GetNotifySettings(storage))
+
 		// ^ ----------------------- Добавить/зарегестрировать нового пользователя -----------------------
-		apiV1.POST("/user", MakeTransaction(storage), candid.PostNewCandidate(storage, mailer))
+		apiV1.POST("/user", MakeTransaction(storage), candid.PostNewCandidate(storage))
 
 		// ^ ----------------------- Добавить резюме -----------------------
 		apiV1.POST("/user/resume", AuthMiddleWare(), MakeTransaction(storage), candid.PostNewResume(storage))
@@ -159,12 +323,62 @@ func main() {
 		// ? ----------------------- Обновить данные пользователя -----------------------
 		apiV1.PUT("/user", AuthMiddleWare(), MakeTransaction(storage), candid.PutCandidateInfo(storage))
 
+		// ? ----------------------- Обновить настройки уведомлений пользователя -----------------------
+		apiV1.PUT("/user/notify", AuthMiddleWare(), MakeTransaction(storage), candid.PutNotifySettings(storage))
+
+		// ? ----------------------- Видимость резюме для работодателей -----------------------
+		apiV1.PATCH("/user/resume/visibility", AuthMiddleWare(), MakeTransaction(storage), candid.PatchResumeVisibility(storage))
+
+		// * ----------------------- Запросы контактов от работодателей -----------------------
+		apiV1.GET("/user/contact", AuthMiddleWare(), MakeTransaction(storage), resumes.GetCandidateContactRequests(storage))
+
+		// ? ----------------------- Ответить на запрос контактов -----------------------
+		apiV1.PATCH("/user/contact", AuthMiddleWare(), MakeTransaction(storage), resumes.AnswerContactRequest(storage))
+
+		// * ----------------------- Рекомендованные вакансии -----------------------
+		apiV1.GET("/user/recommendations", AuthMiddleWare(), MakeTransaction(storage), candid.GetRecommendations(storage))
+
+		// * ----------------------- Сохранённые поиски -----------------------
+		apiV1.GET("/user/search", AuthMiddleWare(), MakeTransaction(storage), candid.GetSavedSearches(storage))
+
+		// * ----------------------- Отписка от писем по сохранённому поиску (ссылка из письма) -----------------------
+		apiV1.GET("/user/search/unsubscribe", MakeTransaction(storage), candid.UnsubscribeSavedSearch(storage))
+
+		// ^ ----------------------- Сохранить поиск -----------------------
+		apiV1.POST("/user/search", AuthMiddleWare(), MakeTransaction(storage), candid.PostSavedSearch(storage))
+
+		// ! ----------------------- Удалить сохранённый поиск -----------------------
+		apiV1.DELETE("/user/search", AuthMiddleWare(), MakeTransaction(storage), candid.DeleteSavedSearch(storage))
+
 		// ? ----------------------- Обновить данные резюме пользователя -----------------------
 		apiV1.PUT("/user/resume", AuthMiddleWare(), MakeTransaction(storage), candid.PutCandidateResume(storage))
 
 		// ! ----------------------- Удалить резюме -----------------------
 		apiV1.DELETE("/user/resume", AuthMiddleWare(), MakeTransaction(storage), candid.DeleteResume(storage))
 
+		// ^ ----------------------- Разделы резюме: опыт работы, образование, языки, навыки -----------------------
+		apiV1.POST("/user/resume/work", AuthMiddleWare(), MakeTransaction(storage), candid.PostResumeWork(storage))
+		apiV1.POST("/user/resume/education", AuthMiddleWare(), MakeTransaction(storage), candid.PostResumeEducation(storage))
+		apiV1.POST("/user/resume/language", AuthMiddleWare(), MakeTransaction(storage), candid.PostResumeLanguage(storage))
+		apiV1.POST("/user/resume/skill", AuthMiddleWare(), MakeTransaction(storage), candid.PostResumeSkill(storage))
+
+		// ? ----------------------- Изменить записи разделов резюме -----------------------
+		apiV1.PUT("/user/resume/work", AuthMiddleWare(), MakeTransaction(storage), candid.PutResumeWork(storage))
+		apiV1.PUT("/user/resume/education", AuthMiddleWare(), MakeTransaction(storage), candid.PutResumeEducation(storage))
+		apiV1.PUT("/user/resume/language", AuthMiddleWare(), MakeTransaction(storage), candid.PutResumeLanguage(storage))
+
+		// ? ----------------------- Порядок записей в разделах резюме -----------------------
+		apiV1.PUT("/user/resume/work/order", AuthMiddleWare(), MakeTransaction(storage), candid.PutResumeOrder(storage, "resume_work", "id"))
+		apiV1.PUT("/user/resume/education/order", AuthMiddleWare(), MakeTransaction(storage), candid.PutResumeOrder(storage, "resume_education", "id"))
+		apiV1.PUT("/user/resume/language/order", AuthMiddleWare(), MakeTransaction(storage), candid.PutResumeOrder(storage, "resume_languages", "id"))
+		apiV1.PUT("/user/resume/skill/order", AuthMiddleWare(), MakeTransaction(storage), candid.PutResumeOrder(storage, "resume_skills", "skill_id"))
+
+		// ! ----------------------- Удалить записи разделов резюме -----------------------
+		apiV1.DELETE("/user/resume/work", AuthMiddleWare(), MakeTransaction(storage), candid.DeleteResumeItem(storage, "resume_work"))
+		apiV1.DELETE("/user/resume/education", AuthMiddleWare(), MakeTransaction(storage), candid.DeleteResumeItem(storage, "resume_education"))
+		apiV1.DELETE("/user/resume/language", AuthMiddleWare(), MakeTransaction(storage), candid.DeleteResumeItem(storage, "resume_languages"))
+		apiV1.DELETE("/user/resume/skill", AuthMiddleWare(), MakeTransaction(storage), candid.DeleteResumeSkill(storage))
+
 		// ! ----------------------- Удаление отклика на вакансию -----------------------
 		apiV1.DELETE("/vac/response", AuthMiddleWare(), MakeTransaction(storage), response.DeleteResponse(storage))
 
@@ -182,7 +396,7 @@ func main() {
 		apiV1.GET("/vac/time", MakeTransaction(storage), vacancy.GetVacancyWithLimitByTime(storage))
 
 		// * ----------------------- Получить информацию о вакансии -----------------------
-		apiV1.GET("/vac/info", MakeTransaction(storage), vacancy.GetVacancyInfoByID(storage))
+		apiV1.GET("/vac/info", OptionalAuthMiddleWare(), MakeTransaction(storage), vacancy.GetVacancyInfoByID(storage))
 
 		// * ----------------------- Количество вакансий в системе -----------------------
 		apiV1.GET("/vac/num", MakeTransaction(storage), vacancy.GetVacanciesNumbers(storage))
@@ -192,24 +406,57 @@ func main() {
 		// * ----------------------- Все отклики на вакансию -----------------------
 		apiV1.GET("/vac/response", AuthMiddleWare(), MakeTransaction(storage), response.GetAllResponseByVacancy(storage))
 
+		// * ----------------------- Редакции вакансии и их сравнение -----------------------
+		apiV1.GET("/vac/revisions", AuthMiddleWare(), MakeTransaction(storage), vacancy.GetVacancyRevisions(storage))
+		apiV1.GET("/vac/revisions/diff", AuthMiddleWare(), MakeTransaction(storage), vacancy.DiffVacancyRevisions(storage))
+
 		// ^ ----------------------- Добавить новую вакансию -----------------------
 		apiV1.POST("/vac", AuthMiddleWare(), MakeTransaction(storage), vacancy.PostNewVacancy(storage))
 
 		// ? ----------------------- Обновить вакансии -----------------------
 		apiV1.PUT("/vac", AuthMiddleWare(), MakeTransaction(storage), vacancy.PutVacancy(storage))
 
-		// ? ----------------------- Обновить видимость вакансии -----------------------
-		apiV1.PATCH("/vac/visible", AuthMiddleWare(), MakeTransaction(storage), vacancy.PatchVisibleVacancy(storage))
+		// ? ----------------------- Изменить состояние вакансии -----------------------
+		apiV1.PATCH("/vac/state", AuthMiddleWare(), MakeTransaction(storage), vacancy.PatchVacancyState(storage))
+
+		// ? ----------------------- Продлить срок публикации вакансии -----------------------
+		apiV1.PATCH("/vac/renew", AuthMiddleWare(), MakeTransaction(storage), vacancy.RenewVacancy(storage))
 
 		// ! ----------------------- Удаление вакансии -----------------------
 		apiV1.DELETE("/vac", AuthMiddleWare(), MakeTransaction(storage), vacancy.DeleteVacancy(storage))
 
+		// & ---------------------------------------------- Уведомления ----------------------------------------------
+		// * ----------------------- Уведомления пользователя -----------------------
+		apiV1.GET("/notifications", AuthMiddleWare(), MakeTransaction(storage), notification.GetNotifications(storage))
+
+		// * ----------------------- Количество непрочитанных уведомлений -----------------------
+		apiV1.GET("/notifications/unread", AuthMiddleWare(), MakeTransaction(storage), notification.GetUnreadCount(storage))
+
+		// ? ----------------------- Отметить уведомление прочитанным -----------------------
+		apiV1.PATCH("/notifications/read", AuthMiddleWare(), MakeTransaction(storage), notification.MarkRead(storage))
+
+		// ? ----------------------- Отметить все уведомления прочитанными -----------------------
+		apiV1.PATCH("/notifications/read-all", AuthMiddleWare(), MakeTransaction(storage), notification.MarkAllRead(storage))
+
+		// & ---------------------------------------------- События ----------------------------------------------
+		// * ----------------------- Поток событий пользователя (SSE). Без транзакции, так как соединение долгоживущее -----------------------
+		apiV1.GET("/events", stream.TokenFromQuery(), AuthMiddleWare(), stream.Events())
+
 	}
 
 	apiV1.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	router.Run(":8080")
 }
 
+// envInt читает целое положительное число из переменной окружения, иначе возвращает def
+func envInt(name string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return def
+	}
+	return value
+}
+
 func MakeTransaction(storage *sqlx.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		tx, err := storage.Beginx()
@@ -225,6 +472,7 @@ func MakeTransaction(storage *sqlx.DB) gin.HandlerFunc {
 			if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
 				log.Printf("failed to rollback transaction: %v", err)
 			}
+			events.Discard(tx)
 		}()
 		ctx.Set("tx", tx)
 		ctx.Next()
@@ -233,10 +481,34 @@ func MakeTransaction(storage *sqlx.DB) gin.HandlerFunc {
 			if err := tx.Commit(); err != nil {
 				return
 			}
+			// события об изменениях из запроса уходят клиентам только после коммита
+			events.Commit(tx)
 		}
 	}
 }
 
+// PurgeDeletedRecords окончательно стирает записи, удалённые раньше, чем retention назад
+func PurgeDeletedRecords(retention time.Duration) func(storage *sqlx.DB) error {
+	return func(storage *sqlx.DB) error {
+		tx, err := storage.Beginx()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		rows, err := sqlp.PurgeDeleted(tx, time.Now().Add(-retention))
+		if err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		if rows > 0 {
+			log.Printf("purged %d deleted records", rows)
+		}
+		return nil
+	}
+}
+
 // @Summary Получение списка опыта
 // @Description Возвращает список всех опыта, который будет использоваться в дальнейшем. Имееют доступ все.
 // @Tags Admin
@@ -437,6 +709,121 @@ func DeleteStatus(storage *sqlx.DB) gin.HandlerFunc {
 	}
 }
 
+// @Summary Список доверенных статусов
+// @Description Возвращает статусы работодателей, вакансии которых публикуются без модерации. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} s.GetAllStatuses "Возвращает массив доверенных статусов"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/status/trusted [get]
+func GetTrustedStatuses(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "ADMIN" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		data, err := sqlp.GetTrustedStatuses(tx)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "OK!",
+			"Data":   data,
+		})
+	}
+}
+
+// @Summary Сделать статус доверенным
+// @Description Вакансии работодателей с доверенным статусом публикуются без модерации, даже если она включена (VACANCY_MODERATION=on). Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Produce json
+// @Param StatusID query int true "ID статуса"
+// @Param Trusted query bool true "true - публиковать без модерации, false - через модерацию"
+// @Success 200 {object} s.StatusInfo "Возвращает статус и краткую информацию "
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса или статуса нету в системе"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/status/trusted [patch]
+func PatchStatusTrusted(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "ADMIN" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		statusID, err := strconv.Atoi(ctx.Query("StatusID"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при попытке получить ID статуса! проверьте его и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		trusted, err := strconv.ParseBool(ctx.Query("Trusted"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Параметр Trusted должен быть true или false",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		if _, err := sqlp.GetStatusByID(tx, statusID); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Такого статуса нету в системе! Перепроверьте данные и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		if err := sqlp.SetStatusTrusted(tx, statusID, trusted); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "OK!",
+			"Info":   "данные успешно обновлены!",
+		})
+	}
+}
+
 // @Summary Удаление опыта
 // @Description Позволяет удалить запись из системы. Доступ имеют только пользователи роли ADMIN
 // @Security ApiKeyAuth
@@ -484,6 +871,336 @@ func DeleteExperience(storage *sqlx.DB) gin.HandlerFunc {
 	}
 }
 
+// @Summary Получение значений справочника атрибутов вакансии
+// @Description Возвращает значения справочника: /employment - типы занятости, /schedule - графики работы, /format - форматы работы (офис, гибрид, удалённо). ID значений передаются в вакансии и в фильтры поиска /vac/search. Имееют доступ все.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} s.GetAllStatuses "Возвращает массив значений справочника"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если произошла на стороне сервера."
+// @Router /employment [get]
+// @Router /schedule [get]
+// @Router /format [get]
+func GetDictionary(storage *sqlx.DB, table string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		data, err := sqlp.GetDictionary(tx, table)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+			"Data":   data,
+		})
+	}
+}
+
+// @Summary Добавить значение в справочник атрибутов вакансии
+// @Description Добавляет новое значение в справочник: /adm/employment - типы занятости, /adm/schedule - графики работы, /adm/format - форматы работы. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Produce json
+// @Param Name query string true "Наименование нового значения"
+// @Success 200 {object} s.Ok "Возвращает статус 'Ok!'"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если наименование не передано"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/employment [post]
+// @Router /adm/schedule [post]
+// @Router /adm/format [post]
+func PostDictionaryValue(storage *sqlx.DB, table string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "ADMIN" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		name := strings.TrimSpace(ctx.Query("Name"))
+		if name == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Укажите наименование значения (Name)",
+			})
+			return
+		}
+		if err := sqlp.PostDictionaryValue(tx, table, name); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+		})
+	}
+}
+
+// @Summary Удалить значение из справочника атрибутов вакансии
+// @Description Удаляет значение из справочника: /adm/employment - типы занятости, /adm/schedule - графики работы, /adm/format - форматы работы. У вакансий с этим значением атрибут становится пустым. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Produce json
+// @Param Name query string true "Наименование удаляемого значения"
+// @Success 200 {object} s.Ok "Возвращает статус 'Ok!'"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если наименование не передано"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/employment [delete]
+// @Router /adm/schedule [delete]
+// @Router /adm/format [delete]
+func DeleteDictionaryValue(storage *sqlx.DB, table string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "ADMIN" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		name := strings.TrimSpace(ctx.Query("Name"))
+		if name == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Укажите наименование значения (Name)",
+			})
+			return
+		}
+		if err := sqlp.DeleteDictionaryValue(tx, table, name); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+		})
+	}
+}
+
+// @Summary Получение списка валют
+// @Description Возвращает валюты, в которых можно указывать зарплату вакансии, и их курсы к рублю. По курсам зарплаты пересчитываются при поиске. Имееют доступ все.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} s.ResponseCurrencies "Возвращает массив валют"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если произошла на стороне сервера."
+// @Router /currency [get]
+func GetAllCurrencies(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		data, err := sqlp.GetCurrencies(tx)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+			"Data":   data,
+		})
+	}
+}
+
+// @Summary Добавить валюту или обновить курс
+// @Description Добавляет валюту или меняет её курс - сколько рублей стоит одна единица валюты. Курс рубля всегда 1. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Produce json
+// @Param Code query string true "Код валюты из трёх латинских букв, например USD"
+// @Param Rate query number true "Курс к рублю"
+// @Success 200 {object} s.Ok "Возвращает статус 'Ok!'"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если код или курс указаны неверно"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/currency [put]
+func PutCurrency(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "ADMIN" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		code := strings.ToUpper(ctx.Query("Code"))
+		rate, err := strconv.ParseFloat(ctx.Query("Rate"), 64)
+		if len(code) != 3 || err != nil || rate <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Укажите код валюты из трёх букв (Code) и курс больше нуля (Rate)",
+			})
+			return
+		}
+		if code == salary.BaseCurrency && rate != 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Курс базовой валюты изменить нельзя, он всегда равен 1",
+			})
+			return
+		}
+		if err := sqlp.PutCurrency(tx, code, rate); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+		})
+	}
+}
+
+// @Summary Удаление валюты
+// @Description Позволяет удалить валюту из системы. Валюту, в которой указана зарплата хотя бы одной вакансии, и рубль удалить нельзя. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Produce json
+// @Param Code query string true "Код валюты"
+// @Success 200 {object} s.StatusInfo "Возвращает статус и краткую информацию "
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если валюту нельзя удалить"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Router /adm/currency [delete]
+func DeleteCurrency(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "ADMIN" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		code := strings.ToUpper(ctx.Query("Code"))
+		if code == salary.BaseCurrency {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Базовую валюту удалить нельзя",
+			})
+			return
+		}
+		if err := sqlp.DeleteCurrency(tx, code); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Не удалось удалить валюту",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "OK!",
+			"Info":   "данные успешно удалены!",
+		})
+	}
+}
+
+// @Summary Предпросмотр шаблона письма
+// @Description Позволяет посмотреть, как выглядит письмо, собранное по шаблону на тестовых данных. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Produce json
+// @Produce html
+// @Param Name query string true "Имя шаблона письма, например confirm_email. Если шаблона нет, то в ошибке будет список всех доступных"
+// @Param Locale query string false "Язык письма: ru или en. По умолчанию ru"
+// @Param Format query string false "html - вернуть готовую HTML-страницу письма, а не JSON"
+// @Success 200 {object} mailer.Email "Возвращает тему, HTML и текстовую версию письма"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если шаблона с таким именем нет"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Router /adm/email/preview [get]
+func PreviewEmailTemplate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "ADMIN" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		locale := ctx.Query("Locale")
+		if locale == "" {
+			locale = mailer.DefaultLocale
+		}
+		email, err := mailer.RenderSample(ctx.Query("Name"), locale)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при сборке письма по шаблону",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		if ctx.Query("Format") == "html" {
+			ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(email.HTML))
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{
+			"Status":  "Ok!",
+			"Subject": email.Subject,
+			"HTML":    email.HTML,
+			"Text":    email.Text,
+		})
+	}
+}
+
 // @Summary Проверка токена
 // @Description Позволяет проверить токен пользователя на актуальность
 // @Tags Admin
@@ -580,3 +1297,16 @@ func AuthMiddleWare() gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+// OptionalAuthMiddleWare - для публичных методов, ответ которых зависит от пользователя.
+// Без заголовка Authorization запрос проходит анонимно, с заголовком токен проверяется как в AuthMiddleWare
+func OptionalAuthMiddleWare() gin.HandlerFunc {
+	auth := AuthMiddleWare()
+	return func(ctx *gin.Context) {
+		if ctx.GetHeader("Authorization") == "" {
+			ctx.Next()
+			return
+		}
+		auth(ctx)
+	}
+}
@@ -0,0 +1,65 @@
+// webhook-receiver - простой получатель вебхуков для локальной проверки.
+// Печатает каждый пришедший запрос и проверяет подпись ключом из WEBHOOK_SECRET.
+// Если задан WEBHOOK_FAIL_STATUS, то отвечает этим кодом, чтобы проверить повторные попытки.
+//
+// Сервер по умолчанию не отправляет вебхуки на loopback и частные адреса. Чтобы зарегистрировать
+// http://localhost:9090/webhook, сервер нужно запустить с WEBHOOK_ALLOW_PRIVATE=on (только для разработки)
+//
+//	WEBHOOK_SECRET=whsec_... go run ./cmd/webhook-receiver -addr :9090
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"main.go/internal/webhook"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "адрес, на котором слушать запросы")
+	flag.Parse()
+
+	secret := os.Getenv("WEBHOOK_SECRET")
+	failStatus, _ := strconv.Atoi(os.Getenv("WEBHOOK_FAIL_STATUS"))
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		verified := "not checked (WEBHOOK_SECRET is empty)"
+		if secret != "" {
+			if err := webhook.Verify(secret, r.Header.Get(webhook.SignatureHeader), body, 5*time.Minute); err != nil {
+				log.Printf("delivery %s: invalid signature: %v", r.Header.Get(webhook.DeliveryHeader), err)
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			verified = "ok"
+		}
+
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, body, "", "  "); err != nil {
+			pretty.Write(body)
+		}
+		log.Printf("delivery %s, event %s, signature %s\n%s",
+			r.Header.Get(webhook.DeliveryHeader), r.Header.Get(webhook.EventHeader), verified, pretty.String())
+
+		if failStatus != 0 {
+			http.Error(w, "fail requested by WEBHOOK_FAIL_STATUS", failStatus)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Printf("webhook receiver is listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS employer_deleted_at_idx;
+DROP INDEX IF EXISTS candidates_deleted_at_idx;
+DROP INDEX IF EXISTS vacancy_deleted_at_idx;
+
+ALTER TABLE employer DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE candidates DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE vacancy DROP COLUMN IF EXISTS deleted_at;
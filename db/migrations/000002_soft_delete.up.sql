@@ -0,0 +1,7 @@
+ALTER TABLE vacancy ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+ALTER TABLE candidates ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+ALTER TABLE employer ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS vacancy_deleted_at_idx ON vacancy (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS candidates_deleted_at_idx ON candidates (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS employer_deleted_at_idx ON employer (deleted_at) WHERE deleted_at IS NOT NULL;
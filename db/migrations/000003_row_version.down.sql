@@ -0,0 +1,4 @@
+ALTER TABLE resume DROP COLUMN IF EXISTS version;
+ALTER TABLE employer DROP COLUMN IF EXISTS version;
+ALTER TABLE candidates DROP COLUMN IF EXISTS version;
+ALTER TABLE vacancy DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+ALTER TABLE vacancy ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE candidates ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE employer ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE resume ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
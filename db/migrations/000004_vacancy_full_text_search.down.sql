@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS vacancy_search_vector_idx;
+
+DROP TRIGGER IF EXISTS employer_search_vector_trigger ON employer;
+DROP FUNCTION IF EXISTS employer_search_vector_update();
+DROP TRIGGER IF EXISTS vacancy_search_vector_trigger ON vacancy;
+DROP FUNCTION IF EXISTS vacancy_search_vector_update();
+DROP FUNCTION IF EXISTS vacancy_search_vector(vacancy);
+
+ALTER TABLE vacancy DROP COLUMN IF EXISTS search_vector;
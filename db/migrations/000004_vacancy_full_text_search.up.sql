@@ -0,0 +1,36 @@
+ALTER TABLE vacancy ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+-- Название работодателя лежит в другой таблице, поэтому вектор собирается триггером, а не generated-колонкой
+CREATE OR REPLACE FUNCTION vacancy_search_vector(vac vacancy) RETURNS tsvector AS $$
+    SELECT
+        setweight(to_tsvector('russian', coalesce(vac.name, '')), 'A') ||
+        setweight(to_tsvector('russian', coalesce((SELECT name_organization FROM employer WHERE id = vac.emp_id), '')), 'B') ||
+        setweight(to_tsvector('russian', coalesce(vac.location, '')), 'B') ||
+        setweight(to_tsvector('russian', coalesce(vac.about_work, '')), 'C');
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION vacancy_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := vacancy_search_vector(NEW);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER vacancy_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF name, location, about_work, emp_id ON vacancy
+    FOR EACH ROW EXECUTE FUNCTION vacancy_search_vector_update();
+
+CREATE OR REPLACE FUNCTION employer_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    UPDATE vacancy v SET search_vector = vacancy_search_vector(v) WHERE v.emp_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER employer_search_vector_trigger
+    AFTER UPDATE OF name_organization ON employer
+    FOR EACH ROW EXECUTE FUNCTION employer_search_vector_update();
+
+UPDATE vacancy v SET search_vector = vacancy_search_vector(v);
+
+CREATE INDEX IF NOT EXISTS vacancy_search_vector_idx ON vacancy USING GIN (search_vector);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS vacancy_emp_id_id_idx;
+DROP INDEX IF EXISTS response_candidates_id_id_idx;
@@ -0,0 +1,3 @@
+-- Индексы под выдачу по курсору: фильтр по владельцу + сортировка по id
+CREATE INDEX IF NOT EXISTS response_candidates_id_id_idx ON response (candidates_id, id);
+CREATE INDEX IF NOT EXISTS vacancy_emp_id_id_idx ON vacancy (emp_id, id) WHERE deleted_at IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE candidates DROP COLUMN IF EXISTS notify_response_status;
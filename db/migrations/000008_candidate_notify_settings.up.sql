@@ -0,0 +1 @@
+ALTER TABLE candidates ADD COLUMN IF NOT EXISTS notify_response_status BOOLEAN NOT NULL DEFAULT true;
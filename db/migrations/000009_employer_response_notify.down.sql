@@ -0,0 +1,2 @@
+ALTER TABLE employer DROP COLUMN IF EXISTS notify_digest_sent_at;
+ALTER TABLE employer DROP COLUMN IF EXISTS notify_new_response;
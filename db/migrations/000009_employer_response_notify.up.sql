@@ -0,0 +1,5 @@
+-- off - не уведомлять, immediate - письмо на каждый отклик, hourly/daily - сводка откликов раз в час/сутки
+ALTER TABLE employer ADD COLUMN IF NOT EXISTS notify_new_response TEXT NOT NULL DEFAULT 'immediate'
+    CHECK (notify_new_response IN ('off', 'immediate', 'hourly', 'daily'));
+-- В сводку попадают отклики, созданные после последней отправленной сводки
+ALTER TABLE employer ADD COLUMN IF NOT EXISTS notify_digest_sent_at TIMESTAMP NOT NULL DEFAULT now();
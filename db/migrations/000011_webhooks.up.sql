@@ -0,0 +1,30 @@
+-- Адреса, на которые работодатель хочет получать события. Тело запроса подписывается HMAC-SHA256 ключом secret
+CREATE TABLE IF NOT EXISTS webhooks (
+    id SERIAL PRIMARY KEY,
+    emp_id INTEGER NOT NULL REFERENCES employer (id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_emp_idx ON webhooks (emp_id);
+
+-- Журнал доставок. Работает как email_outbox: строка создаётся в транзакции запроса, а отправляет её диспетчер
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id SERIAL PRIMARY KEY,
+    webhook_id INTEGER NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    -- pending - ждёт отправки, failed - ждёт повторной попытки, sent - доставлено, dead - попытки закончились
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'failed', 'sent', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_code INTEGER NULL,
+    last_error TEXT NULL,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT now(),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    delivered_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at, id) WHERE status IN ('pending', 'failed');
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_id, id DESC);
@@ -0,0 +1,20 @@
+-- Сохранённые поиски соискателей. Фильтры повторяют параметры /vac/search, NULL - фильтр не задан.
+-- last_vacancy_id - самая новая вакансия, которая уже была учтена, в следующее письмо попадут только вакансии новее неё
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id SERIAL PRIMARY KEY,
+    candidate_id INTEGER NOT NULL REFERENCES candidates (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    exp_id INTEGER NULL,
+    min_price INTEGER NULL,
+    max_price INTEGER NULL,
+    text TEXT NULL,
+    -- instant - как можно быстрее, daily - раз в сутки, weekly - раз в неделю, off - письма не отправляются
+    frequency TEXT NOT NULL DEFAULT 'daily' CHECK (frequency IN ('instant', 'daily', 'weekly', 'off')),
+    last_vacancy_id INTEGER NOT NULL DEFAULT 0,
+    last_run_at TIMESTAMP NOT NULL DEFAULT now(),
+    unsubscribe_token TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS saved_searches_candidate_idx ON saved_searches (candidate_id);
+CREATE INDEX IF NOT EXISTS saved_searches_due_idx ON saved_searches (last_run_at) WHERE frequency <> 'off';
@@ -0,0 +1,20 @@
+-- История запусков фоновых задач планировщика
+CREATE TABLE IF NOT EXISTS job_runs (
+    id SERIAL PRIMARY KEY,
+    job TEXT NOT NULL,
+    -- schedule - запуск по расписанию, manual - запуск администратором
+    trigger TEXT NOT NULL CHECK (trigger IN ('schedule', 'manual')),
+    -- running - выполняется, success - завершилась, failed - завершилась с ошибкой, skipped - задачу уже выполняет другой экземпляр сервера
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'success', 'failed', 'skipped')),
+    error TEXT NULL,
+    -- время по расписанию, на которое пришёлся запуск. У ручных запусков NULL
+    scheduled_at TIMESTAMP NULL,
+    -- экземпляр сервера (host:pid), который выполнял задачу
+    instance TEXT NOT NULL,
+    started_at TIMESTAMP NOT NULL DEFAULT now(),
+    finished_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS job_runs_job_idx ON job_runs (job, id DESC);
+-- Каждое время расписания выполняется только один раз, даже если серверов несколько
+CREATE UNIQUE INDEX IF NOT EXISTS job_runs_slot_idx ON job_runs (job, scheduled_at) WHERE trigger = 'schedule';
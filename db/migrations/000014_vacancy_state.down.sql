@@ -0,0 +1,14 @@
+UPDATE webhooks SET events = array_replace(events, 'vacancy_state_changed', 'vacancy_visibility_changed');
+
+DROP INDEX IF EXISTS vacancy_emp_id_state_idx;
+DROP INDEX IF EXISTS vacancy_published_id_idx;
+
+ALTER TABLE vacancy ADD COLUMN IF NOT EXISTS is_visible BOOLEAN NOT NULL DEFAULT true;
+UPDATE vacancy SET is_visible = (state = 'published');
+
+ALTER TABLE vacancy DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE vacancy DROP COLUMN IF EXISTS closed_at;
+ALTER TABLE vacancy DROP COLUMN IF EXISTS published_at;
+ALTER TABLE vacancy DROP COLUMN IF EXISTS submitted_at;
+ALTER TABLE vacancy DROP COLUMN IF EXISTS state_changed_at;
+ALTER TABLE vacancy DROP COLUMN IF EXISTS state;
@@ -0,0 +1,23 @@
+-- Состояние вакансии вместо флага is_visible. Время входа в каждое состояние хранится в отдельной колонке
+ALTER TABLE vacancy ADD COLUMN IF NOT EXISTS state TEXT NOT NULL DEFAULT 'draft'
+    CHECK (state IN ('draft', 'moderation', 'published', 'closed', 'archived'));
+ALTER TABLE vacancy ADD COLUMN IF NOT EXISTS state_changed_at TIMESTAMP NOT NULL DEFAULT now();
+ALTER TABLE vacancy ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMP NULL;
+ALTER TABLE vacancy ADD COLUMN IF NOT EXISTS published_at TIMESTAMP NULL;
+ALTER TABLE vacancy ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP NULL;
+ALTER TABLE vacancy ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP NULL;
+
+-- Видимые вакансии считаются опубликованными, скрытые - снятыми с публикации
+UPDATE vacancy SET
+    state = CASE WHEN is_visible THEN 'published' ELSE 'closed' END,
+    state_changed_at = updated_at,
+    published_at = created_at,
+    closed_at = CASE WHEN is_visible THEN NULL ELSE updated_at END;
+
+ALTER TABLE vacancy DROP COLUMN IF EXISTS is_visible;
+
+CREATE INDEX IF NOT EXISTS vacancy_published_id_idx ON vacancy (id) WHERE state = 'published' AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS vacancy_emp_id_state_idx ON vacancy (emp_id, state, id) WHERE deleted_at IS NULL;
+
+-- Событие вебхука vacancy_visibility_changed заменено на vacancy_state_changed
+UPDATE webhooks SET events = array_replace(events, 'vacancy_visibility_changed', 'vacancy_state_changed');
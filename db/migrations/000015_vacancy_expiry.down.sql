@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS vacancy_expires_at_idx;
+
+ALTER TABLE vacancy DROP COLUMN IF EXISTS expiry_reminded_at;
+ALTER TABLE vacancy DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,10 @@
+-- Срок публикации вакансии. После expires_at вакансия снимается с публикации задачей vacancy_expire
+ALTER TABLE vacancy ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP NULL;
+-- Когда работодателю отправлено напоминание о скором окончании срока. Сбрасывается при продлении
+ALTER TABLE vacancy ADD COLUMN IF NOT EXISTS expiry_reminded_at TIMESTAMP NULL;
+
+-- Уже опубликованным вакансиям даётся 30 дней (срок по умолчанию), но не меньше недели от момента миграции
+UPDATE vacancy SET expires_at = GREATEST(COALESCE(published_at, created_at) + interval '30 days', now() + interval '7 days')
+WHERE state = 'published';
+
+CREATE INDEX IF NOT EXISTS vacancy_expires_at_idx ON vacancy (expires_at) WHERE state = 'published' AND deleted_at IS NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS vacancy_moderation_queue_idx;
+DROP TABLE IF EXISTS vacancy_moderation;
+
+ALTER TABLE vacancy DROP COLUMN IF EXISTS moderation_reason;
+DROP TABLE IF EXISTS trusted_statuses;
@@ -0,0 +1,22 @@
+-- Работодатели с этими статусами публикуют вакансии без модерации.
+-- Отдельная таблица, а не колонка в status, так как status читается через SELECT * в несколько структур
+CREATE TABLE IF NOT EXISTS trusted_statuses (
+    status_id INTEGER PRIMARY KEY REFERENCES status (id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- Причина последнего отказа модератора, показывается работодателю. Сбрасывается при одобрении
+ALTER TABLE vacancy ADD COLUMN IF NOT EXISTS moderation_reason TEXT NULL;
+
+-- Журнал решений модераторов
+CREATE TABLE IF NOT EXISTS vacancy_moderation (
+    id SERIAL PRIMARY KEY,
+    vacancy_id INTEGER NOT NULL REFERENCES vacancy (id) ON DELETE CASCADE,
+    decision TEXT NOT NULL CHECK (decision IN ('approved', 'rejected')),
+    reason TEXT NULL,
+    admin_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS vacancy_moderation_vacancy_idx ON vacancy_moderation (vacancy_id, id DESC);
+CREATE INDEX IF NOT EXISTS vacancy_moderation_queue_idx ON vacancy (id) WHERE state = 'moderation' AND deleted_at IS NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE response DROP COLUMN IF EXISTS vacancy_revision_id;
+DROP TABLE IF EXISTS vacancy_revisions;
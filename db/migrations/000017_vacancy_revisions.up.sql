@@ -0,0 +1,32 @@
+-- Редакции вакансии: снимок содержимого после каждого создания и изменения.
+-- По ним видно, что было написано в вакансии в момент отклика
+CREATE TABLE IF NOT EXISTS vacancy_revisions (
+    id SERIAL PRIMARY KEY,
+    vacancy_id INTEGER NOT NULL REFERENCES vacancy (id) ON DELETE CASCADE,
+    number INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    price INTEGER NOT NULL,
+    email TEXT NOT NULL,
+    phone_number TEXT NOT NULL,
+    location TEXT NOT NULL,
+    experience_id INTEGER NOT NULL REFERENCES experience (id),
+    about_work TEXT NOT NULL,
+    editor_role TEXT NOT NULL,
+    editor_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (vacancy_id, number)
+);
+
+-- Текущее содержимое существующих вакансий становится их первой редакцией
+INSERT INTO vacancy_revisions (vacancy_id, number, name, price, email, phone_number, location, experience_id, about_work, editor_role, editor_id, created_at)
+SELECT id, 1, name, price, email, phone_number, location, experience_id, about_work, 'employee', emp_id, updated_at
+FROM vacancy
+ON CONFLICT (vacancy_id, number) DO NOTHING;
+
+-- Редакция вакансии, на которую откликнулся соискатель
+ALTER TABLE response ADD COLUMN IF NOT EXISTS vacancy_revision_id INTEGER NULL REFERENCES vacancy_revisions (id) ON DELETE SET NULL;
+
+-- Старым откликам проставляется единственная известная редакция
+UPDATE response r SET vacancy_revision_id = vr.id
+FROM vacancy_revisions vr
+WHERE vr.vacancy_id = r.vacancy_id AND vr.number = 1 AND r.vacancy_revision_id IS NULL;
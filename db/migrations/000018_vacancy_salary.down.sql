@@ -0,0 +1,23 @@
+DROP INDEX IF EXISTS vacancy_salary_idx;
+
+-- Обратно в одно число: берётся нижняя граница вилки (или верхняя, если нижней нет) без пересчёта валюты
+ALTER TABLE vacancy_revisions ADD COLUMN IF NOT EXISTS price INTEGER NOT NULL DEFAULT 0;
+UPDATE vacancy_revisions SET price = COALESCE(salary_from, salary_to, 0);
+ALTER TABLE vacancy_revisions
+    DROP COLUMN IF EXISTS salary_from,
+    DROP COLUMN IF EXISTS salary_to,
+    DROP COLUMN IF EXISTS salary_currency,
+    DROP COLUMN IF EXISTS salary_gross,
+    DROP COLUMN IF EXISTS salary_period;
+
+ALTER TABLE vacancy ADD COLUMN IF NOT EXISTS price INTEGER NOT NULL DEFAULT 0;
+UPDATE vacancy SET price = COALESCE(salary_from, salary_to, 0);
+ALTER TABLE vacancy
+    DROP CONSTRAINT IF EXISTS vacancy_salary_range_check,
+    DROP COLUMN IF EXISTS salary_from,
+    DROP COLUMN IF EXISTS salary_to,
+    DROP COLUMN IF EXISTS salary_currency,
+    DROP COLUMN IF EXISTS salary_gross,
+    DROP COLUMN IF EXISTS salary_period;
+
+DROP TABLE IF EXISTS currency_rates;
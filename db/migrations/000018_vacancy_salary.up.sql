@@ -0,0 +1,34 @@
+-- Курсы валют для сравнения зарплат: сколько единиц базовой валюты (RUB) стоит одна единица валюты.
+-- Курсы меняет администратор, у базовой валюты курс всегда 1
+CREATE TABLE IF NOT EXISTS currency_rates (
+    code TEXT PRIMARY KEY CHECK (code ~ '^[A-Z]{3}$'),
+    rate NUMERIC(18, 6) NOT NULL CHECK (rate > 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+INSERT INTO currency_rates (code, rate) VALUES ('RUB', 1), ('USD', 90), ('EUR', 98), ('KZT', 0.18), ('BYN', 28)
+ON CONFLICT (code) DO NOTHING;
+
+-- Зарплата вакансии: вилка (любая из границ может быть не указана), валюта, до или после вычета налогов и за какой период
+ALTER TABLE vacancy
+    ADD COLUMN IF NOT EXISTS salary_from INTEGER NULL CHECK (salary_from > 0),
+    ADD COLUMN IF NOT EXISTS salary_to INTEGER NULL CHECK (salary_to > 0),
+    ADD COLUMN IF NOT EXISTS salary_currency TEXT NOT NULL DEFAULT 'RUB' REFERENCES currency_rates (code) ON UPDATE CASCADE,
+    ADD COLUMN IF NOT EXISTS salary_gross BOOLEAN NOT NULL DEFAULT true,
+    ADD COLUMN IF NOT EXISTS salary_period TEXT NOT NULL DEFAULT 'month' CHECK (salary_period IN ('month', 'hour'));
+ALTER TABLE vacancy ADD CONSTRAINT vacancy_salary_range_check CHECK (salary_from <= salary_to);
+
+-- Старая зарплата была одним числом в рублях за месяц: переносим её в обе границы вилки
+UPDATE vacancy SET salary_from = price, salary_to = price WHERE price > 0;
+ALTER TABLE vacancy DROP COLUMN IF EXISTS price;
+
+ALTER TABLE vacancy_revisions
+    ADD COLUMN IF NOT EXISTS salary_from INTEGER NULL,
+    ADD COLUMN IF NOT EXISTS salary_to INTEGER NULL,
+    ADD COLUMN IF NOT EXISTS salary_currency TEXT NOT NULL DEFAULT 'RUB',
+    ADD COLUMN IF NOT EXISTS salary_gross BOOLEAN NOT NULL DEFAULT true,
+    ADD COLUMN IF NOT EXISTS salary_period TEXT NOT NULL DEFAULT 'month';
+UPDATE vacancy_revisions SET salary_from = price, salary_to = price WHERE price > 0;
+ALTER TABLE vacancy_revisions DROP COLUMN IF EXISTS price;
+
+CREATE INDEX IF NOT EXISTS vacancy_salary_idx ON vacancy (salary_from, salary_to) WHERE state = 'published' AND deleted_at IS NULL;
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS vacancy_work_format_idx;
+DROP INDEX IF EXISTS vacancy_schedule_idx;
+DROP INDEX IF EXISTS vacancy_employment_type_idx;
+
+DROP TABLE IF EXISTS vacancy_skills;
+DROP TABLE IF EXISTS skills;
+
+ALTER TABLE vacancy
+    DROP COLUMN IF EXISTS employment_type_id,
+    DROP COLUMN IF EXISTS schedule_id,
+    DROP COLUMN IF EXISTS work_format_id;
+
+DROP TABLE IF EXISTS work_formats;
+DROP TABLE IF EXISTS schedules;
+DROP TABLE IF EXISTS employment_types;
@@ -0,0 +1,51 @@
+-- Справочники для структурированных атрибутов вакансии. Устроены так же, как experience
+CREATE TABLE IF NOT EXISTS employment_types (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+CREATE TABLE IF NOT EXISTS schedules (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+CREATE TABLE IF NOT EXISTS work_formats (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+INSERT INTO employment_types (name) VALUES
+    ('Полная занятость'), ('Частичная занятость'), ('Проектная работа'), ('Стажировка'), ('Волонтёрство')
+ON CONFLICT (name) DO NOTHING;
+INSERT INTO schedules (name) VALUES
+    ('Полный день'), ('Сменный график'), ('Гибкий график'), ('Вахтовый метод')
+ON CONFLICT (name) DO NOTHING;
+INSERT INTO work_formats (name) VALUES
+    ('Офис'), ('Гибрид'), ('Удалённо')
+ON CONFLICT (name) DO NOTHING;
+
+-- У существующих вакансий атрибуты не заполнены
+ALTER TABLE vacancy
+    ADD COLUMN IF NOT EXISTS employment_type_id INTEGER NULL REFERENCES employment_types (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS schedule_id INTEGER NULL REFERENCES schedules (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS work_format_id INTEGER NULL REFERENCES work_formats (id) ON DELETE SET NULL;
+
+-- Навыки. Название уникально без учёта регистра
+CREATE TABLE IF NOT EXISTS skills (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS skills_name_idx ON skills (lower(name));
+
+CREATE TABLE IF NOT EXISTS vacancy_skills (
+    vacancy_id INTEGER NOT NULL REFERENCES vacancy (id) ON DELETE CASCADE,
+    skill_id INTEGER NOT NULL REFERENCES skills (id) ON DELETE CASCADE,
+    PRIMARY KEY (vacancy_id, skill_id)
+);
+CREATE INDEX IF NOT EXISTS vacancy_skills_skill_idx ON vacancy_skills (skill_id);
+
+CREATE INDEX IF NOT EXISTS vacancy_employment_type_idx ON vacancy (employment_type_id) WHERE state = 'published' AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS vacancy_schedule_idx ON vacancy (schedule_id) WHERE state = 'published' AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS vacancy_work_format_idx ON vacancy (work_format_id) WHERE state = 'published' AND deleted_at IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS skills_name_prefix_idx;
+DROP TABLE IF EXISTS resume_skills;
+DROP TABLE IF EXISTS skill_synonyms;
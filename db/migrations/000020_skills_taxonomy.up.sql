@@ -0,0 +1,22 @@
+-- Синонимы навыков: при вводе синонима (например, Golang) используется основной навык (Go).
+-- Название синонима уникально без учёта регистра и не должно совпадать с названием навыка
+CREATE TABLE IF NOT EXISTS skill_synonyms (
+    id SERIAL PRIMARY KEY,
+    skill_id INTEGER NOT NULL REFERENCES skills (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS skill_synonyms_name_idx ON skill_synonyms (lower(name));
+CREATE INDEX IF NOT EXISTS skill_synonyms_skill_idx ON skill_synonyms (skill_id);
+
+-- Навыки резюме
+CREATE TABLE IF NOT EXISTS resume_skills (
+    resume_id INTEGER NOT NULL REFERENCES resume (id) ON DELETE CASCADE,
+    skill_id INTEGER NOT NULL REFERENCES skills (id) ON DELETE CASCADE,
+    PRIMARY KEY (resume_id, skill_id)
+);
+CREATE INDEX IF NOT EXISTS resume_skills_skill_idx ON resume_skills (skill_id);
+
+-- Автодополнение ищет по началу названия
+CREATE INDEX IF NOT EXISTS skills_name_prefix_idx ON skills (lower(name) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS skill_synonyms_name_prefix_idx ON skill_synonyms (lower(name) text_pattern_ops);
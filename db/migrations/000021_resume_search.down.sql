@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS contact_requests;
+
+DROP INDEX IF EXISTS resume_city_idx;
+DROP INDEX IF EXISTS resume_search_idx;
+
+ALTER TABLE resume
+    DROP COLUMN IF EXISTS salary_currency,
+    DROP COLUMN IF EXISTS salary_expectation,
+    DROP COLUMN IF EXISTS city,
+    DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,24 @@
+-- Видимость резюме для работодателей: public - в поиске у всех работодателей, responded - только у тех, на чьи вакансии
+-- соискатель откликался, hidden - нигде. Уже созданные резюме в общий поиск не попадают, пока соискатель сам не откроет их
+ALTER TABLE resume
+    ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'responded' CHECK (visibility IN ('public', 'responded', 'hidden')),
+    ADD COLUMN IF NOT EXISTS city TEXT NULL,
+    ADD COLUMN IF NOT EXISTS salary_expectation INTEGER NULL CHECK (salary_expectation > 0),
+    ADD COLUMN IF NOT EXISTS salary_currency TEXT NOT NULL DEFAULT 'RUB' REFERENCES currency_rates (code) ON UPDATE CASCADE;
+
+CREATE INDEX IF NOT EXISTS resume_search_idx ON resume USING GIN (to_tsvector('russian', description));
+CREATE INDEX IF NOT EXISTS resume_city_idx ON resume (lower(city));
+
+-- Запросы контактов: работодатель видит почту и телефон соискателя, если тот откликался на его вакансии
+-- или принял запрос. Один запрос на пару работодатель - соискатель
+CREATE TABLE IF NOT EXISTS contact_requests (
+    id SERIAL PRIMARY KEY,
+    employer_id INTEGER NOT NULL REFERENCES employer (id) ON DELETE CASCADE,
+    candidate_id INTEGER NOT NULL REFERENCES candidates (id) ON DELETE CASCADE,
+    resume_id INTEGER NULL REFERENCES resume (id) ON DELETE SET NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined')),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    answered_at TIMESTAMP NULL,
+    UNIQUE (employer_id, candidate_id)
+);
+CREATE INDEX IF NOT EXISTS contact_requests_candidate_idx ON contact_requests (candidate_id, created_at);
@@ -0,0 +1,12 @@
+ALTER TABLE resume_skills DROP COLUMN IF EXISTS sort_order;
+
+DROP TABLE IF EXISTS resume_languages;
+DROP TABLE IF EXISTS resume_education;
+DROP TABLE IF EXISTS resume_work;
+
+DROP INDEX IF EXISTS resume_search_idx;
+CREATE INDEX IF NOT EXISTS resume_search_idx ON resume USING GIN (to_tsvector('russian', description));
+
+ALTER TABLE resume
+    DROP COLUMN IF EXISTS relocation,
+    DROP COLUMN IF EXISTS title;
@@ -0,0 +1,55 @@
+-- Желаемая должность и готовность к переезду
+ALTER TABLE resume
+    ADD COLUMN IF NOT EXISTS title TEXT NULL,
+    ADD COLUMN IF NOT EXISTS relocation BOOLEAN NOT NULL DEFAULT false;
+
+-- Поиск резюме идёт по желаемой должности и описанию
+DROP INDEX IF EXISTS resume_search_idx;
+CREATE INDEX IF NOT EXISTS resume_search_idx ON resume USING GIN (to_tsvector('russian', coalesce(title, '') || ' ' || description));
+
+-- Разделы резюме. Порядок записей внутри резюме задаёт sort_order, его меняет соискатель
+CREATE TABLE IF NOT EXISTS resume_work (
+    id SERIAL PRIMARY KEY,
+    resume_id INTEGER NOT NULL REFERENCES resume (id) ON DELETE CASCADE,
+    sort_order INTEGER NOT NULL,
+    company TEXT NOT NULL,
+    job_position TEXT NOT NULL,
+    started_at DATE NOT NULL,
+    ended_at DATE NULL, -- NULL - работает до сих пор
+    achievements TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+CREATE INDEX IF NOT EXISTS resume_work_resume_idx ON resume_work (resume_id, sort_order);
+
+CREATE TABLE IF NOT EXISTS resume_education (
+    id SERIAL PRIMARY KEY,
+    resume_id INTEGER NOT NULL REFERENCES resume (id) ON DELETE CASCADE,
+    sort_order INTEGER NOT NULL,
+    institution TEXT NOT NULL,
+    faculty TEXT NOT NULL DEFAULT '',
+    specialization TEXT NOT NULL DEFAULT '',
+    degree TEXT NOT NULL DEFAULT '',
+    graduation_year INTEGER NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS resume_education_resume_idx ON resume_education (resume_id, sort_order);
+
+CREATE TABLE IF NOT EXISTS resume_languages (
+    id SERIAL PRIMARY KEY,
+    resume_id INTEGER NOT NULL REFERENCES resume (id) ON DELETE CASCADE,
+    sort_order INTEGER NOT NULL,
+    language TEXT NOT NULL,
+    level TEXT NOT NULL CHECK (level IN ('A1', 'A2', 'B1', 'B2', 'C1', 'C2', 'native')),
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS resume_languages_name_idx ON resume_languages (resume_id, lower(language));
+
+-- Навыки резюме тоже упорядочены. У уже указанных навыков порядок - по названию
+ALTER TABLE resume_skills ADD COLUMN IF NOT EXISTS sort_order INTEGER NOT NULL DEFAULT 0;
+UPDATE resume_skills rs SET sort_order = o.n
+FROM (
+    SELECT rs2.resume_id, rs2.skill_id, row_number() OVER (PARTITION BY rs2.resume_id ORDER BY sk.name) AS n
+    FROM resume_skills rs2 JOIN skills sk ON sk.id = rs2.skill_id
+) o
+WHERE rs.resume_id = o.resume_id AND rs.skill_id = o.skill_id;
@@ -0,0 +1 @@
+ALTER TABLE employer DROP COLUMN IF EXISTS notify_digest_covered_at;
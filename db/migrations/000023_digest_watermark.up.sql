@@ -0,0 +1,7 @@
+-- Граница сводки - время создания последнего отклика из прошлой сводки, а не время её отправки.
+-- created_at - это время начала транзакции, поэтому отклик, который закоммитили позже, может оказаться раньше границы
+-- и не попасть ни в одну сводку. В 000028 граница заменена отметкой на каждом отклике (response.digested_at)
+ALTER TABLE employer ADD COLUMN IF NOT EXISTS notify_digest_covered_at TIMESTAMP NULL;
+UPDATE employer SET notify_digest_covered_at = notify_digest_sent_at;
+ALTER TABLE employer ALTER COLUMN notify_digest_covered_at SET NOT NULL;
+ALTER TABLE employer ALTER COLUMN notify_digest_covered_at SET DEFAULT now();
@@ -0,0 +1,2 @@
+-- Удалённые тела ответов не восстанавливаются
+SELECT 1;
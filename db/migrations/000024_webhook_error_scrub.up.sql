@@ -0,0 +1,4 @@
+-- Раньше в last_error сохранялось начало тела ответа получателя. Журнал виден работодателю,
+-- поэтому оставляем только код ответа
+UPDATE webhook_deliveries SET last_error = 'получатель ответил кодом ' || response_code
+WHERE response_code IS NOT NULL AND last_error IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS vacancy_published_at_idx;
+
+ALTER TABLE saved_searches ADD COLUMN IF NOT EXISTS last_vacancy_id INTEGER NOT NULL DEFAULT 0;
+UPDATE saved_searches ss SET last_vacancy_id = COALESCE((SELECT max(id) FROM vacancy WHERE published_at <= ss.last_published_at), 0);
+ALTER TABLE saved_searches DROP COLUMN IF EXISTS last_published_at;
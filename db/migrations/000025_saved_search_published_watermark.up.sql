@@ -0,0 +1,10 @@
+-- Письма по сохранённым поискам учитывают вакансии по времени публикации, а не по ID:
+-- вакансия, созданная раньше, но опубликованная после прошлого письма (черновик, модерация), тоже попадёт в письмо.
+-- last_published_at - время публикации самой новой учтённой вакансии
+ALTER TABLE saved_searches ADD COLUMN IF NOT EXISTS last_published_at TIMESTAMP NULL;
+UPDATE saved_searches SET last_published_at = last_run_at;
+ALTER TABLE saved_searches ALTER COLUMN last_published_at SET NOT NULL;
+ALTER TABLE saved_searches ALTER COLUMN last_published_at SET DEFAULT now();
+ALTER TABLE saved_searches DROP COLUMN IF EXISTS last_vacancy_id;
+
+CREATE INDEX IF NOT EXISTS vacancy_published_at_idx ON vacancy (published_at) WHERE state = 'published' AND deleted_at IS NULL;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS vacancy_salary_idx ON vacancy (salary_from, salary_to) WHERE state = 'published' AND deleted_at IS NULL;
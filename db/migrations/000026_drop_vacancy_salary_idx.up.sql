@@ -0,0 +1,3 @@
+-- Фильтр по зарплате сравнивает вилку, приведённую по курсу валют, периоду и налогу, а не исходные salary_from и salary_to,
+-- поэтому индекс по ним не используется. Индекс по приведённому значению построить нельзя: курсы лежат в currency_rates
+DROP INDEX IF EXISTS vacancy_salary_idx;
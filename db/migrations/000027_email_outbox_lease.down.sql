@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS email_outbox_lease_idx;
+
+UPDATE email_outbox SET status = 'pending' WHERE status = 'processing';
+ALTER TABLE email_outbox DROP CONSTRAINT IF EXISTS email_outbox_status_check;
+ALTER TABLE email_outbox ADD CONSTRAINT email_outbox_status_check CHECK (status IN ('pending', 'failed', 'sent', 'dead'));
+ALTER TABLE email_outbox DROP COLUMN IF EXISTS locked_until;
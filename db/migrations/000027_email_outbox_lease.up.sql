@@ -0,0 +1,7 @@
+-- Отправщик забирает письма короткой транзакцией: переводит их в processing и ставит срок аренды locked_until,
+-- а отправляет уже без открытой транзакции. Если отправщик упал, то письмо снова заберут, когда аренда истечёт
+ALTER TABLE email_outbox ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP NULL;
+ALTER TABLE email_outbox DROP CONSTRAINT IF EXISTS email_outbox_status_check;
+ALTER TABLE email_outbox ADD CONSTRAINT email_outbox_status_check CHECK (status IN ('pending', 'processing', 'failed', 'sent', 'dead'));
+
+CREATE INDEX IF NOT EXISTS email_outbox_lease_idx ON email_outbox (locked_until) WHERE status = 'processing';
@@ -0,0 +1,9 @@
+ALTER TABLE employer ADD COLUMN IF NOT EXISTS notify_digest_covered_at TIMESTAMP NULL;
+UPDATE employer em SET notify_digest_covered_at = COALESCE(
+    (SELECT max(r.created_at) FROM response r JOIN vacancy v ON r.vacancy_id = v.id WHERE v.emp_id = em.id AND r.digested_at IS NOT NULL),
+    em.notify_digest_sent_at);
+ALTER TABLE employer ALTER COLUMN notify_digest_covered_at SET NOT NULL;
+ALTER TABLE employer ALTER COLUMN notify_digest_covered_at SET DEFAULT now();
+
+DROP INDEX IF EXISTS response_undigested_idx;
+ALTER TABLE response DROP COLUMN IF EXISTS digested_at;
@@ -0,0 +1,10 @@
+-- Отклик, который уже попал в сводку работодателю (или пришёл, пока работодатель получал письмо на каждый отклик),
+-- отмечается digested_at. В сводку попадают все отклики без отметки, в каком бы порядке их ни закоммитили
+ALTER TABLE response ADD COLUMN IF NOT EXISTS digested_at TIMESTAMP NULL;
+UPDATE response r SET digested_at = em.notify_digest_covered_at
+FROM vacancy v JOIN employer em ON v.emp_id = em.id
+WHERE r.vacancy_id = v.id AND r.created_at <= em.notify_digest_covered_at;
+
+CREATE INDEX IF NOT EXISTS response_undigested_idx ON response (vacancy_id) WHERE digested_at IS NULL;
+
+ALTER TABLE employer DROP COLUMN IF EXISTS notify_digest_covered_at;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS webhook_deliveries_lease_idx;
+
+UPDATE webhook_deliveries SET status = 'pending' WHERE status = 'processing';
+ALTER TABLE webhook_deliveries DROP CONSTRAINT IF EXISTS webhook_deliveries_status_check;
+ALTER TABLE webhook_deliveries ADD CONSTRAINT webhook_deliveries_status_check CHECK (status IN ('pending', 'failed', 'sent', 'dead'));
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS locked_until;
@@ -0,0 +1,7 @@
+-- Диспетчер забирает доставки так же, как отправщик писем (000027): короткой транзакцией переводит их в processing
+-- с арендой locked_until, а запросы получателям делает уже без открытой транзакции
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP NULL;
+ALTER TABLE webhook_deliveries DROP CONSTRAINT IF EXISTS webhook_deliveries_status_check;
+ALTER TABLE webhook_deliveries ADD CONSTRAINT webhook_deliveries_status_check CHECK (status IN ('pending', 'processing', 'failed', 'sent', 'dead'));
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_lease_idx ON webhook_deliveries (locked_until) WHERE status = 'processing';
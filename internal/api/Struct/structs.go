@@ -1,6 +1,7 @@
 package structs
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -9,6 +10,7 @@ import (
 type SuccessAllEmployers struct {
 	Status        string            `json:"Status"`
 	EmployersInfo []SuccessEmployer `json:"EmployersInfo"`
+	NextCursor    string            `json:"NextCursor"`
 }
 
 type SuccessEmployer struct {
@@ -17,7 +19,7 @@ type SuccessEmployer struct {
 	PhoneNumber      string `db:"phone_number"  json:"PhoneNumber"`
 	Email            string `db:"email" json:"Email"`
 	INN              string `db:"inn" json:"INN"`
-	Password         string `db:"password" json:"Password"`
+	Password         string `db:"password" json:"-"`
 	Status           struct {
 		ID        int       `db:"id" json:"ID"`
 		Name      string    `db:"name" json:"Name"`
@@ -25,6 +27,7 @@ type SuccessEmployer struct {
 	} `db:"status" json:"Status"`
 	CreatedAt time.Time `db:"created_at" json:"CreatedAt"`
 	UpdatedAt time.Time `db:"updated_at" json:"UpdatedAt"`
+	Version   int       `db:"version" json:"-"`
 }
 
 type ResponseOnVacancy struct {
@@ -45,7 +48,7 @@ type Vacancy_Body struct {
 	Location    string `json:"location"`
 	Experience  int    `json:"exp"`
 	About       string `json:"about"`
-	IsVisible   bool   `json:"is_visible"`
+	State       string `json:"state"`
 }
 
 type RequestCandidate struct {
@@ -61,15 +64,78 @@ type RequestVac struct {
 	Last_id int `json:"LastID"`
 }
 
+// PageCursor - позиция последней отданной записи, от которой продолжается выдача следующей страницы
+type PageCursor struct {
+	ID   int     `json:"id"`
+	Rank float64 `json:"rank,omitempty"`
+}
+
+// ResumeDetails - пожелания соискателя в резюме: желаемая должность, город, готовность к переезду и ожидаемая зарплата.
+// Зарплата указывается за месяц, по умолчанию в рублях
+type ResumeDetails struct {
+	Title             *string `db:"title" json:"Title"`
+	City              *string `db:"city" json:"City"`
+	Relocation        bool    `db:"relocation" json:"Relocation"`
+	SalaryExpectation *int    `db:"salary_expectation" json:"SalaryExpectation"`
+	SalaryCurrency    string  `db:"salary_currency" json:"SalaryCurrency"`
+}
+
+// ResumeSections - упорядоченные разделы резюме (по SortOrder).
+// Work - {"ID", "SortOrder", "Company", "Position", "StartedAt", "EndedAt", "Achievements"}, даты в формате YYYY-MM-DD;
+// Education - {"ID", "SortOrder", "Institution", "Faculty", "Specialization", "Degree", "GraduationYear"};
+// Languages - {"ID", "SortOrder", "Language", "Level"}
+type ResumeSections struct {
+	Work      json.RawMessage `db:"work" json:"Work" swaggertype:"array,object"`
+	Education json.RawMessage `db:"education" json:"Education" swaggertype:"array,object"`
+	Languages json.RawMessage `db:"languages" json:"Languages" swaggertype:"array,object"`
+}
+
+// RequestResumeWork - место работы. Даты в формате YYYY-MM-DD, пустой EndedAt - соискатель работает там до сих пор
+type RequestResumeWork struct {
+	ResumeID     int     `json:"ResumeID"`
+	Company      string  `json:"Company"`
+	Position     string  `json:"Position"`
+	StartedAt    string  `json:"StartedAt"`
+	EndedAt      *string `json:"EndedAt"`
+	Achievements string  `json:"Achievements"`
+}
+
+type RequestResumeEducation struct {
+	ResumeID       int    `json:"ResumeID"`
+	Institution    string `json:"Institution"`
+	Faculty        string `json:"Faculty"`
+	Specialization string `json:"Specialization"`
+	Degree         string `json:"Degree"`
+	GraduationYear *int   `json:"GraduationYear"`
+}
+
+// RequestResumeLanguage - язык и уровень владения: A1, A2, B1, B2, C1, C2 или native
+type RequestResumeLanguage struct {
+	ResumeID int    `json:"ResumeID"`
+	Language string `json:"Language"`
+	Level    string `json:"Level"`
+}
+
+// RequestResumeOrder - новый порядок записей раздела резюме: все ID раздела в нужном порядке
+type RequestResumeOrder struct {
+	ResumeID int   `json:"ResumeID"`
+	IDs      []int `json:"IDs"`
+}
+
 type RequestResume struct {
-	Experience  int    `json:"ExperienceID"`
-	Description string `json:"Description"`
+	Experience  int       `json:"ExperienceID"`
+	Description string    `json:"Description"`
+	Skills      *[]string `json:"Skills"`
+	ResumeDetails
 }
 
+// RequestResumeUpdate - новые данные резюме. Если Skills не передан, то навыки резюме не меняются
 type RequestResumeUpdate struct {
-	Experience  int    `json:"ExperienceID"`
-	Description string `json:"description"`
-	Resume_id   int    `json:"ResumeID"`
+	Experience  int       `json:"ExperienceID"`
+	Description string    `json:"description"`
+	Resume_id   int       `json:"ResumeID"`
+	Skills      *[]string `json:"Skills"`
+	ResumeDetails
 }
 
 type AllUserResponseOK struct {
@@ -128,7 +194,7 @@ type Vacancies struct {
 	Location     string    `db:"location" json:"Location"`
 	ExperienceId int       `db:"experience_id" json:"ExperienceID"`
 	AboutWork    string    `db:"about_work" json:"AboutWork"`
-	IsVisible    bool      `db:"is_visible" json:"IsVisible"`
+	State        string    `db:"state" json:"State"`
 	CreatedAt    time.Time `db:"created_at" json:"CreatedAt"`
 	UpdatedAt    time.Time `db:"updated_at" json:"UpdatedAt"`
 }
@@ -162,13 +228,13 @@ type VacanciesToResponse struct {
 	ID            int       `db:"id" json:"ID"`
 	Employer_name string    `db:"employee_name" json:"EmployerName"`
 	Name          string    `db:"name" json:"Name"`
-	Price         int       `db:"price" json:"Price"`
+	Salary        Salary    `db:"salary" json:"Salary"`
 	Email         string    `db:"email" json:"Email"`
 	PhoneNumber   string    `db:"phone_number" json:"PhoneNumber"`
 	Location      string    `db:"location" json:"Location"`
 	Experience    GetStatus `db:"experience" json:"ExperienceInfo"`
 	AboutWork     string    `db:"about_work" json:"AboutWork"`
-	IsVisible     bool      `db:"is_visible" json:"IsVisible"`
+	State         string    `db:"state" json:"State"`
 	CreatedAt     time.Time `db:"created_at" json:"CreatedAt"`
 	UpdatedAt     time.Time `db:"updated_at" json:"UpdatedAt"`
 }
@@ -176,10 +242,11 @@ type VacanciesToResponse struct {
 type SuccessResponse struct {
 	Vacancy   VacancyData `db:"vacancy" json:"VacancyInfo"`
 	Responses []struct {
-		ID        int           `db:"id" json:"ID"`
-		Candidate InfoCandidate `db:"candidate" json:"CandidateInfo"`
-		CreatedAt time.Time     `db:"created_at" json:"CreatedAt"`
-		Status    GetStatus     `db:"status" json:"Status"`
+		ID                int           `db:"id" json:"ID"`
+		Candidate         InfoCandidate `db:"candidate" json:"CandidateInfo"`
+		VacancyRevisionID *int          `db:"vacancy_revision_id" json:"VacancyRevisionID"` // редакция вакансии на момент отклика
+		CreatedAt         time.Time     `db:"created_at" json:"CreatedAt"`
+		Status            GetStatus     `db:"status" json:"Status"`
 	} `db:"responses"`
 }
 
@@ -189,14 +256,16 @@ type NumberOfVacancies struct {
 }
 
 type ResponseByVac struct {
-	ID      int                 `db:"id" json:"ID"`
-	Vacancy VacanciesToResponse `db:"vacancy" json:"VacancyInfo"`
-	Status  GetStatus           `db:"status" json:"StatusInfo"`
+	ID                int                 `db:"id" json:"ID"`
+	Vacancy           VacanciesToResponse `db:"vacancy" json:"VacancyInfo"`
+	VacancyRevisionID *int                `db:"vacancy_revision_id" json:"VacancyRevisionID"` // редакция вакансии на момент отклика
+	Status            GetStatus           `db:"status" json:"StatusInfo"`
 }
 
 type ResponsesByVac struct {
-	Status    string          `json:"Status"`
-	Responses []ResponseByVac `json:"Responses"`
+	Status     string          `json:"Status"`
+	Responses  []ResponseByVac `json:"Responses"`
+	NextCursor string          `json:"NextCursor"`
 }
 
 // неиспользуется
@@ -213,35 +282,140 @@ type SuccessVacancy struct {
 type VacanciesByLimitResponse struct {
 	Status        string              `json:"Status"`
 	VacanciesInfo []VacancyData_Limit `json:"VacancyInfo"`
+	NextCursor    string              `json:"NextCursor"`
+}
+
+// Salary - зарплата вакансии. From и To - границы вилки, любая из них может быть не указана
+type Salary struct {
+	From     *int   `db:"from" json:"From"`
+	To       *int   `db:"to" json:"To"`
+	Currency string `db:"currency" json:"Currency"` // код валюты из списка /currency, по умолчанию RUB
+	Gross    *bool  `db:"gross" json:"Gross"`       // true - до вычета налогов, false - на руки, по умолчанию true
+	Period   string `db:"period" json:"Period"`     // month или hour, по умолчанию month
+}
+
+// VacancyAttributes - атрибуты вакансии из справочников: {"ID", "Name"} или null, если атрибут не указан.
+// Навыки - массив {"ID", "Name"}
+type VacancyAttributes struct {
+	EmploymentType json.RawMessage `db:"employment_type" json:"EmploymentType" swaggertype:"object"`
+	Schedule       json.RawMessage `db:"schedule" json:"Schedule" swaggertype:"object"`
+	WorkFormat     json.RawMessage `db:"work_format" json:"WorkFormat" swaggertype:"object"`
+	Skills         json.RawMessage `db:"skills" json:"Skills" swaggertype:"array,object"`
+}
+
+// VacancyAttributesInput - атрибуты вакансии в запросе. ID берутся из /employment, /schedule и /format, навыки передаются
+// названиями из /skills: синоним заменяется основным навыком, неизвестные названия отклоняются.
+// Если Skills не передан, то при изменении вакансии её навыки не меняются
+type VacancyAttributesInput struct {
+	EmploymentTypeID *int      `json:"EmploymentTypeID"`
+	ScheduleID       *int      `json:"ScheduleID"`
+	WorkFormatID     *int      `json:"WorkFormatID"`
+	Skills           *[]string `json:"Skills"`
+}
+
+// VacancyAttributeFilter - фильтры поиска по атрибутам вакансии, пустой список - фильтр не задан
+type VacancyAttributeFilter struct {
+	EmploymentTypeIDs []int
+	ScheduleIDs       []int
+	WorkFormatIDs     []int
+	SkillIDs          []int
 }
 
 type VacancyData_Limit struct {
-	ID          int             `db:"id" json:"ID"`
-	Employer    SuccessEmployer `db:"employer" json:"EmployerInfo"`
-	Name        string          `db:"name" json:"Name"`
-	Price       int             `db:"price" json:"Price"`
-	Email       string          `db:"email" json:"Email"`
-	PhoneNumber string          `db:"phone_number" json:"PhoneNumber"`
-	Location    string          `db:"location" json:"Location"`
-	Experience  GetStatus       `db:"experience" json:"ExperienceInfo"`
-	AboutWork   string          `db:"about_work" json:"AboutWork"`
-	IsVisible   bool            `db:"is_visible" json:"IsVisible"`
-	CreatedAt   time.Time       `db:"created_at" json:"CreatedAt"`
-	UpdatedAt   time.Time       `db:"updated_at" json:"UpdatedAt"`
+	ID       int             `db:"id" json:"ID"`
+	Employer SuccessEmployer `db:"employer" json:"EmployerInfo"`
+	Name     string          `db:"name" json:"Name"`
+	Salary   Salary          `db:"salary" json:"Salary"`
+	VacancyAttributes
+	Email       string     `db:"email" json:"Email"`
+	PhoneNumber string     `db:"phone_number" json:"PhoneNumber"`
+	Location    string     `db:"location" json:"Location"`
+	Experience  GetStatus  `db:"experience" json:"ExperienceInfo"`
+	AboutWork   string     `db:"about_work" json:"AboutWork"`
+	State       string     `db:"state" json:"State"`
+	PublishedAt *time.Time `db:"published_at" json:"PublishedAt"`
+	ExpiresAt   *time.Time `db:"expires_at" json:"ExpiresAt"`
+	CreatedAt   time.Time  `db:"created_at" json:"CreatedAt"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"UpdatedAt"`
+	Version     int        `db:"version" json:"-"`
+}
+
+// VacancySearchResult - вакансия из полнотекстового поиска. Highlight - экранированный HTML,
+// в котором найденные слова обёрнуты в <b>
+type VacancySearchResult struct {
+	VacancyData_Limit
+	Rank      float64 `db:"rank" json:"Rank"`
+	Highlight struct {
+		Name      string `db:"name" json:"Name"`
+		AboutWork string `db:"about_work" json:"AboutWork"`
+	} `db:"highlight" json:"Highlight"`
+}
+
+// RecommendationProfile - что известно о соискателе для подбора вакансий
+type RecommendationProfile struct {
+	ExperienceIDs     []int    // опыт из резюме
+	SkillIDs          []int    // навыки из резюме
+	SimilarSkillIDs   []int    // навыки вакансий, на которые соискатель откликался
+	WorkFormatIDs     []int    // форматы работы вакансий, на которые соискатель откликался
+	EmploymentTypeIDs []int    // типы занятости вакансий, на которые соискатель откликался
+	Locations         []string // города из резюме и вакансий, на которые соискатель откликался, в нижнем регистре
+	SalaryMin         *int     // ожидаемая зарплата в рублях за месяц из резюме или сохранённых поисков
+}
+
+// RecommendationMatch - признаки, по которым вакансия подошла соискателю
+type RecommendationMatch struct {
+	Experience bool            `db:"experience"`
+	Skills     json.RawMessage `db:"skills"` // названия совпавших навыков из резюме
+	SkillNames []string        `db:"-"`
+	Salary     bool            `db:"salary"`
+	Location   bool            `db:"location"`
+	Similar    bool            `db:"similar"`
+}
+
+type VacancyRecommendation struct {
+	VacancyData_Limit
+	Score   int                 `db:"score" json:"Score"`
+	Reasons []string            `db:"-" json:"Reasons"`
+	Match   RecommendationMatch `db:"match" json:"-"`
+}
+
+type ResponseRecommendations struct {
+	Status        string                  `json:"Status"`
+	VacanciesInfo []VacancyRecommendation `json:"VacancyInfo"`
+	NextCursor    string                  `json:"NextCursor"`
+}
+
+type VacanciesSearchResponse struct {
+	Status        string                `json:"Status"`
+	VacanciesInfo []VacancySearchResult `json:"VacancyInfo"`
+	NextCursor    string                `json:"NextCursor"`
 }
 
 type VacancyData struct {
-	ID          int       `db:"id" json:"ID"`
-	Name        string    `db:"name" json:"Name"`
-	Price       int       `db:"price" json:"Price"`
+	ID     int    `db:"id" json:"ID"`
+	Name   string `db:"name" json:"Name"`
+	Salary Salary `db:"salary" json:"Salary"`
+	VacancyAttributes
 	Email       string    `db:"email" json:"Email"`
 	PhoneNumber string    `db:"phone_number" json:"PhoneNumber"`
 	Location    string    `db:"location" json:"Location"`
 	Experience  GetStatus `db:"experience" json:"Experience"`
 	AboutWork   string    `db:"about_work" json:"AboutWork"`
-	IsVisible   bool      `db:"is_visible" json:"IsVisible"`
+	State       string    `db:"state" json:"State"`
 	CreatedAt   time.Time `db:"created_at" json:"CreatedAt"`
 	UpdatedAt   time.Time `db:"updated_at" json:"UpdatedAt"`
+	StateTimes
+	ExpiresAt        *time.Time `db:"expires_at" json:"ExpiresAt"`               // до какого момента вакансия будет опубликована
+	ModerationReason *string    `db:"moderation_reason" json:"ModerationReason"` // почему модератор отклонил вакансию
+}
+
+// StateTimes - когда вакансия последний раз переходила в каждое из состояний. Время создания черновика - CreatedAt
+type StateTimes struct {
+	StateChangedAt *time.Time `db:"state_changed_at" json:"StateChangedAt"`
+	SubmittedAt    *time.Time `db:"submitted_at" json:"SubmittedAt"`
+	PublishedAt    *time.Time `db:"published_at" json:"PublishedAt"`
+	ClosedAt       *time.Time `db:"closed_at" json:"ClosedAt"`
+	ArchivedAt     *time.Time `db:"archived_at" json:"ArchivedAt"`
 }
 
 type ResponseAllResponsesOnVacancy struct {
@@ -268,14 +442,16 @@ type ResponseCreateNewResponse struct {
 }
 
 type ResponseAllVacancyByEmployee struct {
-	Status      Ok            `json:"Status`
+	Status      Ok            `json:"Status"`
 	Vacancies   []VacancyData `json:"VacanciesInfo"`
 	Employer_id int           `json:"EmployerID"`
+	NextCursor  string        `json:"NextCursor"`
 }
 
 type InfoAboutAllCandidates struct {
 	CandidatesInfo []InfoCandidate `json:"CandidatesInfo"`
 	Status         string          `json:"Status"`
+	NextCursor     string          `json:"NextCursor"`
 }
 
 type ResponseAuthorization struct {
@@ -312,26 +488,27 @@ type ResponseInfoByVacancyByTimes struct {
 }
 
 type VacancyPut struct {
-	ID           int    `json:"ID"`
-	VacancyName  string `json:"VacancyName"`
-	Price        int    `json:"Price"`
+	ID          int    `json:"ID"`
+	VacancyName string `json:"VacancyName"`
+	Salary      Salary `json:"Salary"`
+	VacancyAttributesInput
 	Email        string `json:"Email"`
 	PhoneNumber  string `json:"PhoneNumber"`
 	Location     string `json:"Location"`
 	ExperienceId int    `json:"ExperienceId"`
 	About        string `json:"About"`
-	IsVisible    bool   `json:"IsVisible"`
 }
 
 type ResponseVac struct {
-	VacancyName  string `json:"VacancyName"`
-	Price        int    `json:"Price"`
+	VacancyName string `json:"VacancyName"`
+	Salary      Salary `json:"Salary"`
+	VacancyAttributesInput
 	Email        string `json:"Email"`
 	PhoneNumber  string `json:"PhoneNumber"`
 	Location     string `json:"Location"`
 	ExperienceId int    `json:"ExperienceId"`
 	About        string `json:"About"`
-	IsVisible    bool   `json:"IsVisible"`
+	Draft        bool   `json:"Draft"` // true - сохранить черновиком, false - сразу опубликовать
 }
 
 type ResponseSearchVac struct {
@@ -343,6 +520,18 @@ type ResponseSearchVac struct {
 	Experience  string `json:"Experience"`
 }
 
+type DeletedEntity struct {
+	ID        int       `db:"id" json:"ID"`
+	Name      string    `db:"name" json:"Name"`
+	Email     string    `db:"email" json:"Email"`
+	DeletedAt time.Time `db:"deleted_at" json:"DeletedAt"`
+}
+
+type ResponseDeletedEntities struct {
+	Status  string          `json:"Status"`
+	Deleted []DeletedEntity `json:"DeletedInfo"`
+}
+
 type StatusInfo struct {
 	Status string `json:"Status"`
 	Info   string `json:"Info"`
@@ -360,11 +549,16 @@ type GetStatus struct {
 }
 
 type ResumeResult_slice struct {
-	Id          int       `db:"id" json:"ID"`
-	Experience  GetStatus `db:"experience" json:"ExperienceInfo"`
-	Description string    `db:"description" json:"Description"`
-	CreatedAt   time.Time `db:"created_at" json:"CreatedAt"`
-	UpdatedAt   time.Time `db:"updated_at" json:"UpdatedAt"`
+	Id          int             `db:"id" json:"ID"`
+	Experience  GetStatus       `db:"experience" json:"ExperienceInfo"`
+	Description string          `db:"description" json:"Description"`
+	Skills      json.RawMessage `db:"skills" json:"Skills" swaggertype:"array,object"`
+	ResumeDetails
+	Visibility string `db:"visibility" json:"Visibility"` // public, responded или hidden
+	ResumeSections
+	CreatedAt time.Time `db:"created_at" json:"CreatedAt"`
+	UpdatedAt time.Time `db:"updated_at" json:"UpdatedAt"`
+	Version   int       `db:"version" json:"Version"`
 }
 type ResumeResult struct {
 	Resumes   []ResumeResult_slice `db:"resume" json:"ResumesInfo"`
@@ -375,7 +569,7 @@ type InfoCandidate struct {
 	Name        string `db:"name" json:"Name"`
 	PhoneNumber string `db:"phone_number" json:"PhoneNumber"`
 	Email       string `db:"email" json:"Email"`
-	Password    string `db:"password" json:"Password"`
+	Password    string `db:"password" json:"-"`
 	Status      struct {
 		ID        int       `db:"id" json:"ID"`
 		Name      string    `db:"name" json:"Name"`
@@ -383,6 +577,7 @@ type InfoCandidate struct {
 	} `db:"status" json:"StatusInfo"`
 	CreatedAt time.Time `db:"created_at" json:"CreatedAt"`
 	UpdatedAt time.Time `db:"updated_at" json:"UpdatedAt"`
+	Version   int       `db:"version" json:"-"`
 }
 
 type GetAllFromCandidates struct {
@@ -415,6 +610,395 @@ type ClaimsToVerify struct {
 	ID    int    `json:"uid"`
 	Email string `json:"email"`
 	Role  string `json:"role"`
-	Used  bool   `json:isUsed`
+	Used  bool   `json:"isUsed"`
 	jwt.RegisteredClaims
 }
+
+type OutboxEmail struct {
+	ID        int    `db:"id"`
+	Recipient string `db:"recipient"`
+	Subject   string `db:"subject"`
+	Body      string `db:"body"`
+	HTMLBody  string `db:"html_body"`
+	Attempts  int    `db:"attempts"`
+}
+
+type ResponseNotice struct {
+	ID        int       `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+	Candidate struct {
+		ID                   int    `db:"id"`
+		Name                 string `db:"name"`
+		Email                string `db:"email"`
+		NotifyResponseStatus bool   `db:"notify_response_status"`
+	} `db:"candidate"`
+	Vacancy struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	} `db:"vacancy"`
+	Employer struct {
+		ID                int    `db:"id"`
+		NameOrganization  string `db:"name_organization"`
+		Email             string `db:"email"`
+		NotifyNewResponse string `db:"notify_new_response"`
+	} `db:"employer"`
+}
+
+type CandidateNotifySettings struct {
+	ResponseStatusEmail bool `db:"notify_response_status" json:"ResponseStatusEmail"`
+}
+
+type ResponseCandidateNotifySettings struct {
+	Status   string                  `json:"Status"`
+	Settings CandidateNotifySettings `json:"Settings"`
+}
+
+// EmployerNotifySettings - как работодатель хочет узнавать о новых откликах.
+// NewResponse: off - не уведомлять, immediate - письмо на каждый отклик, hourly/daily - сводка раз в час/сутки
+type EmployerNotifySettings struct {
+	NewResponse string `db:"notify_new_response" json:"NewResponse"`
+}
+
+type ResponseEmployerNotifySettings struct {
+	Status   string                 `json:"Status"`
+	Settings EmployerNotifySettings `json:"Settings"`
+}
+
+// DigestEmployer - работодатель, которому пора отправить сводку откликов
+type DigestEmployer struct {
+	ID                int    `db:"id"`
+	NameOrganization  string `db:"name_organization"`
+	Email             string `db:"email"`
+	NotifyNewResponse string `db:"notify_new_response"`
+}
+
+// DigestResponse - отклик, который попадёт в сводку работодателю
+type DigestResponse struct {
+	ID            int    `db:"id"`
+	VacancyID     int    `db:"vacancy_id"`
+	VacancyName   string `db:"vacancy_name"`
+	CandidateName string `db:"candidate_name"`
+}
+
+// Notification - уведомление внутри приложения. Payload зависит от Type:
+// response_created - новый отклик (для работодателя), response_status_changed - работодатель поменял статус отклика (для соискателя),
+// employer_status_changed - администратор поменял статус работодателя
+type Notification struct {
+	ID        int             `db:"id" json:"ID"`
+	Type      string          `db:"type" json:"Type"`
+	Payload   json.RawMessage `db:"payload" json:"Payload" swaggertype:"object"`
+	IsRead    bool            `db:"is_read" json:"IsRead"`
+	CreatedAt time.Time       `db:"created_at" json:"CreatedAt"`
+}
+
+type ResponseNotifications struct {
+	Status        string         `json:"Status"`
+	Notifications []Notification `json:"Notifications"`
+	NextCursor    string         `json:"NextCursor"`
+}
+
+type ResponseUnreadNotifications struct {
+	Status string `json:"Status"`
+	Unread int    `json:"Unread"`
+}
+
+type RequestWebhook struct {
+	URL string `json:"URL"`
+	// Events - на какие события подписаться: response_created, response_withdrawn, vacancy_state_changed
+	Events []string `json:"Events"`
+}
+
+type Webhook struct {
+	ID        int             `db:"id" json:"ID"`
+	URL       string          `db:"url" json:"URL"`
+	Events    json.RawMessage `db:"events" json:"Events" swaggertype:"array,string"`
+	CreatedAt time.Time       `db:"created_at" json:"CreatedAt"`
+}
+
+type ResponseNewWebhook struct {
+	Status  string  `json:"Status"`
+	Webhook Webhook `json:"Webhook"`
+	// Secret - ключ для проверки подписи. Показывается только один раз при создании
+	Secret string `json:"Secret"`
+}
+
+type ResponseWebhooks struct {
+	Status   string    `json:"Status"`
+	Webhooks []Webhook `json:"Webhooks"`
+}
+
+type WebhookDelivery struct {
+	ID            int             `db:"id" json:"ID"`
+	WebhookID     int             `db:"webhook_id" json:"WebhookID"`
+	Event         string          `db:"event" json:"Event"`
+	Payload       json.RawMessage `db:"payload" json:"Payload" swaggertype:"object"`
+	Status        string          `db:"status" json:"Status"`
+	Attempts      int             `db:"attempts" json:"Attempts"`
+	ResponseCode  *int            `db:"response_code" json:"ResponseCode"`
+	LastError     *string         `db:"last_error" json:"LastError"`
+	NextAttemptAt time.Time       `db:"next_attempt_at" json:"NextAttemptAt"`
+	CreatedAt     time.Time       `db:"created_at" json:"CreatedAt"`
+	DeliveredAt   *time.Time      `db:"delivered_at" json:"DeliveredAt"`
+}
+
+type ResponseWebhookDeliveries struct {
+	Status     string            `json:"Status"`
+	Deliveries []WebhookDelivery `json:"Deliveries"`
+	NextCursor string            `json:"NextCursor"`
+}
+
+// PendingWebhookDelivery - доставка, которую диспетчер должен отправить
+type PendingWebhookDelivery struct {
+	ID        int             `db:"id"`
+	URL       string          `db:"url"`
+	Secret    string          `db:"secret"`
+	Event     string          `db:"event"`
+	Payload   json.RawMessage `db:"payload"`
+	Attempts  int             `db:"attempts"`
+	CreatedAt time.Time       `db:"created_at"`
+}
+
+// RequestSavedSearch - сохранённый поиск. Фильтры такие же, как у /vac/search, незаданный фильтр не учитывается.
+// Frequency: instant - письмо сразу после появления вакансий, daily - раз в сутки, weekly - раз в неделю, off - без писем
+type RequestSavedSearch struct {
+	Name      string  `json:"Name"`
+	ExpID     *int    `json:"ExpID"`
+	Min       *int    `json:"Min"` // зарплата в рублях за месяц, как у /vac/search без Currency
+	Max       *int    `json:"Max"`
+	Text      *string `json:"Text"`
+	Frequency string  `json:"Frequency"`
+}
+
+type SavedSearch struct {
+	ID        int       `db:"id" json:"ID"`
+	Name      string    `db:"name" json:"Name"`
+	ExpID     *int      `db:"exp_id" json:"ExpID"`
+	Min       *int      `db:"min_price" json:"Min"`
+	Max       *int      `db:"max_price" json:"Max"`
+	Text      *string   `db:"text" json:"Text"`
+	Frequency string    `db:"frequency" json:"Frequency"`
+	LastRunAt time.Time `db:"last_run_at" json:"LastRunAt"`
+	CreatedAt time.Time `db:"created_at" json:"CreatedAt"`
+}
+
+type ResponseSavedSearches struct {
+	Status   string        `json:"Status"`
+	Searches []SavedSearch `json:"Searches"`
+}
+
+type ResponseSavedSearch struct {
+	Status string      `json:"Status"`
+	Search SavedSearch `json:"Search"`
+}
+
+// DueSavedSearch - сохранённый поиск, по которому пора отправить письмо
+type DueSavedSearch struct {
+	SavedSearch
+	LastPublishedAt  time.Time `db:"last_published_at"`
+	UnsubscribeToken string    `db:"unsubscribe_token"`
+	CandidateName    string    `db:"candidate_name"`
+	CandidateEmail   string    `db:"candidate_email"`
+}
+
+// Job - фоновая задача планировщика
+type Job struct {
+	Name        string    `json:"Name"`
+	Description string    `json:"Description"`
+	Schedule    string    `json:"Schedule"`
+	NextRunAt   time.Time `json:"NextRunAt"`
+	Running     bool      `json:"Running"` // выполняется ли задача на этом экземпляре сервера прямо сейчас
+	LastRun     *JobRun   `json:"LastRun"`
+}
+
+// JobRun - запись в истории запусков фоновой задачи
+type JobRun struct {
+	ID          int        `db:"id" json:"ID"`
+	Job         string     `db:"job" json:"Job"`
+	Trigger     string     `db:"trigger" json:"Trigger"`
+	Status      string     `db:"status" json:"Status"`
+	Error       *string    `db:"error" json:"Error"`
+	Instance    string     `db:"instance" json:"Instance"`
+	ScheduledAt *time.Time `db:"scheduled_at" json:"ScheduledAt"`
+	StartedAt   time.Time  `db:"started_at" json:"StartedAt"`
+	FinishedAt  *time.Time `db:"finished_at" json:"FinishedAt"`
+}
+
+type ResponseJobs struct {
+	Status string `json:"Status"`
+	Jobs   []Job  `json:"Jobs"`
+}
+
+type ResponseJobRuns struct {
+	Status     string   `json:"Status"`
+	Runs       []JobRun `json:"Runs"`
+	NextCursor string   `json:"NextCursor"`
+}
+
+// ExpiringVacancy - опубликованная вакансия, срок которой скоро закончится
+type ExpiringVacancy struct {
+	ID            int       `db:"id"`
+	Name          string    `db:"name"`
+	ExpiresAt     time.Time `db:"expires_at"`
+	EmployerName  string    `db:"employer_name"`
+	EmployerEmail string    `db:"employer_email"`
+}
+
+type ResponseRenewVacancy struct {
+	Status    string    `json:"Status"`
+	ExpiresAt time.Time `json:"ExpiresAt"`
+}
+
+// ModerationVacancy - вакансия в очереди модерации
+type ModerationVacancy struct {
+	VacancyData_Limit
+	SubmittedAt      *time.Time `db:"submitted_at" json:"SubmittedAt"`
+	ModerationReason *string    `db:"moderation_reason" json:"ModerationReason"` // причина прошлого отказа, если вакансию уже отклоняли
+}
+
+type ResponseModerationQueue struct {
+	Status        string              `json:"Status"`
+	VacanciesInfo []ModerationVacancy `json:"VacancyInfo"`
+	NextCursor    string              `json:"NextCursor"`
+}
+
+type RequestModerationReject struct {
+	Reason string `json:"Reason"`
+}
+
+// VacancyRevision - снимок содержимого вакансии после создания или изменения
+type VacancyRevision struct {
+	ID          int       `db:"id" json:"ID"`
+	VacancyID   int       `db:"vacancy_id" json:"VacancyID"`
+	Number      int       `db:"number" json:"Number"`
+	Name        string    `db:"name" json:"Name"`
+	Salary      Salary    `db:"salary" json:"Salary"`
+	Email       string    `db:"email" json:"Email"`
+	PhoneNumber string    `db:"phone_number" json:"PhoneNumber"`
+	Location    string    `db:"location" json:"Location"`
+	Experience  GetStatus `db:"experience" json:"ExperienceInfo"`
+	AboutWork   string    `db:"about_work" json:"AboutWork"`
+	EditorRole  string    `db:"editor_role" json:"EditorRole"`
+	EditorID    int       `db:"editor_id" json:"EditorID"`
+	CreatedAt   time.Time `db:"created_at" json:"CreatedAt"`
+}
+
+type ResponseVacancyRevisions struct {
+	Status     string            `json:"Status"`
+	Revisions  []VacancyRevision `json:"Revisions"`
+	NextCursor string            `json:"NextCursor"`
+}
+
+// RevisionChange - поле, которое отличается в двух редакциях вакансии
+type RevisionChange struct {
+	Field string `json:"Field"`
+	From  any    `json:"From"`
+	To    any    `json:"To"`
+}
+
+type ResponseRevisionDiff struct {
+	Status  string           `json:"Status"`
+	From    VacancyRevision  `json:"From"`
+	To      VacancyRevision  `json:"To"`
+	Changes []RevisionChange `json:"Changes"`
+}
+
+// Currency - курс валюты: сколько рублей стоит одна единица валюты
+type Currency struct {
+	Code      string    `db:"code" json:"Code"`
+	Rate      float64   `db:"rate" json:"Rate"`
+	UpdatedAt time.Time `db:"updated_at" json:"UpdatedAt"`
+}
+
+type ResponseCurrencies struct {
+	Status string     `json:"Status"`
+	Data   []Currency `json:"Data"`
+}
+
+// Skill - навык из общего справочника вакансий и резюме. Synonyms - массив {"ID", "Name"}
+type Skill struct {
+	ID        int             `db:"id" json:"ID"`
+	Name      string          `db:"name" json:"Name"`
+	Synonyms  json.RawMessage `db:"synonyms" json:"Synonyms" swaggertype:"array,object"`
+	Vacancies int             `db:"vacancies" json:"Vacancies"` // в скольких вакансиях указан навык
+	Resumes   int             `db:"resumes" json:"Resumes"`     // в скольких резюме указан навык
+	CreatedAt time.Time       `db:"created_at" json:"CreatedAt"`
+}
+
+// ResumeSearchFilter - фильтры поиска резюме работодателем. Зарплата - в рублях за месяц, пустые значения - фильтр не задан
+type ResumeSearchFilter struct {
+	Text      string
+	ExpID     int
+	SkillIDs  []int
+	City      string
+	SalaryMin *int
+	SalaryMax *int
+}
+
+// ResumeCard - резюме в поиске работодателя. Почта и телефон заполнены, только если ContactsVisible:
+// соискатель откликался на вакансии работодателя или принял его запрос контактов
+type ResumeCard struct {
+	ID            int             `db:"id" json:"ID"`
+	CandidateID   int             `db:"candidate_id" json:"CandidateID"`
+	CandidateName string          `db:"candidate_name" json:"CandidateName"`
+	Experience    GetStatus       `db:"experience" json:"ExperienceInfo"`
+	Description   string          `db:"description" json:"Description"`
+	Skills        json.RawMessage `db:"skills" json:"Skills" swaggertype:"array,object"`
+	ResumeDetails
+	UpdatedAt       time.Time `db:"updated_at" json:"UpdatedAt"`
+	Rank            float64   `db:"rank" json:"Rank"`
+	ContactsVisible bool      `db:"contacts_visible" json:"ContactsVisible"`
+	Email           *string   `db:"email" json:"Email"`
+	PhoneNumber     *string   `db:"phone_number" json:"PhoneNumber"`
+}
+
+type ResponseResumeSearch struct {
+	Status     string       `json:"Status"`
+	Resumes    []ResumeCard `json:"Resumes"`
+	NextCursor string       `json:"NextCursor"`
+}
+
+// ContactRequest - запрос работодателя на контакты соискателя. Status: pending, accepted или declined
+type ContactRequest struct {
+	ID            int        `db:"id" json:"ID"`
+	EmployerID    int        `db:"employer_id" json:"EmployerID"`
+	EmployerName  string     `db:"employer_name" json:"EmployerName"`
+	CandidateID   int        `db:"candidate_id" json:"CandidateID"`
+	CandidateName string     `db:"candidate_name" json:"CandidateName"`
+	ResumeID      *int       `db:"resume_id" json:"ResumeID"`
+	Status        string     `db:"status" json:"Status"`
+	CreatedAt     time.Time  `db:"created_at" json:"CreatedAt"`
+	AnsweredAt    *time.Time `db:"answered_at" json:"AnsweredAt"`
+}
+
+type ResponseContactRequests struct {
+	Status   string           `json:"Status"`
+	Requests []ContactRequest `json:"Requests"`
+}
+
+type ResponseContactRequest struct {
+	Status  string         `json:"Status"`
+	Request ContactRequest `json:"Request"`
+}
+
+type ResponseSkill struct {
+	Status string `json:"Status"`
+	Data   Skill  `json:"Data"`
+}
+
+type ResponseSkills struct {
+	Status     string  `json:"Status"`
+	Data       []Skill `json:"Data"`
+	NextCursor string  `json:"NextCursor"`
+}
+
+// SkillSuggestion - подсказка автодополнения. Synonym заполнен, если совпал синоним, а не название навыка
+type SkillSuggestion struct {
+	ID      int     `db:"id" json:"ID"`
+	Name    string  `db:"name" json:"Name"`
+	Synonym *string `db:"synonym" json:"Synonym"`
+}
+
+type ResponseSkillSuggestions struct {
+	Status string            `json:"Status"`
+	Data   []SkillSuggestion `json:"Data"`
+}
@@ -2,6 +2,7 @@ package employee
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -11,7 +12,10 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/jmoiron/sqlx"
 	s "main.go/internal/api/Struct"
+	"main.go/internal/api/etag"
 	get "main.go/internal/api/get"
+	"main.go/internal/api/page"
+	"main.go/internal/notify"
 	sqlp "main.go/internal/storage/postSQL"
 )
 
@@ -130,6 +134,14 @@ func PatchEmployerStatus(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		if err := notify.EmployerStatusChanged(tx, EmpID, StatusID); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при создании уведомления работодателю об изменении статуса",
+				"Error":  err.Error(),
+			})
+			return
+		}
 		ctx.JSON(200, gin.H{
 			"Status": "Ok!",
 			"Info":   "Данные успешно обновлены!",
@@ -144,9 +156,12 @@ func PatchEmployerStatus(storag *sqlx.DB) gin.HandlerFunc {
 // @Accept json
 // @Produce json
 // @Param EmployerInfo body s.RequestEmployer true "Данные о работодателе, на которые нужно обновить в системе"
+// @Param If-Match header string false "ETag, полученный вместе с данными работодателя. Если данные успели измениться, то вернётся 412"
 // @Success 200 {object} s.StatusInfo "Возвращает статус 'Ok!' и небольшую информацию"
 // @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
 // @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если работодатель не найден или удалён"
+// @Failure 412 {object} s.InfoError "Возвращает ошибку, если данные работодателя уже были изменены кем-то другим"
 // @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
 // @Router /emp [put]
 func PutEmployeeInfo(storag *sqlx.DB) gin.HandlerFunc {
@@ -211,8 +226,31 @@ func PutEmployeeInfo(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
-		err := sqlp.UpdateEmployeeInfo(tx, req, uid)
+		version, err := etag.FromIfMatch(ctx)
 		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в заголовке If-Match! Передайте в нём ETag, который был получен вместе с данными",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		newVersion, err := sqlp.UpdateEmployeeInfo(tx, req, uid, version)
+		if err == sqlp.ErrVersionMismatch {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{
+				"Status": "Err",
+				"Info":   "Данные работодателя уже были изменены кем-то другим! Получите актуальные данные и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		} else if errors.Is(err, sqlp.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"Status": "Err",
+				"Info":   "Работодатель не был найден или уже удалён!",
+				"Error":  err.Error(),
+			})
+			return
+		} else if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"Status": "Err",
 				"Info":   "Ошибка в SQL файле для обновления данных резюме пользователя",
@@ -221,6 +259,7 @@ func PutEmployeeInfo(storag *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
+		etag.Set(ctx, newVersion)
 		ctx.JSON(200, gin.H{
 			"Status": "Ok!",
 			"Info":   "Данные успешно обновлены!",
@@ -315,7 +354,9 @@ func PostNewEmployer(storage *sqlx.DB) gin.HandlerFunc {
 // @Security ApiKeyAuth
 // @Accept json
 // @Produce json
-// @Success 200 {object} s.SuccessAllEmployers "Возвращает статус 'Ok!' и массив всех данных о работодателях"
+// @Param Cursor query string false "Курсор следующей страницы (NextCursor из предыдущего ответа). Если не передан - отдаётся первая страница"
+// @Param Limit query int false "Кол-во записей на странице. По умолчанию 20, максимум 100"
+// @Success 200 {object} s.SuccessAllEmployers "Возвращает статус 'Ok!', массив данных о работодателях и курсор следующей страницы (пустой, если страница последняя)"
 // @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
 // @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
 // @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
@@ -338,7 +379,16 @@ func GetAllEmployee(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
-		data, err := sqlp.GetAllEmployee(tx)
+		after, limit, err := page.FromQuery(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при попытке получить параметры страницы (Cursor, Limit)! проверьте их и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, err := sqlp.GetAllEmployee(tx, after.ID, limit+1)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"Status": "Err",
@@ -348,9 +398,11 @@ func GetAllEmployee(storag *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
+		data, next := page.Cut(data, limit, func(e s.SuccessEmployer) s.PageCursor { return s.PageCursor{ID: e.ID} })
 		ctx.JSON(200, gin.H{
 			"Status":        "Ok!",
 			"EmployersInfo": data,
+			"NextCursor":    next,
 		})
 
 	}
@@ -364,6 +416,7 @@ func GetAllEmployee(storag *sqlx.DB) gin.HandlerFunc {
 // @Produce json
 // @Param EmployerID query int true "ID работодателя"
 // @Success 200 {object} s.ResponseEmployerInfo "Возвращает статус 'Ok!' и данные о работодателе"
+// @Header 200 {string} ETag "Версия данных, которую нужно передать в If-Match при обновлении"
 // @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
 // @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
 // @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
@@ -391,6 +444,7 @@ func GetEmployeeInfo(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		etag.Set(ctx, data.Version)
 		// if emp_id == uid || role == "ADMIN" {
 		// 	fmt.Println("Работодатель получил свои данные или админом")
 		// } else {
@@ -464,3 +518,224 @@ func AuthorizationMethodEmp(storag *sqlx.DB) gin.HandlerFunc {
 
 	}
 }
+
+// @Summary Список удалённых работодателей
+// @Description Позволяет получить список работодателей, которые были удалены, но ещё не стёрты из системы окончательно. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} s.ResponseDeletedEntities "Возвращает статус 'Ok!' и массив удалённых работодателей"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/emp/deleted [get]
+func GetDeletedEmployers(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "ADMIN" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		data, err := sqlp.GetDeletedEmployers(tx)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":      "Ok!",
+			"DeletedInfo": data,
+		})
+	}
+}
+
+// @Summary Восстановить удалённого работодателя
+// @Description Позволяет вернуть удалённого работодателя вместе с его вакансиями, пока он не был стёрт из системы окончательно. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Produce json
+// @Param EmployerID query int true "ID работодателя, которого нужно восстановить"
+// @Success 200 {object} s.StatusInfo "Возвращает статус и краткую информацию "
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/emp/restore [patch]
+func RestoreUser(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "ADMIN" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		user, err := strconv.Atoi(ctx.Query("EmployerID"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Error":  err.Error(),
+				"Info":   "Ошибка при попытке получить ID работодателя! проверьте его и попробуйте снова",
+			})
+			return
+		}
+		err = sqlp.RestoreEmployee(tx, user)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+			"Info":   "Данные успешно восстановлены!",
+		})
+	}
+}
+
+// @Summary Настройки уведомлений работодателя
+// @Description Позволяет получить, как работодатель хочет узнавать о новых откликах на свои вакансии. Доступ имеет только роль employee
+// @Security ApiKeyAuth
+// @Tags Employer
+// @Produce json
+// @Success 200 {object} s.ResponseEmployerNotifySettings "Возвращает статус 'Ok!' и настройки уведомлений"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /emp/notify [get]
+func GetNotifySettings(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "employee" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		uid, ok := get.GetUserIDFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить ID пользователя из заголовка токена",
+			})
+			return
+		}
+		data, err := sqlp.GetEmployerNotifySettings(tx, uid)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":   "Ok!",
+			"Settings": data,
+		})
+	}
+}
+
+// @Summary Обновить настройки уведомлений работодателя
+// @Description Позволяет выбрать, как узнавать о новых откликах. NewResponse: off - не уведомлять, immediate - письмо на каждый отклик, hourly - сводка раз в час, daily - сводка раз в сутки. В сводке отклики сгруппированы по вакансиям. Доступ имеет только роль employee
+// @Security ApiKeyAuth
+// @Tags Employer
+// @Accept json
+// @Produce json
+// @Param Settings body s.EmployerNotifySettings true "Новые настройки уведомлений"
+// @Success 200 {object} s.ResponseEmployerNotifySettings "Возвращает статус 'Ok!' и сохранённые настройки уведомлений"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /emp/notify [put]
+func PutNotifySettings(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "employee" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		uid, ok := get.GetUserIDFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить ID пользователя из заголовка токена",
+			})
+			return
+		}
+		var req s.EmployerNotifySettings
+		if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в парсинге запроса! Пожалуйста перепроверьте ваши данные в Body запроса и попробуйте снова!",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		switch req.NewResponse {
+		case "off", "immediate", "hourly", "daily":
+		default:
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Неизвестный режим уведомлений! Доступные: off, immediate, hourly, daily",
+			})
+			return
+		}
+		if err := sqlp.UpdateEmployerNotifySettings(tx, uid, req); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":   "Ok!",
+			"Settings": req,
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package etag
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Set выставляет заголовок ETag по версии записи
+func Set(ctx *gin.Context, version int) {
+	ctx.Header("ETag", fmt.Sprintf("\"%d\"", version))
+}
+
+// FromIfMatch достаёт версию записи из заголовка If-Match.
+// Если заголовка нет (или передан '*'), то возвращается 0 и обновление выполняется без проверки версии
+func FromIfMatch(ctx *gin.Context) (int, error) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	value := strings.Trim(strings.TrimPrefix(header, "W/"), "\"")
+	version, err := strconv.Atoi(value)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("неверный формат заголовка If-Match: %s", header)
+	}
+	return version, nil
+}
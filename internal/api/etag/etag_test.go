@@ -0,0 +1,58 @@
+package etag
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFromIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    int
+		wantErr bool
+	}{
+		{name: "без заголовка", header: "", want: 0},
+		{name: "звёздочка", header: "*", want: 0},
+		{name: "версия в кавычках", header: `"7"`, want: 7},
+		{name: "слабый ETag", header: `W/"12"`, want: 12},
+		{name: "без кавычек", header: "3", want: 3},
+		{name: "пробелы по краям", header: ` "5" `, want: 5},
+		{name: "не число", header: `"abc"`, wantErr: true},
+		{name: "нулевая версия", header: `"0"`, wantErr: true},
+		{name: "отрицательная версия", header: `"-1"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest("PUT", "/", nil)
+			if tt.header != "" {
+				ctx.Request.Header.Set("If-Match", tt.header)
+			}
+			got, err := FromIfMatch(ctx)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("FromIfMatch(%q) не вернул ошибку", tt.header)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FromIfMatch(%q): %v", tt.header, err)
+			}
+			if got != tt.want {
+				t.Errorf("FromIfMatch(%q) = %d, ожидалось %d", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	Set(ctx, 42)
+	if got := recorder.Header().Get("ETag"); got != `"42"` {
+		t.Errorf("ETag = %s, ожидалось \"42\"", got)
+	}
+}
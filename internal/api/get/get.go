@@ -1,6 +1,11 @@
 package get
 
-import "github.com/gin-gonic/gin"
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
 
 func GetUserRoleFromContext(ctx *gin.Context) (string, bool) {
 	roleGet, fjd := ctx.Get("role")
@@ -42,3 +47,53 @@ func GetUserIDFromContext(ctx *gin.Context) (int, bool) {
 	}
 	return uid, true
 }
+
+// UserIDWithRole проверяет, что запрос сделал пользователь роли role, и возвращает его ID.
+// Если нет - отвечает 400 или 401 и возвращает false
+func UserIDWithRole(ctx *gin.Context, role string) (int, bool) {
+	if !HasRole(ctx, role) {
+		return 0, false
+	}
+	uid, ok := GetUserIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "ошибка в попытке получить ID пользователя из заголовка токена",
+		})
+		return 0, false
+	}
+	return uid, true
+}
+
+// HasRole проверяет, что запрос сделал пользователь роли role. Если нет - отвечает 400 или 401 и возвращает false
+func HasRole(ctx *gin.Context, role string) bool {
+	userRole, ok := GetUserRoleFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "ошибка в попытке получить роль пользователя из заголовка токена",
+		})
+		return false
+	}
+	if userRole != role {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"Status": "Err",
+			"Info":   "У вас нету прав к этому функционалу!",
+		})
+		return false
+	}
+	return true
+}
+
+// QueryID достаёт из запроса положительный ID из параметра name. Если его нет - отвечает 400 и возвращает false
+func QueryID(ctx *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(ctx.Query(name))
+	if err != nil || id <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "Ошибка при попытке получить " + name + "! Перепроверьте его и попробуйте снова",
+		})
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,140 @@
+package jobs
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	s "main.go/internal/api/Struct"
+	"main.go/internal/api/get"
+	"main.go/internal/api/page"
+	"main.go/internal/scheduler"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+// @Summary Список фоновых задач
+// @Description Возвращает все задачи планировщика: расписание, время следующего запуска, выполняется ли задача сейчас и результат последнего запуска. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} s.ResponseJobs "Возвращает статус 'Ok!' и массив задач"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/jobs [get]
+func GetJobs(storage *sqlx.DB, sc *scheduler.Scheduler) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		if !get.HasRole(ctx, "ADMIN") {
+			return
+		}
+		last, err := sqlp.GetLastJobRuns(tx)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data := sc.Jobs()
+		for i := range data {
+			if run, ok := last[data[i].Name]; ok {
+				data[i].LastRun = &run
+			}
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+			"Jobs":   data,
+		})
+	}
+}
+
+// @Summary История запусков фоновых задач
+// @Description Возвращает запуски задач планировщика от новых к старым. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Produce json
+// @Param Name query string false "Имя задачи. Если не указано - запуски всех задач"
+// @Param Cursor query string false "Курсор следующей страницы (NextCursor из прошлого ответа)"
+// @Param Limit query int false "Количество записей на странице"
+// @Success 200 {object} s.ResponseJobRuns "Возвращает статус 'Ok!', массив запусков и курсор следующей страницы"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/jobs/runs [get]
+func GetJobRuns(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		if !get.HasRole(ctx, "ADMIN") {
+			return
+		}
+		before, limit, err := page.FromQuery(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при попытке получить параметры страницы (Cursor, Limit)! проверьте их и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, err := sqlp.GetJobRuns(tx, ctx.Query("Name"), before.ID, limit+1)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, next := page.Cut(data, limit, func(r s.JobRun) s.PageCursor { return s.PageCursor{ID: r.ID} })
+		ctx.JSON(200, gin.H{
+			"Status":     "Ok!",
+			"Runs":       data,
+			"NextCursor": next,
+		})
+	}
+}
+
+// @Summary Запустить фоновую задачу вручную
+// @Description Запускает задачу планировщика вне расписания. Задача выполняется в фоне, результат появится в истории запусков. Если задачу в этот момент выполняет другой экземпляр сервера, то запуск будет записан в историю со статусом skipped. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Produce json
+// @Param Name query string true "Имя задачи"
+// @Success 202 {object} s.StatusInfo "Возвращает статус 'Ok!', если задача запущена"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если задачи с таким именем нет."
+// @Failure 409 {object} s.InfoError "Возвращает ошибку, если задача уже выполняется."
+// @Router /adm/jobs/run [post]
+func RunJob(sc *scheduler.Scheduler) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !get.HasRole(ctx, "ADMIN") {
+			return
+		}
+		name := ctx.Query("Name")
+		err := sc.Trigger(name)
+		switch {
+		case errors.Is(err, scheduler.ErrUnknownJob):
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"Status": "Err",
+				"Info":   "Задачи с таким именем нет! Список задач можно получить через /adm/jobs",
+				"Error":  err.Error(),
+			})
+			return
+		case errors.Is(err, scheduler.ErrJobRunning):
+			ctx.JSON(http.StatusConflict, gin.H{
+				"Status": "Err",
+				"Info":   "Задача уже выполняется, дождитесь её окончания",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(http.StatusAccepted, gin.H{
+			"Status": "Ok!",
+			"Info":   "Задача " + name + " запущена",
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package notification
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	s "main.go/internal/api/Struct"
+	"main.go/internal/api/get"
+	"main.go/internal/api/page"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+// recipient достаёт из токена роль и ID пользователя, которому принадлежат уведомления.
+// Уведомления есть только у соискателей и работодателей, остальным ролям (ADMIN) отвечаем 401
+func recipient(ctx *gin.Context) (string, int, bool) {
+	role, ok := get.GetUserRoleFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "ошибка в попытке получить роль пользователя из заголовка токена",
+		})
+		return "", 0, false
+	}
+	if role != "candidate" && role != "employee" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"Status": "Err",
+			"Info":   "У вас нету прав к этому функционалу!",
+		})
+		return "", 0, false
+	}
+	uid, ok := get.GetUserIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "ошибка в попытке получить ID пользователя из заголовка токена",
+		})
+		return "", 0, false
+	}
+	return role, uid, true
+}
+
+// @Summary Уведомления пользователя
+// @Description Позволяет получить уведомления текущего пользователя (соискателя или работодателя, для ADMIN - 401) от новых к старым. Type - вид уведомления (response_created, response_status_changed, employer_status_changed), Payload - данные, которые зависят от вида
+// @Security ApiKeyAuth
+// @Tags Notification
+// @Produce json
+// @Param Unread query bool false "Если true - вернутся только непрочитанные уведомления"
+// @Param Cursor query string false "Курсор следующей страницы (NextCursor из предыдущего ответа). Если не передан - отдаётся первая страница"
+// @Param Limit query int false "Кол-во записей на странице. По умолчанию 20, максимум 100"
+// @Success 200 {object} s.ResponseNotifications "Возвращает статус 'Ok!', массив уведомлений и курсор следующей страницы (пустой, если страница последняя)"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу (уведомления есть только у соискателей и работодателей)."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /notifications [get]
+func GetNotifications(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, uid, ok := recipient(ctx)
+		if !ok {
+			return
+		}
+		before, limit, err := page.FromQuery(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при попытке получить параметры страницы (Cursor, Limit)! проверьте их и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		unreadOnly := false
+		if value := ctx.Query("Unread"); value != "" {
+			unreadOnly, err = strconv.ParseBool(value)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{
+					"Status": "Err",
+					"Info":   "Ошибка при попытке получить параметр Unread! Он должен быть true или false",
+					"Error":  err.Error(),
+				})
+				return
+			}
+		}
+		data, err := sqlp.GetNotifications(tx, role, uid, before.ID, limit+1, unreadOnly)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, next := page.Cut(data, limit, func(n s.Notification) s.PageCursor { return s.PageCursor{ID: n.ID} })
+		ctx.JSON(200, gin.H{
+			"Status":        "Ok!",
+			"Notifications": data,
+			"NextCursor":    next,
+		})
+	}
+}
+
+// @Summary Количество непрочитанных уведомлений
+// @Description Позволяет получить количество непрочитанных уведомлений текущего пользователя, например для счётчика в шапке сайта
+// @Security ApiKeyAuth
+// @Tags Notification
+// @Produce json
+// @Success 200 {object} s.ResponseUnreadNotifications "Возвращает статус 'Ok!' и количество непрочитанных уведомлений"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу (уведомления есть только у соискателей и работодателей)."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /notifications/unread [get]
+func GetUnreadCount(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, uid, ok := recipient(ctx)
+		if !ok {
+			return
+		}
+		count, err := sqlp.CountUnreadNotifications(tx, role, uid)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+			"Unread": count,
+		})
+	}
+}
+
+// @Summary Отметить уведомление прочитанным
+// @Description Позволяет отметить одно уведомление текущего пользователя прочитанным
+// @Security ApiKeyAuth
+// @Tags Notification
+// @Produce json
+// @Param ID query int true "ID уведомления"
+// @Success 200 {object} s.StatusInfo "Возвращает статус 'Ok!' и небольшую информацию"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу (уведомления есть только у соискателей и работодателей)."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /notifications/read [patch]
+func MarkRead(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, uid, ok := recipient(ctx)
+		if !ok {
+			return
+		}
+		id, err := strconv.Atoi(ctx.Query("ID"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при попытке получить ID уведомления! проверьте его и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		if err := sqlp.MarkNotificationRead(tx, role, uid, id); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+			"Info":   "Уведомление отмечено прочитанным!",
+		})
+	}
+}
+
+// @Summary Отметить все уведомления прочитанными
+// @Description Позволяет отметить прочитанными все уведомления текущего пользователя
+// @Security ApiKeyAuth
+// @Tags Notification
+// @Produce json
+// @Success 200 {object} s.StatusInfo "Возвращает статус 'Ok!' и небольшую информацию"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу (уведомления есть только у соискателей и работодателей)."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /notifications/read-all [patch]
+func MarkAllRead(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, uid, ok := recipient(ctx)
+		if !ok {
+			return
+		}
+		if _, err := sqlp.MarkAllNotificationsRead(tx, role, uid); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+			"Info":   "Все уведомления отмечены прочитанными!",
+		})
+	}
+}
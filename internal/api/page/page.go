@@ -0,0 +1,66 @@
+package page
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	s "main.go/internal/api/Struct"
+)
+
+const (
+	// DefaultLimit - размер страницы, если клиент не передал Limit
+	DefaultLimit = 20
+	// MaxLimit - больше этого количества записей за один запрос не отдаём
+	MaxLimit = 100
+)
+
+// FromQuery достаёт из запроса курсор (параметр Cursor) и размер страницы (параметр Limit).
+// Пустой курсор означает первую страницу. Limit больше MaxLimit урезается до MaxLimit
+func FromQuery(ctx *gin.Context) (s.PageCursor, int, error) {
+	var cursor s.PageCursor
+
+	limit := DefaultLimit
+	if value := ctx.Query("Limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return cursor, 0, fmt.Errorf("неверное значение Limit: %s", value)
+		}
+		if limit > MaxLimit {
+			limit = MaxLimit
+		}
+	}
+
+	value := ctx.Query("Cursor")
+	if value == "" {
+		return cursor, limit, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, 0, fmt.Errorf("неверный формат Cursor: %s", err.Error())
+	}
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.ID <= 0 {
+		return cursor, 0, fmt.Errorf("неверный формат Cursor: %s", value)
+	}
+	return cursor, limit, nil
+}
+
+// Encode превращает позицию последней отданной записи в непрозрачную строку для клиента
+func Encode(cursor s.PageCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// Cut обрезает выборку до limit записей и возвращает курсор на следующую страницу.
+// Из базы нужно запрашивать limit+1 записей: лишняя запись говорит о том, что следующая страница есть.
+// Если следующей страницы нет, то курсор пустой
+func Cut[T any](items []T, limit int, key func(T) s.PageCursor) ([]T, string) {
+	if len(items) <= limit {
+		return items, ""
+	}
+	items = items[:limit]
+	return items, Encode(key(items[limit-1]))
+}
@@ -0,0 +1,82 @@
+package page
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	s "main.go/internal/api/Struct"
+)
+
+func queryContext(params url.Values) *gin.Context {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", "/?"+params.Encode(), nil)
+	return ctx
+}
+
+func TestFromQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     url.Values
+		wantCursor s.PageCursor
+		wantLimit  int
+		wantErr    bool
+	}{
+		{name: "первая страница", params: url.Values{}, wantLimit: DefaultLimit},
+		{name: "свой Limit", params: url.Values{"Limit": {"5"}}, wantLimit: 5},
+		{name: "Limit больше максимума", params: url.Values{"Limit": {"1000"}}, wantLimit: MaxLimit},
+		{name: "курсор по ID", params: url.Values{"Cursor": {Encode(s.PageCursor{ID: 17})}}, wantCursor: s.PageCursor{ID: 17}, wantLimit: DefaultLimit},
+		{
+			name:       "курсор с оценкой",
+			params:     url.Values{"Cursor": {Encode(s.PageCursor{ID: 3, Rank: 0.25})}, "Limit": {"10"}},
+			wantCursor: s.PageCursor{ID: 3, Rank: 0.25},
+			wantLimit:  10,
+		},
+		{name: "нулевой Limit", params: url.Values{"Limit": {"0"}}, wantErr: true},
+		{name: "Limit не число", params: url.Values{"Limit": {"ten"}}, wantErr: true},
+		{name: "курсор не base64", params: url.Values{"Cursor": {"!!!"}}, wantErr: true},
+		{name: "курсор не JSON", params: url.Values{"Cursor": {"bm90LWpzb24"}}, wantErr: true},
+		{name: "курсор без ID", params: url.Values{"Cursor": {Encode(s.PageCursor{})}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, limit, err := FromQuery(queryContext(tt.params))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("FromQuery не вернул ошибку")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FromQuery: %v", err)
+			}
+			if cursor != tt.wantCursor || limit != tt.wantLimit {
+				t.Errorf("FromQuery = %+v, %d, ожидалось %+v, %d", cursor, limit, tt.wantCursor, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestCut(t *testing.T) {
+	key := func(id int) s.PageCursor { return s.PageCursor{ID: id} }
+	tests := []struct {
+		name     string
+		items    []int
+		limit    int
+		wantLen  int
+		wantNext string
+	}{
+		{name: "меньше страницы", items: []int{1, 2}, limit: 3, wantLen: 2, wantNext: ""},
+		{name: "ровно страница", items: []int{1, 2, 3}, limit: 3, wantLen: 3, wantNext: ""},
+		{name: "есть следующая страница", items: []int{1, 2, 3, 4}, limit: 3, wantLen: 3, wantNext: Encode(s.PageCursor{ID: 3})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, next := Cut(tt.items, tt.limit, key)
+			if len(items) != tt.wantLen || next != tt.wantNext {
+				t.Errorf("Cut = %v, %q, ожидалось %d записей и %q", items, next, tt.wantLen, tt.wantNext)
+			}
+		})
+	}
+}
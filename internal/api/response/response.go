@@ -1,6 +1,7 @@
 package response
 
 import (
+	"database/sql"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -9,6 +10,8 @@ import (
 	"github.com/jmoiron/sqlx"
 	s "main.go/internal/api/Struct"
 	"main.go/internal/api/get"
+	"main.go/internal/lifecycle"
+	"main.go/internal/notify"
 	sqlp "main.go/internal/storage/postSQL"
 )
 
@@ -126,7 +129,7 @@ func DeleteResponse(storage *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
-		err = sqlp.DeleteResponse(tx, vac_id, uid)
+		resp_id, err := sqlp.DeleteResponse(tx, vac_id, uid)
 		if err != nil {
 			ctx.JSON(http.StatusUnprocessableEntity, gin.H{
 				"Status": "Err",
@@ -135,6 +138,14 @@ func DeleteResponse(storage *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		if err := notify.ResponseWithdrawn(tx, resp_id, vac_id, uid); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при создании уведомления работодателю об отзыве отклика",
+				"Error":  err.Error(),
+			})
+			return
+		}
 		ctx.JSON(200, gin.H{
 			"Status": "Ok!",
 			"Info":   "успешно удалили данные!",
@@ -144,7 +155,7 @@ func DeleteResponse(storage *sqlx.DB) gin.HandlerFunc {
 }
 
 // @Summary Изменить статус отклика
-// @Description Позволяет изменить статус отклика на вакансию. Доступно только пользователям группы employee и ADMIN
+// @Description Позволяет изменить статус отклика на вакансию. Доступно только пользователям группы employee (только для откликов на свои вакансии) и ADMIN. Если статус изменился, то соискатель получит письмо (если не отключил такие уведомления)
 // @Tags Vacancy
 // @Security ApiKeyAuth
 // @Accept json
@@ -153,6 +164,7 @@ func DeleteResponse(storage *sqlx.DB) gin.HandlerFunc {
 // @Success 200 {object} s.StatusInfo "Возвращает статус 'Ok!' и небольшую информацию"
 // @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
 // @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если отклика нет среди откликов на вакансии работодателя"
 // @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
 // @Router /vac/response [patch]
 func PatchResponseStatus(storag *sqlx.DB) gin.HandlerFunc {
@@ -190,8 +202,26 @@ func PatchResponseStatus(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
-		err := sqlp.PatchResponse(tx, req)
-		if err != nil {
+		// работодатель может менять статус только откликов на свои вакансии, администратор - любых
+		empID := 0
+		if role == "employee" {
+			empID, ok = get.GetUserIDFromContext(ctx)
+			if !ok {
+				ctx.JSON(http.StatusBadRequest, gin.H{
+					"Status": "Err",
+					"Info":   "Ошибка в попытке получить ID пользователя из заголовка токена",
+				})
+				return
+			}
+		}
+		previousStatus, err := sqlp.PatchResponse(tx, req, empID)
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"Status": "Err",
+				"Info":   "Такого отклика нету среди откликов на ваши вакансии! Перепроверьте данные и попробуйте снова",
+			})
+			return
+		} else if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"Status": "Err",
 				"Info":   "Ошибка в SQL файле для обновления данных отклика на вакансию",
@@ -199,6 +229,16 @@ func PatchResponseStatus(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		if previousStatus != req.Status_id {
+			if err := notify.ResponseStatusChanged(tx, req.Response_id, req.Status_id); err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{
+					"Status": "Err",
+					"Info":   "Ошибка при создании уведомления соискателю об изменении статуса отклика",
+					"Error":  err.Error(),
+				})
+				return
+			}
+		}
 
 		ctx.JSON(200, gin.H{
 			"Status": "Ok!",
@@ -208,7 +248,7 @@ func PatchResponseStatus(storag *sqlx.DB) gin.HandlerFunc {
 }
 
 // @Summary Добавить новый отклик на вакансию
-// @Description Позволяет создать в системе новый отклик соискателя на вакансию.
+// @Description Позволяет создать в системе новый отклик соискателя на вакансию. Откликнуться можно только на опубликованную вакансию.
 // @Tags Vacancy
 // @Security ApiKeyAuth
 // @Accept json
@@ -254,6 +294,29 @@ func PostNewRespone(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		vac_data, err := sqlp.GetVacancyByID(tx, vac_id)
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Такой вакансии нету в системе! Перепроверьте данные и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		} else if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле вакансий",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		if vac_data.State != lifecycle.Published {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "На эту вакансию нельзя откликнуться, так как она не опубликована",
+			})
+			return
+		}
 		resp_id, err := sqlp.PostResponse(tx, uid, vac_id)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
@@ -263,11 +326,10 @@ func PostNewRespone(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
-		vac_data, err := sqlp.GetVacancyByID(tx, vac_id)
-		if err != nil {
+		if err := notify.ResponseCreated(tx, resp_id); err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"Status": "Err",
-				"Info":   "Ошибка в SQL файле вакансий",
+				"Info":   "Ошибка при создании уведомления работодателю о новом отклике",
 				"Error":  err.Error(),
 			})
 			return
@@ -0,0 +1,376 @@
+package resumes
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	s "main.go/internal/api/Struct"
+	"main.go/internal/api/get"
+	"main.go/internal/api/page"
+	"main.go/internal/notify"
+	"main.go/internal/salary"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+// searchFilter достаёт из запроса фильтры поиска резюме. Зарплата переводится в рубли по курсу Currency
+func searchFilter(ctx *gin.Context, tx *sqlx.Tx) (s.ResumeSearchFilter, error) {
+	filter := s.ResumeSearchFilter{
+		Text: strings.TrimSpace(ctx.Query("Text")),
+		City: strings.TrimSpace(ctx.Query("City")),
+	}
+	if value := ctx.Query("ExpID"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return filter, fmt.Errorf("неверное значение ExpID: %s", value)
+		}
+		filter.ExpID = id
+	}
+	seen := make(map[int]bool)
+	for _, value := range ctx.QueryArray("SkillID") {
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return filter, fmt.Errorf("неверное значение SkillID: %s", value)
+		}
+		if !seen[id] {
+			seen[id] = true
+			filter.SkillIDs = append(filter.SkillIDs, id)
+		}
+	}
+
+	rate := 1.0
+	if code := strings.ToUpper(ctx.Query("Currency")); code != "" && code != salary.BaseCurrency {
+		currency, err := sqlp.GetCurrency(tx, code)
+		if err != nil {
+			return filter, fmt.Errorf("такой валюты нету в системе, список доступных валют можно получить в /currency")
+		}
+		rate = currency.Rate
+	}
+	for _, bound := range []struct {
+		name string
+		dest **int
+	}{{"Min", &filter.SalaryMin}, {"Max", &filter.SalaryMax}} {
+		value := ctx.Query(bound.name)
+		if value == "" {
+			continue
+		}
+		amount, err := strconv.Atoi(value)
+		if err != nil || amount < 0 {
+			return filter, fmt.Errorf("неверное значение %s: %s", bound.name, value)
+		}
+		amount = int(math.Round(float64(amount) * rate))
+		*bound.dest = &amount
+	}
+	return filter, nil
+}
+
+// @Summary Поиск резюме
+// @Description Позволяет работодателю искать резюме соискателей. В выдачу попадают только резюме, которые соискатель открыл для всех работодателей (Visibility = public), и резюме с Visibility = responded, если соискатель откликался на вакансии этого работодателя. Почта и телефон соискателя возвращаются (ContactsVisible = true), только если он откликался на вакансии работодателя или принял запрос контактов (/emp/contact). С параметром Text выдача отсортирована по релевантности, без него - сначала новые резюме. Выдача идёт по курсору: Cursor - NextCursor из предыдущего ответа, Limit - сколько резюме вернуть. Доступ имеет только роль employee
+// @Security ApiKeyAuth
+// @Tags Employer
+// @Produce json
+// @Param Text query string false "Искомый текст в описании резюме. Поддерживает синтаксис веб-поиска: слова в кавычках, OR, минус перед словом"
+// @Param ExpID query int false "ID опыта из /exp"
+// @Param SkillID query []int false "ID навыка. Можно передать несколько раз - у резюме должны быть все навыки" collectionFormat(multi)
+// @Param City query string false "Город из резюме (без учёта регистра)"
+// @Param Min query int false "Ожидаемая зарплата не меньше, за месяц"
+// @Param Max query int false "Ожидаемая зарплата не больше, за месяц"
+// @Param Currency query string false "Валюта Min и Max из /currency, по умолчанию RUB"
+// @Param Cursor query string false "NextCursor из предыдущего ответа. Пустой - первая страница"
+// @Param Limit query int false "Сколько резюме вернуть (по умолчанию 20, не больше 100)"
+// @Success 200 {object} s.ResponseResumeSearch "Возвращает статус 'Ok!', карточки резюме и курсор на следующую страницу"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если фильтры, курсор или Limit неверные"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /emp/resume/search [get]
+func SearchResumes(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		empID, ok := get.UserIDWithRole(ctx, "employee")
+		if !ok {
+			return
+		}
+		filter, err := searchFilter(ctx, tx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в фильтрах поиска! Перепроверьте их и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		after, limit, err := page.FromQuery(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при попытке получить параметры страницы (Cursor, Limit)! проверьте их и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, err := sqlp.SearchResumes(tx, empID, filter, after, limit+1)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле для поиска резюме",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, next := page.Cut(data, limit, func(r s.ResumeCard) s.PageCursor { return s.PageCursor{ID: r.ID, Rank: r.Rank} })
+		ctx.JSON(200, gin.H{
+			"Status":     "Ok!",
+			"Resumes":    data,
+			"NextCursor": next,
+		})
+	}
+}
+
+// @Summary Запросить контакты соискателя
+// @Description Отправляет соискателю запрос на контакты по найденному резюме. Соискатель получает уведомление и может принять или отклонить запрос (/user/contact). После принятия почта и телефон соискателя видны работодателю в поиске резюме и в /user/resume. Запросить контакты одного соискателя можно только один раз. Доступ имеет только роль employee
+// @Security ApiKeyAuth
+// @Tags Employer
+// @Produce json
+// @Param ResumeID query int true "ID резюме из поиска"
+// @Success 200 {object} s.ResponseContactRequest "Возвращает статус 'Ok!' и запрос контактов"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если контакты уже доступны или запрос уже был отправлен"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если резюме нет или оно скрыто от работодателя"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /emp/contact [post]
+func PostContactRequest(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		empID, ok := get.UserIDWithRole(ctx, "employee")
+		if !ok {
+			return
+		}
+		resumeID, err := strconv.Atoi(ctx.Query("ResumeID"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка при попытке получить ID резюме! проверьте его и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		candidateID, err := sqlp.GetVisibleResumeCandidate(tx, empID, resumeID)
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"Status": "Err",
+				"Info":   "Такого резюме нет или соискатель скрыл его от работодателей",
+			})
+			return
+		} else if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		if _, contacts, err := sqlp.ContactAccess(tx, empID, candidateID); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		} else if contacts {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Контакты этого соискателя вам уже доступны",
+			})
+			return
+		}
+		id, err := sqlp.PostContactRequest(tx, empID, candidateID, resumeID)
+		if err == sqlp.ErrContactRequestExists {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Вы уже запрашивали контакты этого соискателя. Ответ можно посмотреть в /emp/contact",
+				"Error":  err.Error(),
+			})
+			return
+		} else if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		if err := notify.ContactRequested(tx, id); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при отправке уведомления соискателю",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		request, err := sqlp.GetContactRequest(tx, id)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":  "Ok!",
+			"Request": request,
+		})
+	}
+}
+
+// @Summary Запросы контактов работодателя
+// @Description Возвращает запросы контактов, которые отправил работодатель, и ответы соискателей. Сначала новые. Доступ имеет только роль employee
+// @Security ApiKeyAuth
+// @Tags Employer
+// @Produce json
+// @Success 200 {object} s.ResponseContactRequests "Возвращает статус 'Ok!' и массив запросов"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /emp/contact [get]
+func GetEmployerContactRequests(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		empID, ok := get.UserIDWithRole(ctx, "employee")
+		if !ok {
+			return
+		}
+		data, err := sqlp.GetContactRequests(tx, "ctr.employer_id", empID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":   "Ok!",
+			"Requests": data,
+		})
+	}
+}
+
+// @Summary Запросы контактов соискателя
+// @Description Возвращает запросы работодателей на контакты соискателя. Сначала новые. Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Produce json
+// @Success 200 {object} s.ResponseContactRequests "Возвращает статус 'Ok!' и массив запросов"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/contact [get]
+func GetCandidateContactRequests(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		uid, ok := get.UserIDWithRole(ctx, "candidate")
+		if !ok {
+			return
+		}
+		data, err := sqlp.GetContactRequests(tx, "ctr.candidate_id", uid)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":   "Ok!",
+			"Requests": data,
+		})
+	}
+}
+
+// @Summary Ответить на запрос контактов
+// @Description Позволяет соискателю принять или отклонить запрос работодателя на контакты. После принятия работодатель видит почту и телефон соискателя. Ответить можно только один раз. Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Produce json
+// @Param RequestID query int true "ID запроса контактов"
+// @Param Accept query bool true "true - открыть контакты работодателю, false - отклонить запрос"
+// @Success 200 {object} s.ResponseContactRequest "Возвращает статус 'Ok!' и запрос контактов"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если параметры неверные"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если запроса нет или на него уже ответили"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/contact [patch]
+func AnswerContactRequest(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		uid, ok := get.UserIDWithRole(ctx, "candidate")
+		if !ok {
+			return
+		}
+		id, err := strconv.Atoi(ctx.Query("RequestID"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка при попытке получить ID запроса! проверьте его и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		accept, err := strconv.ParseBool(ctx.Query("Accept"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Передайте Accept = true, чтобы открыть контакты, или false, чтобы отклонить запрос",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		status := "declined"
+		if accept {
+			status = "accepted"
+		}
+		err = sqlp.AnswerContactRequest(tx, id, uid, status)
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"Status": "Err",
+				"Info":   "Такого запроса нет или на него уже ответили",
+			})
+			return
+		} else if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		if err := notify.ContactAnswered(tx, id); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при отправке уведомления работодателю",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		request, err := sqlp.GetContactRequest(tx, id)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":  "Ok!",
+			"Request": request,
+		})
+	}
+}
@@ -0,0 +1,375 @@
+package skills
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	s "main.go/internal/api/Struct"
+	"main.go/internal/api/get"
+	"main.go/internal/api/page"
+	"main.go/internal/skill"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+const (
+	// defaultSuggestLimit - сколько подсказок отдаёт автодополнение, если клиент не передал Limit
+	defaultSuggestLimit = 10
+	// maxSuggestLimit - больше этого количества подсказок за один запрос не отдаём
+	maxSuggestLimit = 50
+)
+
+// queryName достаёт из запроса название навыка или синонима из параметра Name. Если оно неверное - отвечает 400
+func queryName(ctx *gin.Context) (string, bool) {
+	name := skill.Clean(ctx.Query("Name"))
+	if err := skill.CheckName(name); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "Ошибка в названии (Name)! Перепроверьте его и попробуйте снова",
+			"Error":  err.Error(),
+		})
+		return "", false
+	}
+	return name, true
+}
+
+// storageError отвечает на ошибку из хранилища: 404, если навыка нет, 400, если название занято, иначе 500
+func storageError(ctx *gin.Context, err error) {
+	switch err {
+	case sql.ErrNoRows:
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"Status": "Err",
+			"Info":   "Такой записи нету в системе! Перепроверьте данные и попробуйте снова",
+		})
+	case sqlp.ErrSkillNameTaken:
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "Это название уже занято! Используйте другое или объедините навыки",
+			"Error":  err.Error(),
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"Status": "Err",
+			"Info":   "Ошибка в SQL файле",
+			"Error":  err.Error(),
+		})
+	}
+}
+
+// @Summary Список навыков
+// @Description Возвращает навыки из общего справочника вакансий и резюме вместе с синонимами и количеством вакансий и резюме, в которых они указаны. Выдача идёт по курсору: Cursor - NextCursor из предыдущего ответа, Limit - сколько навыков вернуть. Имееют доступ все.
+// @Tags Skill
+// @Produce json
+// @Param Cursor query string false "NextCursor из предыдущего ответа. Пустой - первая страница"
+// @Param Limit query int false "Сколько навыков вернуть (по умолчанию 20, не больше 100)"
+// @Success 200 {object} s.ResponseSkills "Возвращает массив навыков и курсор на следующую страницу"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если курсор или Limit неверные"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /skills [get]
+func GetSkills(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		after, limit, err := page.FromQuery(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в параметрах Cursor или Limit! Перепроверьте их и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, err := sqlp.GetSkills(tx, after.ID, limit+1)
+		if err != nil {
+			storageError(ctx, err)
+			return
+		}
+
+		data, next := page.Cut(data, limit, func(sk s.Skill) s.PageCursor { return s.PageCursor{ID: sk.ID} })
+		ctx.JSON(200, gin.H{
+			"Status":     "Ok!",
+			"Data":       data,
+			"NextCursor": next,
+		})
+	}
+}
+
+// @Summary Автодополнение навыков
+// @Description Возвращает навыки, название или синоним которых начинается с Text (без учёта регистра). Сначала идёт точное совпадение, затем навыки, которые чаще указывают в вакансиях и резюме. Если совпал синоним, то он возвращается в поле Synonym, а в Name - основной навык. Имееют доступ все.
+// @Tags Skill
+// @Produce json
+// @Param Text query string true "Начало названия навыка"
+// @Param Limit query int false "Сколько подсказок вернуть (по умолчанию 10, не больше 50)"
+// @Success 200 {object} s.ResponseSkillSuggestions "Возвращает массив подсказок"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если Text не передан или Limit неверный"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /skills/suggest [get]
+func SuggestSkills(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		text := skill.Clean(ctx.Query("Text"))
+		if text == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Передайте начало названия навыка в параметре Text",
+			})
+			return
+		}
+		limit := defaultSuggestLimit
+		if value := ctx.Query("Limit"); value != "" {
+			var err error
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit <= 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{
+					"Status": "Err",
+					"Info":   "Неверное значение Limit! Перепроверьте его и попробуйте снова",
+				})
+				return
+			}
+			limit = min(limit, maxSuggestLimit)
+		}
+		data, err := sqlp.SuggestSkills(tx, text, limit)
+		if err != nil {
+			storageError(ctx, err)
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+			"Data":   data,
+		})
+	}
+}
+
+// @Summary Добавить навык
+// @Description Добавляет навык в общий справочник вакансий и резюме. Название не должно совпадать с другим навыком или синонимом без учёта регистра. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Skill
+// @Produce json
+// @Param Name query string true "Название навыка"
+// @Success 200 {object} s.Ok "Возвращает статус 'Ok!' и ID навыка"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если название неверное или уже занято"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/skills [post]
+func PostSkill(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		if !get.HasRole(ctx, "ADMIN") {
+			return
+		}
+		name, ok := queryName(ctx)
+		if !ok {
+			return
+		}
+		id, err := sqlp.PostSkill(tx, name)
+		if err != nil {
+			storageError(ctx, err)
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":  "Ok!",
+			"SkillID": id,
+		})
+	}
+}
+
+// @Summary Переименовать навык
+// @Description Меняет название навыка. В вакансиях и резюме навык сразу отображается с новым названием. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Skill
+// @Produce json
+// @Param SkillID query int true "ID навыка"
+// @Param Name query string true "Новое название навыка"
+// @Success 200 {object} s.Ok "Возвращает статус 'Ok!'"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если название неверное или уже занято"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если навыка нет"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/skills [patch]
+func RenameSkill(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		if !get.HasRole(ctx, "ADMIN") {
+			return
+		}
+		id, ok := get.QueryID(ctx, "SkillID")
+		if !ok {
+			return
+		}
+		name, ok := queryName(ctx)
+		if !ok {
+			return
+		}
+		if err := sqlp.RenameSkill(tx, id, name); err != nil {
+			storageError(ctx, err)
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+		})
+	}
+}
+
+// @Summary Удалить навык
+// @Description Удаляет навык вместе с его синонимами. Из вакансий и резюме навык пропадает. Если навык дублирует другой, то лучше объединить их через /adm/skills/merge. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Skill
+// @Produce json
+// @Param SkillID query int true "ID навыка"
+// @Success 200 {object} s.Ok "Возвращает статус 'Ok!'"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если ID не передан"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если навыка нет"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/skills [delete]
+func DeleteSkill(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		if !get.HasRole(ctx, "ADMIN") {
+			return
+		}
+		id, ok := get.QueryID(ctx, "SkillID")
+		if !ok {
+			return
+		}
+		if err := sqlp.DeleteSkill(tx, id); err != nil {
+			storageError(ctx, err)
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "OK!",
+			"Info":   "данные успешно удалены!",
+		})
+	}
+}
+
+// @Summary Добавить синоним навыка
+// @Description Добавляет навыку синоним, например "Golang" для "Go". Когда в вакансии или резюме указывают синоним, сохраняется основной навык. Синоним ищется автодополнением. Название не должно совпадать с другим навыком или синонимом без учёта регистра. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Skill
+// @Produce json
+// @Param SkillID query int true "ID основного навыка"
+// @Param Name query string true "Синоним"
+// @Success 200 {object} s.Ok "Возвращает статус 'Ok!' и ID синонима"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если название неверное или уже занято"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если навыка нет"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/skills/synonym [post]
+func PostSkillSynonym(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		if !get.HasRole(ctx, "ADMIN") {
+			return
+		}
+		skillID, ok := get.QueryID(ctx, "SkillID")
+		if !ok {
+			return
+		}
+		name, ok := queryName(ctx)
+		if !ok {
+			return
+		}
+		if _, err := sqlp.GetSkill(tx, skillID); err != nil {
+			storageError(ctx, err)
+			return
+		}
+		id, err := sqlp.PostSkillSynonym(tx, skillID, name)
+		if err != nil {
+			storageError(ctx, err)
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":    "Ok!",
+			"SynonymID": id,
+		})
+	}
+}
+
+// @Summary Удалить синоним навыка
+// @Description Удаляет синоним. Вакансии и резюме, в которых он был указан, сохраняют основной навык. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Skill
+// @Produce json
+// @Param SynonymID query int true "ID синонима"
+// @Success 200 {object} s.Ok "Возвращает статус 'Ok!'"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если ID не передан"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если синонима нет"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/skills/synonym [delete]
+func DeleteSkillSynonym(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		if !get.HasRole(ctx, "ADMIN") {
+			return
+		}
+		id, ok := get.QueryID(ctx, "SynonymID")
+		if !ok {
+			return
+		}
+		if err := sqlp.DeleteSkillSynonym(tx, id); err != nil {
+			storageError(ctx, err)
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "OK!",
+			"Info":   "данные успешно удалены!",
+		})
+	}
+}
+
+// @Summary Объединить навыки
+// @Description Объединяет навык FromID с навыком ToID, например "Golang" с "Go": вакансии и резюме с FromID получают навык ToID, синонимы FromID переходят к ToID, а название FromID становится ещё одним синонимом ToID. Навык FromID удаляется. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Skill
+// @Produce json
+// @Param FromID query int true "ID навыка, который нужно влить в другой"
+// @Param ToID query int true "ID навыка, который останется"
+// @Success 200 {object} s.ResponseSkill "Возвращает статус 'Ok!' и итоговый навык"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если ID не переданы или совпадают"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если какого-то из навыков нет"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/skills/merge [post]
+func MergeSkills(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		if !get.HasRole(ctx, "ADMIN") {
+			return
+		}
+		fromID, ok := get.QueryID(ctx, "FromID")
+		if !ok {
+			return
+		}
+		toID, ok := get.QueryID(ctx, "ToID")
+		if !ok {
+			return
+		}
+		if fromID == toID {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Нельзя объединить навык с самим собой",
+			})
+			return
+		}
+		if _, err := sqlp.GetSkill(tx, toID); err != nil {
+			storageError(ctx, err)
+			return
+		}
+		if err := sqlp.MergeSkills(tx, fromID, toID); err != nil {
+			storageError(ctx, err)
+			return
+		}
+		data, err := sqlp.GetSkill(tx, toID)
+		if err != nil {
+			storageError(ctx, err)
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+			"Data":   data,
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package stream
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"main.go/internal/api/get"
+	"main.go/internal/events"
+)
+
+// heartbeat - как часто отправлять пустое событие, чтобы прокси и балансировщики не закрывали соединение по простою
+const heartbeat = 25 * time.Second
+
+// @Summary Поток событий (Server-Sent Events)
+// @Description Держит открытое соединение и присылает события текущего пользователя по мере их появления: response_created, response_status_changed, vacancy_state_changed, vacancy_deleted и notification. Имя SSE события - тип, data - JSON с данными события. Раз в 25 секунд приходит событие ping. Так как EventSource в браузере не умеет передавать заголовки, токен можно передать в параметре Token
+// @Security ApiKeyAuth
+// @Tags Events
+// @Produce text/event-stream
+// @Param Token query string false "JWT токен, если нельзя передать заголовок Authorization"
+// @Success 200 {string} string "Поток событий"
+// @Failure 400 {string} string "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {string} string "Возвращает ошибку, если токена нет или он невалидный"
+// @Router /events [get]
+func Events() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		uid, ok := get.GetUserIDFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить ID пользователя из заголовка токена",
+			})
+			return
+		}
+
+		ch, unsubscribe := events.Subscribe(role, uid)
+		defer unsubscribe()
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		ctx.Header("Cache-Control", "no-cache")
+		ctx.Header("Connection", "keep-alive")
+		// nginx по умолчанию буферизует ответ, из-за чего события приходили бы пачками
+		ctx.Header("X-Accel-Buffering", "no")
+		ctx.SSEvent("ready", gin.H{"Role": role, "ID": uid})
+		ctx.Writer.Flush()
+
+		ctx.Stream(func(w io.Writer) bool {
+			select {
+			case <-ctx.Request.Context().Done():
+				return false
+			case event := <-ch:
+				ctx.SSEvent(event.Type, event.Data)
+			case <-ticker.C:
+				ctx.SSEvent("ping", time.Now().Unix())
+			}
+			return true
+		})
+	}
+}
+
+// TokenFromQuery переносит токен из параметра Token в заголовок Authorization, если заголовка нет.
+// Нужен для EventSource, который не умеет отправлять заголовки. Ставится перед AuthMiddleWare
+func TokenFromQuery() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetHeader("Authorization") == "" {
+			if token := ctx.Query("Token"); token != "" {
+				ctx.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,94 @@
+package candid
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	s "main.go/internal/api/Struct"
+	"main.go/internal/api/get"
+	"main.go/internal/api/page"
+	"main.go/internal/recommend"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+// @Summary Рекомендованные вакансии
+// @Description Возвращает видимые вакансии, подобранные для соискателя, по убыванию оценки Score (от 0 до 100). Оценка складывается из совпадения требуемого опыта с опытом в резюме, навыков из резюме, зарплаты с ожиданиями (из резюме, а если там не указана - из сохранённых поисков), города из резюме или откликов и сходства с вакансиями, на которые соискатель откликался. В Reasons перечислено, почему вакансия подошла. Вакансии, на которые соискатель уже откликнулся, не возвращаются. При равной оценке сначала идут новые вакансии. Выдача идёт по курсору: Cursor - NextCursor из предыдущего ответа, Limit - сколько вакансий вернуть. Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Produce json
+// @Param Cursor query string false "NextCursor из предыдущего ответа. Пустой - первая страница"
+// @Param Limit query int false "Сколько вакансий вернуть (по умолчанию 20, не больше 100)"
+// @Success 200 {object} s.ResponseRecommendations "Возвращает статус 'Ok!', вакансии с оценкой и причинами и курсор на следующую страницу"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен, курсор или Limit)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/recommendations [get]
+func GetRecommendations(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "candidate" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		uid, ok := get.GetUserIDFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить ID пользователя из заголовка токена",
+			})
+			return
+		}
+		after, limit, err := page.FromQuery(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в параметрах Cursor или Limit! Перепроверьте их и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+
+		profile, err := sqlp.GetRecommendationProfile(tx, uid)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, err := sqlp.GetRecommendedVacancies(tx, uid, profile, after, limit+1)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+
+		data, next := page.Cut(data, limit, func(v s.VacancyRecommendation) s.PageCursor {
+			return s.PageCursor{ID: v.ID, Rank: float64(v.Score)}
+		})
+		for i := range data {
+			data[i].Reasons = recommend.Reasons(data[i], profile)
+		}
+		ctx.JSON(200, gin.H{
+			"Status":      "Ok!",
+			"VacancyInfo": data,
+			"NextCursor":  next,
+		})
+	}
+}
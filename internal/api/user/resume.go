@@ -0,0 +1,168 @@
+package candid
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	s "main.go/internal/api/Struct"
+	"main.go/internal/api/get"
+	"main.go/internal/salary"
+	"main.go/internal/skill"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+// checkResumeDetails проверяет пожелания из резюме и подставляет валюту по умолчанию. При ошибке отвечает 400 и возвращает false
+func checkResumeDetails(ctx *gin.Context, tx *sqlx.Tx, details *s.ResumeDetails) bool {
+	if details.Title != nil {
+		title := strings.Join(strings.Fields(*details.Title), " ")
+		details.Title = &title
+		if title == "" {
+			details.Title = nil
+		} else if utf8.RuneCountInString(title) > maxFieldLength {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   fmt.Sprintf("Желаемая должность (Title) должна быть не длиннее %d символов", maxFieldLength),
+			})
+			return false
+		}
+	}
+	if details.City != nil {
+		city := strings.TrimSpace(*details.City)
+		details.City = &city
+		if city == "" {
+			details.City = nil
+		}
+	}
+	if details.SalaryExpectation != nil && *details.SalaryExpectation <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "Ожидаемая зарплата должна быть больше нуля",
+		})
+		return false
+	}
+	details.SalaryCurrency = strings.ToUpper(strings.TrimSpace(details.SalaryCurrency))
+	if details.SalaryCurrency == "" {
+		details.SalaryCurrency = salary.BaseCurrency
+	}
+	if _, err := sqlp.GetCurrency(tx, details.SalaryCurrency); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "Такой валюты нету в системе! Список доступных валют можно получить в /currency",
+			"Error":  err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// checkResumeSkills очищает список навыков резюме, если он передан, и проверяет, что все навыки есть в справочнике.
+// При ошибке отвечает 400 (500 при ошибке базы) и возвращает false
+func checkResumeSkills(ctx *gin.Context, tx *sqlx.Tx, names *[]string) bool {
+	if names == nil {
+		return true
+	}
+	skills, err := skill.Normalize(*names)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "Ошибка в списке навыков резюме! Перепроверьте данные и попробуйте снова",
+			"Error":  err.Error(),
+		})
+		return false
+	}
+	unknown, err := sqlp.UnknownSkills(tx, skills)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"Status": "Err",
+			"Info":   "Ошибка в SQL файле",
+			"Error":  err.Error(),
+		})
+		return false
+	}
+	if len(unknown) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   fmt.Sprintf("Навыков %s нету в системе! Выберите навыки из подсказок /skills/suggest", strings.Join(unknown, ", ")),
+			"Error":  sqlp.ErrUnknownSkill.Error(),
+		})
+		return false
+	}
+	*names = skills
+	return true
+}
+
+// @Summary Изменить видимость резюме
+// @Description Позволяет соискателю выбрать, кто из работодателей видит резюме в поиске (/emp/resume/search): public - все работодатели, responded - только те, на чьи вакансии соискатель откликался (по умолчанию), hidden - никто. Почта и телефон в любом случае видны только работодателям, на вакансии которых соискатель откликался или чей запрос контактов он принял. Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Produce json
+// @Param ResumeID query int true "ID резюме"
+// @Param Visibility query string true "public, responded или hidden"
+// @Success 200 {object} s.Ok "Возвращает статус 'Ok!'"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если параметры неверные или резюме не найдено"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/resume/visibility [patch]
+func PatchResumeVisibility(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "candidate" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		uid, ok := get.GetUserIDFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить ID пользователя из заголовка токена",
+			})
+			return
+		}
+		resumeID, err := strconv.Atoi(ctx.Query("ResumeID"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка при попытке получить ID резюме! проверьте его и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		visibility := ctx.Query("Visibility")
+		switch visibility {
+		case "public", "responded", "hidden":
+		default:
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Visibility может быть только public, responded или hidden",
+			})
+			return
+		}
+		if err := sqlp.SetResumeVisibility(tx, resumeID, uid, visibility); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при изменении видимости резюме",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+		})
+	}
+}
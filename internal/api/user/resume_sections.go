@@ -0,0 +1,586 @@
+package candid
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	s "main.go/internal/api/Struct"
+	"main.go/internal/api/get"
+	"main.go/internal/skill"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+const (
+	// maxSectionItems - сколько записей можно добавить в один раздел резюме (места работы, образование, языки)
+	maxSectionItems = 30
+	// maxFieldLength - максимальная длина короткого текстового поля раздела в символах
+	maxFieldLength = 200
+	// maxAchievementsLength - максимальная длина описания достижений на месте работы в символах
+	maxAchievementsLength = 3000
+	// minGraduationYear - самый ранний допустимый год окончания учебного заведения
+	minGraduationYear = 1950
+)
+
+// languageLevels - допустимые уровни владения языком
+var languageLevels = map[string]bool{"A1": true, "A2": true, "B1": true, "B2": true, "C1": true, "C2": true, "native": true}
+
+// bindSection читает тело запроса раздела резюме и проверяет его функцией check. При ошибке отвечает 400
+func bindSection[T any](ctx *gin.Context, req *T, check func(*T) error) bool {
+	if err := ctx.ShouldBindBodyWithJSON(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "ошибка при чтении данных! Перепроверьте данные и попробуйте снова",
+			"Error":  err.Error(),
+		})
+		return false
+	}
+	if err := check(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "Ошибка в данных раздела резюме! Перепроверьте их и попробуйте снова",
+			"Error":  err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// ownResume проверяет, что резюме принадлежит соискателю. Если нет - отвечает 404
+func ownResume(ctx *gin.Context, tx *sqlx.Tx, resumeID, uid int) bool {
+	owned, err := sqlp.ResumeOwnedBy(tx, resumeID, uid)
+	if err != nil {
+		sectionError(ctx, err)
+		return false
+	}
+	if !owned {
+		sectionError(ctx, sql.ErrNoRows)
+		return false
+	}
+	return true
+}
+
+// hasRoom проверяет, что в разделе table резюме есть место ещё для одной записи. Если нет - отвечает 400
+func hasRoom(ctx *gin.Context, tx *sqlx.Tx, table string, resumeID, limit int) bool {
+	amount, err := sqlp.CountResumeItems(tx, table, resumeID)
+	if err != nil {
+		sectionError(ctx, err)
+		return false
+	}
+	if amount >= limit {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   fmt.Sprintf("В разделе резюме может быть не больше %d записей", limit),
+		})
+		return false
+	}
+	return true
+}
+
+// sectionError отвечает на ошибку из хранилища: 404, если записи или резюме нет, 400, если запись повторяется, иначе 500
+func sectionError(ctx *gin.Context, err error) {
+	switch err {
+	case sql.ErrNoRows:
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"Status": "Err",
+			"Info":   "Такой записи у ваших резюме нету! Перепроверьте данные и попробуйте снова",
+		})
+	case sqlp.ErrResumeLanguageExists, sqlp.ErrResumeSkillExists, sqlp.ErrResumeOrderMismatch, sqlp.ErrUnknownSkill:
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "Ошибка в данных раздела резюме! Перепроверьте их и попробуйте снова",
+			"Error":  err.Error(),
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"Status": "Err",
+			"Info":   "Ошибка в SQL файле",
+			"Error":  err.Error(),
+		})
+	}
+}
+
+// checkText убирает пробелы по краям поля и проверяет его длину. Обязательное поле не может быть пустым
+func checkText(value *string, field string, required bool, limit int) error {
+	*value = strings.TrimSpace(*value)
+	if required && *value == "" {
+		return fmt.Errorf("поле %s не может быть пустым", field)
+	}
+	if utf8.RuneCountInString(*value) > limit {
+		return fmt.Errorf("поле %s должно быть не длиннее %d символов", field, limit)
+	}
+	return nil
+}
+
+func checkWork(req *s.RequestResumeWork) error {
+	if err := checkText(&req.Company, "Company", true, maxFieldLength); err != nil {
+		return err
+	}
+	if err := checkText(&req.Position, "Position", true, maxFieldLength); err != nil {
+		return err
+	}
+	if err := checkText(&req.Achievements, "Achievements", false, maxAchievementsLength); err != nil {
+		return err
+	}
+	started, err := time.Parse(time.DateOnly, strings.TrimSpace(req.StartedAt))
+	if err != nil {
+		return fmt.Errorf("StartedAt должна быть датой в формате YYYY-MM-DD")
+	}
+	if started.After(time.Now()) {
+		return fmt.Errorf("StartedAt не может быть в будущем")
+	}
+	req.StartedAt = started.Format(time.DateOnly)
+	if req.EndedAt == nil || strings.TrimSpace(*req.EndedAt) == "" {
+		req.EndedAt = nil
+		return nil
+	}
+	ended, err := time.Parse(time.DateOnly, strings.TrimSpace(*req.EndedAt))
+	if err != nil {
+		return fmt.Errorf("EndedAt должна быть датой в формате YYYY-MM-DD")
+	}
+	if ended.Before(started) {
+		return fmt.Errorf("EndedAt не может быть раньше StartedAt")
+	}
+	endedAt := ended.Format(time.DateOnly)
+	req.EndedAt = &endedAt
+	return nil
+}
+
+func checkEducation(req *s.RequestResumeEducation) error {
+	if err := checkText(&req.Institution, "Institution", true, maxFieldLength); err != nil {
+		return err
+	}
+	if err := checkText(&req.Faculty, "Faculty", false, maxFieldLength); err != nil {
+		return err
+	}
+	if err := checkText(&req.Specialization, "Specialization", false, maxFieldLength); err != nil {
+		return err
+	}
+	if err := checkText(&req.Degree, "Degree", false, maxFieldLength); err != nil {
+		return err
+	}
+	// год окончания может быть ожидаемым, поэтому допускаем несколько лет вперёд
+	if req.GraduationYear != nil && (*req.GraduationYear < minGraduationYear || *req.GraduationYear > time.Now().Year()+10) {
+		return fmt.Errorf("GraduationYear должен быть между %d и %d", minGraduationYear, time.Now().Year()+10)
+	}
+	return nil
+}
+
+func checkLanguage(req *s.RequestResumeLanguage) error {
+	req.Language = strings.Join(strings.Fields(req.Language), " ")
+	if err := checkText(&req.Language, "Language", true, skill.MaxLength); err != nil {
+		return err
+	}
+	if req.Level = strings.TrimSpace(req.Level); req.Level != "native" {
+		req.Level = strings.ToUpper(req.Level)
+	}
+	if !languageLevels[req.Level] {
+		return fmt.Errorf("Level может быть только A1, A2, B1, B2, C1, C2 или native")
+	}
+	return nil
+}
+
+// @Summary Добавить место работы в резюме
+// @Description Добавляет место работы в конец раздела опыта работы резюме. Даты передаются в формате YYYY-MM-DD, пустой EndedAt - соискатель работает там до сих пор. Порядок записей меняется через /user/resume/work/order. Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Accept json
+// @Produce json
+// @Param request body s.RequestResumeWork true "Место работы"
+// @Success 200 {object} s.Ok "Возвращает статус 'Ok!' и ID записи (EntryID)"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если данные неверные или записей в разделе слишком много"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если резюме не найдено"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/resume/work [post]
+func PostResumeWork(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		uid, ok := get.UserIDWithRole(ctx, "candidate")
+		if !ok {
+			return
+		}
+		var req s.RequestResumeWork
+		if !bindSection(ctx, &req, checkWork) || !ownResume(ctx, tx, req.ResumeID, uid) || !hasRoom(ctx, tx, "resume_work", req.ResumeID, maxSectionItems) {
+			return
+		}
+		id, err := sqlp.AddResumeWork(tx, req)
+		if err != nil {
+			sectionError(ctx, err)
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":  "Ok!",
+			"EntryID": id,
+		})
+	}
+}
+
+// @Summary Изменить место работы в резюме
+// @Description Заменяет данные места работы. ResumeID в теле запроса не учитывается - запись остаётся в своём резюме. Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Accept json
+// @Produce json
+// @Param EntryID query int true "ID места работы"
+// @Param request body s.RequestResumeWork true "Место работы"
+// @Success 200 {object} s.Ok "Возвращает статус 'Ok!'"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если данные неверные"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если записи нет в резюме соискателя"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/resume/work [put]
+func PutResumeWork(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		uid, ok := get.UserIDWithRole(ctx, "candidate")
+		if !ok {
+			return
+		}
+		id, ok := get.QueryID(ctx, "EntryID")
+		if !ok {
+			return
+		}
+		var req s.RequestResumeWork
+		if !bindSection(ctx, &req, checkWork) {
+			return
+		}
+		if err := sqlp.UpdateResumeWork(tx, id, uid, req); err != nil {
+			sectionError(ctx, err)
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+		})
+	}
+}
+
+// @Summary Добавить образование в резюме
+// @Description Добавляет учебное заведение в конец раздела образования резюме. Обязательно только Institution, GraduationYear может быть ожидаемым годом окончания. Порядок записей меняется через /user/resume/education/order. Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Accept json
+// @Produce json
+// @Param request body s.RequestResumeEducation true "Образование"
+// @Success 200 {object} s.Ok "Возвращает статус 'Ok!' и ID записи (EntryID)"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если данные неверные или записей в разделе слишком много"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если резюме не найдено"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/resume/education [post]
+func PostResumeEducation(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		uid, ok := get.UserIDWithRole(ctx, "candidate")
+		if !ok {
+			return
+		}
+		var req s.RequestResumeEducation
+		if !bindSection(ctx, &req, checkEducation) || !ownResume(ctx, tx, req.ResumeID, uid) || !hasRoom(ctx, tx, "resume_education", req.ResumeID, maxSectionItems) {
+			return
+		}
+		id, err := sqlp.AddResumeEducation(tx, req)
+		if err != nil {
+			sectionError(ctx, err)
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":  "Ok!",
+			"EntryID": id,
+		})
+	}
+}
+
+// @Summary Изменить образование в резюме
+// @Description Заменяет данные об учебном заведении. ResumeID в теле запроса не учитывается - запись остаётся в своём резюме. Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Accept json
+// @Produce json
+// @Param EntryID query int true "ID записи об образовании"
+// @Param request body s.RequestResumeEducation true "Образование"
+// @Success 200 {object} s.Ok "Возвращает статус 'Ok!'"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если данные неверные"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если записи нет в резюме соискателя"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/resume/education [put]
+func PutResumeEducation(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		uid, ok := get.UserIDWithRole(ctx, "candidate")
+		if !ok {
+			return
+		}
+		id, ok := get.QueryID(ctx, "EntryID")
+		if !ok {
+			return
+		}
+		var req s.RequestResumeEducation
+		if !bindSection(ctx, &req, checkEducation) {
+			return
+		}
+		if err := sqlp.UpdateResumeEducation(tx, id, uid, req); err != nil {
+			sectionError(ctx, err)
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+		})
+	}
+}
+
+// @Summary Добавить язык в резюме
+// @Description Добавляет язык в конец раздела языков резюме. Level - A1, A2, B1, B2, C1, C2 или native. Один язык можно указать в резюме только один раз (без учёта регистра). Порядок записей меняется через /user/resume/language/order. Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Accept json
+// @Produce json
+// @Param request body s.RequestResumeLanguage true "Язык и уровень"
+// @Success 200 {object} s.Ok "Возвращает статус 'Ok!' и ID записи (EntryID)"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если данные неверные, язык уже указан или записей в разделе слишком много"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если резюме не найдено"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/resume/language [post]
+func PostResumeLanguage(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		uid, ok := get.UserIDWithRole(ctx, "candidate")
+		if !ok {
+			return
+		}
+		var req s.RequestResumeLanguage
+		if !bindSection(ctx, &req, checkLanguage) || !ownResume(ctx, tx, req.ResumeID, uid) || !hasRoom(ctx, tx, "resume_languages", req.ResumeID, maxSectionItems) {
+			return
+		}
+		id, err := sqlp.AddResumeLanguage(tx, req)
+		if err != nil {
+			sectionError(ctx, err)
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":  "Ok!",
+			"EntryID": id,
+		})
+	}
+}
+
+// @Summary Изменить язык в резюме
+// @Description Заменяет язык или уровень владения им. ResumeID в теле запроса не учитывается - запись остаётся в своём резюме. Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Accept json
+// @Produce json
+// @Param EntryID query int true "ID записи о языке"
+// @Param request body s.RequestResumeLanguage true "Язык и уровень"
+// @Success 200 {object} s.Ok "Возвращает статус 'Ok!'"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если данные неверные или язык уже указан в другой записи"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если записи нет в резюме соискателя"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/resume/language [put]
+func PutResumeLanguage(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		uid, ok := get.UserIDWithRole(ctx, "candidate")
+		if !ok {
+			return
+		}
+		id, ok := get.QueryID(ctx, "EntryID")
+		if !ok {
+			return
+		}
+		var req s.RequestResumeLanguage
+		if !bindSection(ctx, &req, checkLanguage) {
+			return
+		}
+		if err := sqlp.UpdateResumeLanguage(tx, id, uid, req); err != nil {
+			sectionError(ctx, err)
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+		})
+	}
+}
+
+// @Summary Удалить запись из раздела резюме
+// @Description Удаляет место работы, образование или язык из резюме соискателя. Порядок остальных записей не меняется. Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Produce json
+// @Param EntryID query int true "ID записи"
+// @Success 200 {object} s.Ok "Возвращает статус 'OK!'"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если ID не передан"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если записи нет в резюме соискателя"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/resume/work [delete]
+// @Router /user/resume/education [delete]
+// @Router /user/resume/language [delete]
+func DeleteResumeItem(storage *sqlx.DB, table string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		uid, ok := get.UserIDWithRole(ctx, "candidate")
+		if !ok {
+			return
+		}
+		id, ok := get.QueryID(ctx, "EntryID")
+		if !ok {
+			return
+		}
+		if err := sqlp.DeleteResumeItem(tx, table, id, uid); err != nil {
+			sectionError(ctx, err)
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "OK!",
+			"Info":   "данные успешно удалены!",
+		})
+	}
+}
+
+// @Summary Изменить порядок записей в разделе резюме
+// @Description Расставляет записи раздела резюме в переданном порядке. В IDs нужно передать все ID записей раздела ровно по одному разу (для навыков - ID навыков). Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Accept json
+// @Produce json
+// @Param request body s.RequestResumeOrder true "ID резюме и ID записей в нужном порядке"
+// @Success 200 {object} s.Ok "Возвращает статус 'Ok!'"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если список ID не совпадает с записями раздела"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если резюме не найдено"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/resume/work/order [put]
+// @Router /user/resume/education/order [put]
+// @Router /user/resume/language/order [put]
+// @Router /user/resume/skill/order [put]
+func PutResumeOrder(storage *sqlx.DB, table, column string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		uid, ok := get.UserIDWithRole(ctx, "candidate")
+		if !ok {
+			return
+		}
+		var req s.RequestResumeOrder
+		if !bindSection(ctx, &req, checkOrder) || !ownResume(ctx, tx, req.ResumeID, uid) {
+			return
+		}
+		if err := sqlp.ReorderResumeItems(tx, table, column, req.ResumeID, req.IDs); err != nil {
+			sectionError(ctx, err)
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+		})
+	}
+}
+
+// checkOrder проверяет, что ID в новом порядке не повторяются
+func checkOrder(req *s.RequestResumeOrder) error {
+	seen := make(map[int]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if seen[id] {
+			return fmt.Errorf("ID %d указан в IDs несколько раз", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// @Summary Добавить навык в резюме
+// @Description Добавляет навык в конец списка навыков резюме. Навык должен быть в общем справочнике (/skills): синоним заменяется основным навыком, неизвестное название отклоняется. Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Produce json
+// @Param ResumeID query int true "ID резюме"
+// @Param Name query string true "Название навыка"
+// @Success 200 {object} s.Ok "Возвращает статус 'Ok!' и ID навыка (SkillID)"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если название неверное, такого навыка нет в справочнике, навык уже указан или навыков слишком много"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если резюме не найдено"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/resume/skill [post]
+func PostResumeSkill(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		uid, ok := get.UserIDWithRole(ctx, "candidate")
+		if !ok {
+			return
+		}
+		resumeID, ok := get.QueryID(ctx, "ResumeID")
+		if !ok {
+			return
+		}
+		name := skill.Clean(ctx.Query("Name"))
+		if err := skill.CheckName(name); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в названии навыка (Name)! Перепроверьте его и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		if !ownResume(ctx, tx, resumeID, uid) || !hasRoom(ctx, tx, "resume_skills", resumeID, skill.MaxPerItem) {
+			return
+		}
+		skillID, err := sqlp.AddResumeSkill(tx, resumeID, name)
+		if err != nil {
+			sectionError(ctx, err)
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":  "Ok!",
+			"SkillID": skillID,
+		})
+	}
+}
+
+// @Summary Удалить навык из резюме
+// @Description Убирает навык из резюме. Сам навык остаётся в общем справочнике. Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Produce json
+// @Param ResumeID query int true "ID резюме"
+// @Param SkillID query int true "ID навыка"
+// @Success 200 {object} s.Ok "Возвращает статус 'OK!'"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если ID не переданы"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если резюме не найдено или навыка в нём нет"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/resume/skill [delete]
+func DeleteResumeSkill(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		uid, ok := get.UserIDWithRole(ctx, "candidate")
+		if !ok {
+			return
+		}
+		resumeID, ok := get.QueryID(ctx, "ResumeID")
+		if !ok {
+			return
+		}
+		skillID, ok := get.QueryID(ctx, "SkillID")
+		if !ok {
+			return
+		}
+		if !ownResume(ctx, tx, resumeID, uid) {
+			return
+		}
+		if err := sqlp.DeleteResumeSkill(tx, resumeID, skillID); err != nil {
+			sectionError(ctx, err)
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "OK!",
+			"Info":   "данные успешно удалены!",
+		})
+	}
+}
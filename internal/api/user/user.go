@@ -2,6 +2,7 @@ package candid
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -12,8 +13,11 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/jmoiron/sqlx"
 	s "main.go/internal/api/Struct"
+	"main.go/internal/api/etag"
 	"main.go/internal/api/get"
+	"main.go/internal/api/page"
 	mailer "main.go/internal/email-sender"
+	"main.go/internal/notify"
 	sqlp "main.go/internal/storage/postSQL"
 	"main.go/internal/utils"
 )
@@ -80,7 +84,9 @@ func DeleteUser() gin.HandlerFunc {
 // @Tags Candidate
 // @Accept json
 // @Produce json
-// @Success 200 {object} s.ResponsesByVac "Возвращает ID отклика, данные об этой вакансии, на которую откликнулся пользователь и статус отклика "
+// @Param Cursor query string false "Курсор следующей страницы (NextCursor из предыдущего ответа). Если не передан - отдаётся первая страница"
+// @Param Limit query int false "Кол-во записей на странице. По умолчанию 20, максимум 100"
+// @Success 200 {object} s.ResponsesByVac "Возвращает ID отклика, данные об этой вакансии, на которую откликнулся пользователь и статус отклика, а также курсор следующей страницы (пустой, если страница последняя)"
 // @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
 // @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
 // @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
@@ -111,7 +117,16 @@ func GetAllUserResponse(storage *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
-		data, err := sqlp.GetResponseByCandidate(tx, uid)
+		after, limit, err := page.FromQuery(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при попытке получить параметры страницы (Cursor, Limit)! проверьте их и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, err := sqlp.GetResponseByCandidate(tx, uid, after.ID, limit+1)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"Status": "Err",
@@ -120,9 +135,11 @@ func GetAllUserResponse(storage *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		data, next := page.Cut(data, limit, func(r s.ResponseByVac) s.PageCursor { return s.PageCursor{ID: r.ID} })
 		ctx.JSON(200, gin.H{
-			"Status":    "Ok!",
-			"Responses": data,
+			"Status":     "Ok!",
+			"Responses":  data,
+			"NextCursor": next,
 		})
 
 	}
@@ -130,14 +147,18 @@ func GetAllUserResponse(storage *sqlx.DB) gin.HandlerFunc {
 
 // @Summary Обновить данные об резюме соискателя
 // @Description Позволяет обновить данные, которые касаются только резюме соискателя. Доступ имеют роли Candidate и ADMIN
+// @Description Skills заменяет весь список навыков в переданном порядке, а если Skills не передан, то навыки не меняются. Опыт работы, образование и языки этим запросом не меняются
 // @Security ApiKeyAuth
 // @Tags Candidate
 // @Accept json
 // @Produce json
-// @Param ResumeData body s.RequestResumeUpdate true "Данные, которые можно изменить. Это только опыт (стаж) и описание. НО также указываете ID резюме, которое необходимо изменить!"
+// @Param ResumeData body s.RequestResumeUpdate true "Данные, которые можно изменить. Это опыт (стаж), описание и навыки (список заменяется целиком, без Skills навыки остаются прежними). НО также указываете ID резюме, которое необходимо изменить!"
+// @Param If-Match header string false "Версия резюме (поле Version), полученная вместе с данными. Если резюме успело измениться, то вернётся 412"
 // @Success 200 {object} s.StatusInfo "Возвращает статус 'Ok!' и небольшую информацию"
 // @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
 // @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если резюме не было найдено"
+// @Failure 412 {object} s.InfoError "Возвращает ошибку, если резюме уже было изменено кем-то другим"
 // @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
 // @Router /user/resume [put]
 func PutCandidateResume(storag *sqlx.DB) gin.HandlerFunc {
@@ -175,6 +196,12 @@ func PutCandidateResume(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		if !checkResumeSkills(ctx, tx, req.Skills) {
+			return
+		}
+		if !checkResumeDetails(ctx, tx, &req.ResumeDetails) {
+			return
+		}
 		uid, ok := get.GetUserIDFromContext(ctx)
 		if !ok {
 			ctx.JSON(http.StatusBadRequest, gin.H{
@@ -183,8 +210,31 @@ func PutCandidateResume(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
-		err := sqlp.UpdateCandidateResume(tx, req, uid)
+		version, err := etag.FromIfMatch(ctx)
 		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в заголовке If-Match! Передайте в нём ETag, который был получен вместе с данными",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		newVersion, err := sqlp.UpdateCandidateResume(tx, req, uid, version)
+		if err == sqlp.ErrVersionMismatch {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{
+				"Status": "Err",
+				"Info":   "Данные резюме уже были изменены кем-то другим! Получите актуальные данные и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		} else if errors.Is(err, sqlp.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"Status": "Err",
+				"Info":   "Резюме не было найдено! Перепроверьте данные и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		} else if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"Status": "Err",
 				"Info":   "Ошибка в SQL файле для обновления данных резюме пользователя",
@@ -193,6 +243,7 @@ func PutCandidateResume(storag *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
+		etag.Set(ctx, newVersion)
 		ctx.JSON(200, gin.H{
 			"Status": "Ok!",
 			"Info":   "Данные успешно обновлены!",
@@ -328,7 +379,7 @@ func PatchVerifyStatus(storag *sqlx.DB) gin.HandlerFunc {
 // @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
 // @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
 // @Router /user [post]
-func PostNewCandidate(storag *sqlx.DB, mailer *mailer.Mailer) gin.HandlerFunc {
+func PostNewCandidate(storag *sqlx.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		tx := ctx.MustGet("tx").(*sqlx.Tx)
 		var req s.RequestCandidate
@@ -396,9 +447,18 @@ func PostNewCandidate(storag *sqlx.DB, mailer *mailer.Mailer) gin.HandlerFunc {
 			})
 			return
 		}
-		link := fmt.Sprint("https://isp-workall.online/api/v1/user/confirm-email?Token=")
-		textToSend := fmt.Sprintf("Здравствуйте, %s!\n\nБлагодарим вас за регистрацию на нашем сервисе!\n\nДля подтверждения почты, пожалуйста, перейдите по ссылке ниже:\n%s%s", data.Name, link, tokenVerify)
-		mailer.SendAsync(data.Email, "Подтверждения почты!", textToSend)
+		letter := mailer.ConfirmEmailData{
+			Name: data.Name,
+			Link: "https://isp-workall.online/api/v1/user/confirm-email?Token=" + tokenVerify,
+		}
+		if err := mailer.Enqueue(tx, data.Email, "confirm_email", mailer.LocaleFromHeader(ctx.GetHeader("Accept-Language")), letter); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при постановке письма для подтверждения почты в очередь на отправку",
+				"Error":  err.Error(),
+			})
+			return
+		}
 		token, err := sqlp.CreateAccessToken(claim)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
@@ -452,6 +512,7 @@ func CheckToken(storag *sqlx.DB) gin.HandlerFunc {
 // @Produce json
 // @Param CandidateID query int true "ID соискателя"
 // @Success 200 {object} s.GetAllFromCandidates "Возвращает статус 'Ok!' и данные пользователя"
+// @Header 200 {string} ETag "Версия данных, которую нужно передать в If-Match при обновлении"
 // @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
 // @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
 // @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
@@ -478,6 +539,7 @@ func GetCandidateInfo(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		etag.Set(ctx, data.Version)
 		// if candidId == uid || role == "ADMIN" {
 		// 	fmt.Println("Соискатель получил свои данные или админом")
 		// } else {
@@ -498,9 +560,12 @@ func GetCandidateInfo(storag *sqlx.DB) gin.HandlerFunc {
 // @Accept json
 // @Produce json
 // @Param CandidateInfo body s.RequestCandidate true "Данные о соискателе, на которые нужно обновить в системе"
+// @Param If-Match header string false "ETag, полученный вместе с данными соискателя. Если данные успели измениться, то вернётся 412"
 // @Success 200 {object} s.StatusInfo "Возвращает статус 'Ok!' и небольшую информацию"
 // @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
 // @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если соискатель не найден или удалён"
+// @Failure 412 {object} s.InfoError "Возвращает ошибку, если данные соискателя уже были изменены кем-то другим"
 // @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
 // @Router /user [put]
 func PutCandidateInfo(storag *sqlx.DB) gin.HandlerFunc {
@@ -566,8 +631,31 @@ func PutCandidateInfo(storag *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
-		err := sqlp.UpdateCandidateInfo(tx, req, uid)
+		version, err := etag.FromIfMatch(ctx)
 		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в заголовке If-Match! Передайте в нём ETag, который был получен вместе с данными",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		newVersion, err := sqlp.UpdateCandidateInfo(tx, req, uid, version)
+		if err == sqlp.ErrVersionMismatch {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{
+				"Status": "Err",
+				"Info":   "Данные соискателя уже были изменены кем-то другим! Получите актуальные данные и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		} else if errors.Is(err, sqlp.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"Status": "Err",
+				"Info":   "Соискатель не был найден или уже удалён!",
+				"Error":  err.Error(),
+			})
+			return
+		} else if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"Status": "Err",
 				"Info":   "Ошибка в SQL файле для обновления данных о соискателе",
@@ -576,6 +664,7 @@ func PutCandidateInfo(storag *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
+		etag.Set(ctx, newVersion)
 		ctx.JSON(200, gin.H{
 			"Status": "Ok!",
 			"Info":   "Данные успешно обновлены!",
@@ -590,7 +679,9 @@ func PutCandidateInfo(storag *sqlx.DB) gin.HandlerFunc {
 // @Security ApiKeyAuth
 // @Accept json
 // @Produce json
-// @Success 200 {object} s.InfoAboutAllCandidates "Возвращает статус 'Ok!' и массив всех данных о соискателях"
+// @Param Cursor query string false "Курсор следующей страницы (NextCursor из предыдущего ответа). Если не передан - отдаётся первая страница"
+// @Param Limit query int false "Кол-во записей на странице. По умолчанию 20, максимум 100"
+// @Success 200 {object} s.InfoAboutAllCandidates "Возвращает статус 'Ok!', массив данных о соискателях и курсор следующей страницы (пустой, если страница последняя)"
 // @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
 // @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
 // @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
@@ -613,7 +704,16 @@ func GetAllCandidates(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
-		data, err := sqlp.GetAllCandidates(tx)
+		after, limit, err := page.FromQuery(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при попытке получить параметры страницы (Cursor, Limit)! проверьте их и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, err := sqlp.GetAllCandidates(tx, after.ID, limit+1)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"Status": "Err",
@@ -623,20 +723,23 @@ func GetAllCandidates(storag *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
+		data, next := page.Cut(data, limit, func(c s.InfoCandidate) s.PageCursor { return s.PageCursor{ID: c.ID} })
 		ctx.JSON(200, gin.H{
 			"Status":         "Ok!",
 			"CandidatesInfo": data,
+			"NextCursor":     next,
 		})
 	}
 }
 
 // @Summary Добавить новое резюме для соискателя
 // @Description Позволяет добавить к соискателю новое резюме.
+// @Description Опыт работы, образование и языки добавляются отдельно через /user/resume/work, /user/resume/education и /user/resume/language. Навыки можно передать сразу списком Skills или добавлять по одному через /user/resume/skill
 // @Tags Candidate
 // @Security ApiKeyAuth
 // @Accept json
 // @Produce json
-// @Param ResumeInfo body s.RequestResume true "Основные данные для резюме. В поле experience_id указывайте ID, который уже есть в системе!"
+// @Param ResumeInfo body s.RequestResume true "Основные данные для резюме. В поле experience_id указывайте ID, который уже есть в системе! Навыки передаются названиями из справочника /skills: синоним заменяется основным навыком, неизвестные названия отклоняются"
 // @Success 200 {object} s.Ok "Возвращает статус 'Ok!"
 // @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
 // @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
@@ -669,6 +772,12 @@ func PostNewResume(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		if !checkResumeSkills(ctx, tx, req.Skills) {
+			return
+		}
+		if !checkResumeDetails(ctx, tx, &req.ResumeDetails) {
+			return
+		}
 		uid, ok := get.GetUserIDFromContext(ctx)
 		if !ok {
 			ctx.JSON(http.StatusBadRequest, gin.H{
@@ -696,6 +805,8 @@ func PostNewResume(storag *sqlx.DB) gin.HandlerFunc {
 
 // @Summary Информация про все резюме
 // @Description Позволяет получить всю основную информацию про все резюме пользователя, которые у него есть в системе. Доступно для всех пользователей, но токен обязательный!
+// @Description Сам соискатель и ADMIN видят все резюме. Работодатель видит резюме с Visibility = public, а с Visibility = responded - только если соискатель откликался на его вакансии; почта и телефон соискателя видны ему только после отклика или принятого запроса контактов. Остальные пользователи видят только резюме с Visibility = public без контактов. Пароль виден только самому соискателю и ADMIN
+// @Description Каждое резюме отдаётся целиком: желаемая должность, город, готовность к переезду, ожидаемая зарплата, а также опыт работы (Work), образование (Education), языки (Languages) и навыки (Skills) в заданном соискателем порядке
 // @Tags Candidate
 // @Accept json
 // @Produce json
@@ -709,7 +820,7 @@ func PostNewResume(storag *sqlx.DB) gin.HandlerFunc {
 func GetResumeOfCandidates(storag *sqlx.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		tx := ctx.MustGet("tx").(*sqlx.Tx)
-		_, ok := get.GetUserRoleFromContext(ctx)
+		role, ok := get.GetUserRoleFromContext(ctx)
 		if !ok {
 			ctx.JSON(http.StatusBadRequest, gin.H{
 				"Status": "Err",
@@ -717,6 +828,14 @@ func GetResumeOfCandidates(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		viewerID, ok := get.GetUserIDFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить ID пользователя из заголовка токена",
+			})
+			return
+		}
 		uid, err := strconv.Atoi(ctx.Query("CandidateID"))
 		if err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{
@@ -735,6 +854,30 @@ func GetResumeOfCandidates(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		if role != "ADMIN" && !(role == "candidate" && viewerID == uid) {
+			responded, contacts := false, false
+			if role == "employee" {
+				responded, contacts, err = sqlp.ContactAccess(tx, viewerID, uid)
+				if err != nil {
+					ctx.JSON(http.StatusInternalServerError, gin.H{
+						"Status": "Err",
+						"Info":   "Произошла ошибка на стороне сервера. Ошибка в SQL файле",
+						"Error":  err.Error(),
+					})
+					return
+				}
+			}
+			visible := data.Resumes[:0]
+			for _, resume := range data.Resumes {
+				if resume.Visibility == "public" || (resume.Visibility == "responded" && responded) {
+					visible = append(visible, resume)
+				}
+			}
+			data.Resumes = visible
+			if !contacts {
+				data.Candidate.Email, data.Candidate.PhoneNumber = "", ""
+			}
+		}
 		ctx.JSON(200, gin.H{
 
 			"ResumesInfo":   data.Resumes,
@@ -754,7 +897,7 @@ func GetResumeOfCandidates(storag *sqlx.DB) gin.HandlerFunc {
 // @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
 // @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
 // @Router /user/recover [get]
-func RecoverPassword(mailer *mailer.Mailer) gin.HandlerFunc {
+func RecoverPassword(storag *sqlx.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		tx := ctx.MustGet("tx").(*sqlx.Tx)
 		email := ctx.Query("Email")
@@ -793,9 +936,15 @@ func RecoverPassword(mailer *mailer.Mailer) gin.HandlerFunc {
 				return
 			}
 
-			link := fmt.Sprintf("https://isp-workall.online/api/v1/user/pr?Token=%s", token)
-			text := fmt.Sprintf("Учётная запись в системе WorkAll\n\nМы получили запрос на сброс вашего пароля. Подтвердите это действие и перейдтие по ссылке ниже, чтобы сбросить пароль от вашей учётной записи. Иначе, просто проигнорируйте это письмо!\n%s\n\nС уважением, WorkAll!", link)
-			mailer.SendAsync(email, "Сброс пароля", text)
+			letter := mailer.PasswordResetData{Link: "https://isp-workall.online/api/v1/user/pr?Token=" + token}
+			if err := mailer.Enqueue(tx, email, "password_reset", mailer.LocaleFromHeader(ctx.GetHeader("Accept-Language")), letter); err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{
+					"Status": "Err",
+					"Info":   "Ошибка при постановке письма для сброса пароля в очередь на отправку",
+					"Error":  err.Error(),
+				})
+				return
+			}
 
 		}
 
@@ -805,7 +954,7 @@ func RecoverPassword(mailer *mailer.Mailer) gin.HandlerFunc {
 	}
 }
 
-func ResetPasswordForUser(mailer *mailer.Mailer) gin.HandlerFunc {
+func ResetPasswordForUser(storag *sqlx.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		tx := ctx.MustGet("tx").(*sqlx.Tx)
 		token := ctx.Query("Token")
@@ -840,8 +989,15 @@ func ResetPasswordForUser(mailer *mailer.Mailer) gin.HandlerFunc {
 			})
 			return
 		}
-		text := fmt.Sprintf("Ваш старый пароль был успешно сброшен!\n\nВот ваш новый пароль от учётной записи:\n\nЛогин:%s\nПароль:%s\n\n\n\nС уважением, WorkAll!", tokenArgs.Email, newPassword)
-		mailer.SendAsync(tokenArgs.Email, "Ваш пароль был обновлён!", text)
+		letter := mailer.NewPasswordData{Email: tokenArgs.Email, Password: newPassword}
+		if err := mailer.Enqueue(tx, tokenArgs.Email, "new_password", mailer.LocaleFromHeader(ctx.GetHeader("Accept-Language")), letter); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при постановке письма с новым паролем в очередь на отправку",
+				"Error":  err.Error(),
+			})
+			return
+		}
 
 		ctx.Redirect(http.StatusPermanentRedirect, "https://workall-9eca6.web.app/auth")
 
@@ -1036,3 +1192,462 @@ func AuthorizationMethod(storag *sqlx.DB) gin.HandlerFunc {
 		})
 	}
 }
+
+// @Summary Список удалённых соискателей
+// @Description Позволяет получить список соискателей, которые были удалены, но ещё не стёрты из системы окончательно. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} s.ResponseDeletedEntities "Возвращает статус 'Ok!' и массив удалённых соискателей"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/user/deleted [get]
+func GetDeletedCandidates(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "ADMIN" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		data, err := sqlp.GetDeletedCandidates(tx)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":      "Ok!",
+			"DeletedInfo": data,
+		})
+	}
+}
+
+// @Summary Восстановить удалённого соискателя
+// @Description Позволяет вернуть удалённого соискателя, пока он не был стёрт из системы окончательно. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Produce json
+// @Param UserID query int true "ID соискателя, которого нужно восстановить"
+// @Success 200 {object} s.StatusInfo "Возвращает статус и краткую информацию "
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/user/restore [patch]
+func RestoreUser(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "ADMIN" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		user, err := strconv.Atoi(ctx.Query("UserID"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Error":  err.Error(),
+				"Info":   "ошибка при попытке получить ID соискателя! проверьте его и попробуйте снова",
+			})
+			return
+		}
+		err = sqlp.RestoreCandidate(tx, user)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+			"Info":   "Данные успешно восстановлены!",
+		})
+	}
+}
+
+// @Summary Настройки уведомлений соискателя
+// @Description Позволяет получить, какие уведомления на почту соискатель хочет получать. Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Produce json
+// @Success 200 {object} s.ResponseCandidateNotifySettings "Возвращает статус 'Ok!' и настройки уведомлений"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/notify [get]
+func GetNotifySettings(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "candidate" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		uid, ok := get.GetUserIDFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить ID пользователя из заголовка токена",
+			})
+			return
+		}
+		data, err := sqlp.GetCandidateNotifySettings(tx, uid)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":   "Ok!",
+			"Settings": data,
+		})
+	}
+}
+
+// @Summary Обновить настройки уведомлений соискателя
+// @Description Позволяет включить или отключить уведомления на почту. ResponseStatusEmail - письмо, когда работодатель меняет статус отклика. Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Accept json
+// @Produce json
+// @Param Settings body s.CandidateNotifySettings true "Новые настройки уведомлений"
+// @Success 200 {object} s.ResponseCandidateNotifySettings "Возвращает статус 'Ok!' и сохранённые настройки уведомлений"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/notify [put]
+func PutNotifySettings(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "candidate" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		uid, ok := get.GetUserIDFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить ID пользователя из заголовка токена",
+			})
+			return
+		}
+		var req s.CandidateNotifySettings
+		if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в парсинге запроса! Пожалуйста перепроверьте ваши данные в Body запроса и попробуйте снова!",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		if err := sqlp.UpdateCandidateNotifySettings(tx, uid, req); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":   "Ok!",
+			"Settings": req,
+		})
+	}
+}
+
+// @Summary Сохранённые поиски соискателя
+// @Description Позволяет получить все сохранённые поиски соискателя. Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Produce json
+// @Success 200 {object} s.ResponseSavedSearches "Возвращает статус 'Ok!' и массив сохранённых поисков"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/search [get]
+func GetSavedSearches(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "candidate" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		uid, ok := get.GetUserIDFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить ID пользователя из заголовка токена",
+			})
+			return
+		}
+		data, err := sqlp.GetSavedSearches(tx, uid)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":   "Ok!",
+			"Searches": data,
+		})
+	}
+}
+
+// @Summary Сохранить поиск
+// @Description Позволяет сохранить поиск вакансий с теми же фильтрами, что и у /vac/search (ExpID, Min, Max, Text), и получать письма о новых подходящих вакансиях. Frequency: instant - сразу после появления, daily - раз в сутки, weekly - раз в неделю, off - без писем. В каждом письме есть ссылка для отписки. Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Accept json
+// @Produce json
+// @Param Search body s.RequestSavedSearch true "Название, фильтры и частота писем"
+// @Success 200 {object} s.ResponseSavedSearch "Возвращает статус 'Ok!' и сохранённый поиск"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/search [post]
+func PostSavedSearch(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "candidate" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		uid, ok := get.GetUserIDFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить ID пользователя из заголовка токена",
+			})
+			return
+		}
+		var req s.RequestSavedSearch
+		if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в парсинге запроса! Пожалуйста перепроверьте ваши данные в Body запроса и попробуйте снова!",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		if req.Name == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "У сохранённого поиска должно быть название!",
+			})
+			return
+		}
+		if req.ExpID == nil && req.Min == nil && req.Max == nil && (req.Text == nil || *req.Text == "") {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Вы передали ни одного фильтра!",
+			})
+			return
+		}
+		if req.Frequency == "" {
+			req.Frequency = "daily"
+		}
+		switch req.Frequency {
+		case "instant", "daily", "weekly", "off":
+		default:
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Неизвестная частота писем! Доступные: instant, daily, weekly, off",
+			})
+			return
+		}
+		token, err := notify.NewUnsubscribeToken()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при создании токена отписки",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, err := sqlp.CreateSavedSearch(tx, uid, req, token)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+			"Search": data,
+		})
+	}
+}
+
+// @Summary Удалить сохранённый поиск
+// @Description Позволяет удалить сохранённый поиск. Письма по нему больше не приходят. Доступ имеет только роль candidate
+// @Security ApiKeyAuth
+// @Tags Candidate
+// @Produce json
+// @Param ID query int true "ID сохранённого поиска"
+// @Success 200 {object} s.StatusInfo "Возвращает статус 'Ok!' и небольшую информацию"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /user/search [delete]
+func DeleteSavedSearch(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "candidate" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		uid, ok := get.GetUserIDFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "ошибка в попытке получить ID пользователя из заголовка токена",
+			})
+			return
+		}
+		id, err := strconv.Atoi(ctx.Query("ID"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при попытке получить ID сохранённого поиска! проверьте его и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		if err := sqlp.DeleteSavedSearch(tx, uid, id); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+			"Info":   "Успешно удалили данные!",
+		})
+	}
+}
+
+// @Summary Отписаться от писем по сохранённому поиску
+// @Description Ссылка из письма с новыми вакансиями. Отключает письма по сохранённому поиску (Frequency становится off), сам поиск остаётся. Авторизация не нужна, достаточно токена из письма
+// @Tags Candidate
+// @Produce json
+// @Param Token query string true "Токен отписки из письма"
+// @Success 200 {object} s.StatusInfo "Возвращает статус 'Ok!' и небольшую информацию"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если токен не передан"
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если сохранённого поиска с таким токеном нет"
+// @Router /user/search/unsubscribe [get]
+func UnsubscribeSavedSearch(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		token := ctx.Query("Token")
+		if token == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Не передан токен отписки!",
+			})
+			return
+		}
+		data, err := sqlp.UnsubscribeSavedSearch(tx, token)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"Status": "Err",
+				"Info":   "Не удалось отписаться от писем",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+			"Info":   fmt.Sprintf("Вы отписались от писем по поиску «%s»", data.Name),
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package vacancy
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	s "main.go/internal/api/Struct"
+	"main.go/internal/skill"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+// checkAttributes проверяет атрибуты вакансии из запроса: значения справочников и навыки должны быть в системе, а навыки
+// очищаются от пробелов и повторов. При ошибке отвечает 400 и возвращает false
+func checkAttributes(ctx *gin.Context, tx *sqlx.Tx, attrs *s.VacancyAttributesInput) bool {
+	dictionaries := []struct {
+		table string
+		id    *int
+		param string
+	}{
+		{sqlp.EmploymentTypes, attrs.EmploymentTypeID, "EmploymentTypeID"},
+		{sqlp.Schedules, attrs.ScheduleID, "ScheduleID"},
+		{sqlp.WorkFormats, attrs.WorkFormatID, "WorkFormatID"},
+	}
+	for _, d := range dictionaries {
+		if d.id == nil {
+			continue
+		}
+		exists, err := sqlp.DictionaryHasID(tx, d.table, *d.id)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return false
+		}
+		if !exists {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   fmt.Sprintf("Значения %s = %d нету в системе! Перепроверьте данные и попробуйте снова", d.param, *d.id),
+			})
+			return false
+		}
+	}
+
+	if attrs.Skills == nil {
+		return true
+	}
+	skills, err := skill.Normalize(*attrs.Skills)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "Ошибка в списке навыков вакансии! Перепроверьте данные и попробуйте снова",
+			"Error":  err.Error(),
+		})
+		return false
+	}
+	unknown, err := sqlp.UnknownSkills(tx, skills)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"Status": "Err",
+			"Info":   "Ошибка в SQL файле",
+			"Error":  err.Error(),
+		})
+		return false
+	}
+	if len(unknown) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   fmt.Sprintf("Навыков %s нету в системе! Выберите навыки из подсказок /skills/suggest", strings.Join(unknown, ", ")),
+			"Error":  sqlp.ErrUnknownSkill.Error(),
+		})
+		return false
+	}
+	*attrs.Skills = skills
+	return true
+}
+
+// attributeFilter достаёт из запроса поиска фильтры по атрибутам. Каждый параметр можно передать несколько раз
+func attributeFilter(query url.Values) (s.VacancyAttributeFilter, error) {
+	var filter s.VacancyAttributeFilter
+	var err error
+	if filter.EmploymentTypeIDs, err = queryIDs(query, "EmploymentTypeID"); err != nil {
+		return filter, err
+	}
+	if filter.ScheduleIDs, err = queryIDs(query, "ScheduleID"); err != nil {
+		return filter, err
+	}
+	if filter.WorkFormatIDs, err = queryIDs(query, "WorkFormatID"); err != nil {
+		return filter, err
+	}
+	if filter.SkillIDs, err = queryIDs(query, "SkillID"); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// queryIDs читает повторяющийся параметр запроса как список ID без повторов
+func queryIDs(query url.Values, name string) ([]int, error) {
+	var ids []int
+	seen := make(map[int]bool)
+	for _, value := range query[name] {
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("неверное значение %s: %s", name, value)
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,78 @@
+package vacancy
+
+import (
+	"log"
+
+	"github.com/jmoiron/sqlx"
+	"main.go/internal/events"
+	"main.go/internal/notify"
+	"main.go/internal/scheduler"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+// expireBatch - сколько вакансий снимается с публикации за одну транзакцию
+const expireBatch = 50
+
+// RegisterJobs добавляет в планировщик задачи, связанные со сроком публикации вакансий
+func RegisterJobs(sc *scheduler.Scheduler) {
+	sc.Register(scheduler.Job{
+		Name:        "vacancy_expire",
+		Description: "Снимает с публикации вакансии, срок которых закончился",
+		Schedule:    "*/5 * * * *",
+		Run: func(storage *sqlx.DB) error {
+			_, err := ExpireVacancies(storage)
+			return err
+		},
+	})
+	sc.Register(scheduler.Job{
+		Name:        "vacancy_expiry_reminders",
+		Description: "Напоминает работодателям о вакансиях, срок которых скоро закончится",
+		Schedule:    "0 * * * *",
+		Run: func(storage *sqlx.DB) error {
+			_, err := notify.SendExpiryReminders(storage)
+			return err
+		},
+	})
+}
+
+// ExpireVacancies переводит вакансии с истёкшим сроком в состояние closed и возвращает их количество
+func ExpireVacancies(storage *sqlx.DB) (int, error) {
+	total := 0
+	for {
+		n, err := expireBatchOnce(storage)
+		total += n
+		if err != nil || n < expireBatch {
+			if total > 0 {
+				log.Printf("expired %d vacancies", total)
+			}
+			return total, err
+		}
+	}
+}
+
+func expireBatchOnce(storage *sqlx.DB) (int, error) {
+	tx, err := storage.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		tx.Rollback()
+		events.Discard(tx)
+	}()
+
+	ids, err := sqlp.ExpireVacancies(tx, expireBatch)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		if err := notify.VacancyExpired(tx, id); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	// события для подключённых клиентов отправляются только после коммита, как и в MakeTransaction
+	events.Commit(tx)
+	return len(ids), nil
+}
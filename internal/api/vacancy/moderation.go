@@ -0,0 +1,194 @@
+package vacancy
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	s "main.go/internal/api/Struct"
+	"main.go/internal/api/get"
+	"main.go/internal/api/page"
+	"main.go/internal/lifecycle"
+	"main.go/internal/notify"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+// publishState возвращает состояние, в которое попадает вакансия работодателя при публикации: moderation, если модерация
+// включена и статус работодателя не доверенный, иначе published
+func publishState(tx *sqlx.Tx, empID int) (string, error) {
+	if !lifecycle.ModerationEnabled() {
+		return lifecycle.Published, nil
+	}
+	trusted, err := sqlp.IsEmployerTrusted(tx, empID)
+	if err != nil {
+		return "", err
+	}
+	if trusted {
+		return lifecycle.Published, nil
+	}
+	return lifecycle.Moderation, nil
+}
+
+// isSubstantialEdit - изменились ли поля, которые нужно заново проверить модератору (название, описание и контакты)
+func isSubstantialEdit(old s.VacancyData_Limit, req s.VacancyPut) bool {
+	return old.Name != req.VacancyName || old.AboutWork != req.About || old.Email != req.Email || old.PhoneNumber != req.PhoneNumber
+}
+
+// @Summary Очередь модерации вакансий
+// @Description Возвращает вакансии, которые ждут решения модератора, в порядке поступления. Вакансии попадают в очередь, если включена модерация (VACANCY_MODERATION=on) и статус работодателя не доверенный. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Produce json
+// @Param Cursor query string false "Курсор следующей страницы (NextCursor из предыдущего ответа). Если не передан - отдаётся первая страница"
+// @Param Limit query int false "Кол-во записей на странице. По умолчанию 20, максимум 100"
+// @Success 200 {object} s.ResponseModerationQueue "Возвращает статус 'Ok!', массив вакансий и курсор следующей страницы"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/moderation [get]
+func GetModerationQueue(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		if _, ok := get.UserIDWithRole(ctx, "ADMIN"); !ok {
+			return
+		}
+		after, limit, err := page.FromQuery(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при попытке получить параметры страницы (Cursor, Limit)! проверьте их и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, err := sqlp.GetModerationQueue(tx, after.ID, limit+1)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, next := page.Cut(data, limit, func(v s.ModerationVacancy) s.PageCursor { return s.PageCursor{ID: v.ID} })
+		ctx.JSON(200, gin.H{
+			"Status":      "Ok!",
+			"VacancyInfo": data,
+			"NextCursor":  next,
+		})
+	}
+}
+
+// @Summary Одобрить вакансию
+// @Description Публикует вакансию из очереди модерации. Работодатель получает уведомление и письмо. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Produce json
+// @Param VacancyID query int true "ID вакансии"
+// @Success 200 {object} s.StatusInfo "Возвращает статус 'Ok!'"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса или вакансия не на модерации"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/moderation/approve [post]
+func ApproveVacancy(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		moderate(ctx, lifecycle.Published, nil)
+	}
+}
+
+// @Summary Отклонить вакансию
+// @Description Возвращает вакансию из очереди модерации в черновики с указанием причины. Причина видна работодателю в данных вакансии (ModerationReason), также он получает уведомление и письмо. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param VacancyID query int true "ID вакансии"
+// @Param Reason body s.RequestModerationReject true "Причина отказа"
+// @Success 200 {object} s.StatusInfo "Возвращает статус 'Ok!'"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса, не указана причина или вакансия не на модерации"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/moderation/reject [post]
+func RejectVacancy(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req s.RequestModerationReject
+		if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в парсинге запроса! Пожалуйста перепроверьте ваши данные в Body запроса и попробуйте снова!",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		reason := strings.TrimSpace(req.Reason)
+		if reason == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Укажите причину отказа, она будет показана работодателю",
+			})
+			return
+		}
+		moderate(ctx, lifecycle.Draft, &reason)
+	}
+}
+
+// moderate переводит вакансию из очереди модерации в состояние to и сообщает работодателю о решении
+func moderate(ctx *gin.Context, to string, reason *string) {
+	tx := ctx.MustGet("tx").(*sqlx.Tx)
+	uid, ok := get.UserIDWithRole(ctx, "ADMIN")
+	if !ok {
+		return
+	}
+	vacID, err := strconv.Atoi(ctx.Query("VacancyID"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Error":  err.Error(),
+			"Info":   "Ошибка при попытке получить ID вакансии! проверьте его и попробуйте снова",
+		})
+		return
+	}
+	if _, err := sqlp.UpdateVacancyState(tx, vacID, 0, 0, lifecycle.Moderation, to); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "Вакансия не найдена или уже не находится на модерации",
+			"Error":  err.Error(),
+		})
+		return
+	}
+	approved := to == lifecycle.Published
+	decision, text := "approved", ""
+	if !approved {
+		decision, text = "rejected", *reason
+	}
+	if err := sqlp.AddModerationDecision(tx, vacID, uid, decision, reason); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"Status": "Err",
+			"Info":   "Ошибка в SQL файле",
+			"Error":  err.Error(),
+		})
+		return
+	}
+	if err := notify.VacancyStateChanged(tx, vacID, lifecycle.Moderation, to); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"Status": "Err",
+			"Info":   "Ошибка при отправке события об изменении состояния вакансии",
+			"Error":  err.Error(),
+		})
+		return
+	}
+	if err := notify.VacancyModerated(tx, vacID, approved, text); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"Status": "Err",
+			"Info":   "Ошибка при отправке работодателю уведомления о решении модератора",
+			"Error":  err.Error(),
+		})
+		return
+	}
+	ctx.JSON(200, gin.H{
+		"Status": "Ok!",
+		"Info":   "Решение сохранено, работодатель получит уведомление",
+	})
+}
@@ -0,0 +1,214 @@
+package vacancy
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	s "main.go/internal/api/Struct"
+	"main.go/internal/api/get"
+	"main.go/internal/api/page"
+	"main.go/internal/salary"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+// canViewRevisions - редакции вакансии видят администратор, её работодатель и соискатели, которые на неё откликались
+func canViewRevisions(tx *sqlx.Tx, role string, uid, vacID int) (bool, error) {
+	switch role {
+	case "ADMIN":
+		return true, nil
+	case "employee":
+		data, err := sqlp.GetVacancyInfoByID(tx, vacID)
+		if err != nil {
+			return false, nil
+		}
+		return data.Employer.ID == uid, nil
+	case "candidate":
+		response, err := sqlp.GetResponseOnVacancy(tx, uid, vacID)
+		if err != nil {
+			return false, err
+		}
+		return response.IsResponsed, nil
+	}
+	return false, nil
+}
+
+// revisionsAccess достаёт ID вакансии из запроса и проверяет доступ к её редакциям
+func revisionsAccess(ctx *gin.Context, tx *sqlx.Tx) (int, bool) {
+	role, ok := get.GetUserRoleFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "Ошибка в попытке получить роль пользователя из заголовка токена",
+		})
+		return 0, false
+	}
+	uid, ok := get.GetUserIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "Ошибка в попытке получить ID пользователя из заголовка токена",
+		})
+		return 0, false
+	}
+	vacID, err := strconv.Atoi(ctx.Query("VacancyID"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Error":  err.Error(),
+			"Info":   "Ошибка при попытке получить ID вакансии! проверьте его и попробуйте снова",
+		})
+		return 0, false
+	}
+	allowed, err := canViewRevisions(tx, role, uid, vacID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"Status": "Err",
+			"Info":   "Ошибка в SQL файле",
+			"Error":  err.Error(),
+		})
+		return 0, false
+	}
+	if !allowed {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"Status": "Err",
+			"Info":   "У вас нету прав к этому функционалу!",
+		})
+		return 0, false
+	}
+	return vacID, true
+}
+
+// diffRevisions возвращает поля, которые отличаются в редакциях from и to
+func diffRevisions(from, to s.VacancyRevision) []s.RevisionChange {
+	changes := []s.RevisionChange{}
+	add := func(field string, a, b any) {
+		if a != b {
+			changes = append(changes, s.RevisionChange{Field: field, From: a, To: b})
+		}
+	}
+	add("Name", from.Name, to.Name)
+	add("SalaryFrom", intValue(from.Salary.From), intValue(to.Salary.From))
+	add("SalaryTo", intValue(from.Salary.To), intValue(to.Salary.To))
+	add("Currency", from.Salary.Currency, to.Salary.Currency)
+	add("Gross", salary.IsGross(from.Salary), salary.IsGross(to.Salary))
+	add("Period", from.Salary.Period, to.Salary.Period)
+	add("Email", from.Email, to.Email)
+	add("PhoneNumber", from.PhoneNumber, to.PhoneNumber)
+	add("Location", from.Location, to.Location)
+	add("Experience", from.Experience.Name, to.Experience.Name)
+	add("AboutWork", from.AboutWork, to.AboutWork)
+	return changes
+}
+
+// intValue разыменовывает необязательное число, чтобы сравнивать значения, а не указатели
+func intValue(value *int) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+// @Summary Редакции вакансии
+// @Description Возвращает редакции вакансии от новых к старым. Редакция сохраняется при создании вакансии и при каждом изменении её содержимого (название, зарплата, контакты, город, опыт, описание). Доступно администратору, работодателю вакансии и соискателям, которые на неё откликались
+// @Tags Vacancy
+// @Security ApiKeyAuth
+// @Produce json
+// @Param VacancyID query int true "ID вакансии"
+// @Param Cursor query string false "Курсор следующей страницы (NextCursor из предыдущего ответа). Если не передан - отдаётся первая страница"
+// @Param Limit query int false "Кол-во записей на странице. По умолчанию 20, максимум 100"
+// @Success 200 {object} s.ResponseVacancyRevisions "Возвращает статус 'Ok!', массив редакций и курсор следующей страницы"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /vac/revisions [get]
+func GetVacancyRevisions(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		vacID, ok := revisionsAccess(ctx, tx)
+		if !ok {
+			return
+		}
+		before, limit, err := page.FromQuery(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при попытке получить параметры страницы (Cursor, Limit)! проверьте их и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, err := sqlp.GetVacancyRevisions(tx, vacID, before.ID, limit+1)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, next := page.Cut(data, limit, func(r s.VacancyRevision) s.PageCursor { return s.PageCursor{ID: r.ID} })
+		ctx.JSON(200, gin.H{
+			"Status":     "Ok!",
+			"Revisions":  data,
+			"NextCursor": next,
+		})
+	}
+}
+
+// @Summary Сравнить редакции вакансии
+// @Description Возвращает обе редакции и список полей, которые в них отличаются. ID редакции, на которую откликнулся соискатель, есть в данных отклика (VacancyRevisionID). Доступно администратору, работодателю вакансии и соискателям, которые на неё откликались
+// @Tags Vacancy
+// @Security ApiKeyAuth
+// @Produce json
+// @Param VacancyID query int true "ID вакансии"
+// @Param From query int true "ID первой редакции"
+// @Param To query int true "ID второй редакции"
+// @Success 200 {object} s.ResponseRevisionDiff "Возвращает статус 'Ok!', обе редакции и изменённые поля"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса или редакции нету у этой вакансии"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /vac/revisions/diff [get]
+func DiffVacancyRevisions(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		vacID, ok := revisionsAccess(ctx, tx)
+		if !ok {
+			return
+		}
+		fromID, err1 := strconv.Atoi(ctx.Query("From"))
+		toID, err2 := strconv.Atoi(ctx.Query("To"))
+		if err1 != nil || err2 != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при попытке получить ID редакций (From, To)! проверьте их и попробуйте снова",
+			})
+			return
+		}
+		from, err := sqlp.GetVacancyRevision(tx, vacID, fromID)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Такой редакции нету в системе! Перепроверьте данные и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		to, err := sqlp.GetVacancyRevision(tx, vacID, toID)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Такой редакции нету в системе! Перепроверьте данные и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":  "Ok!",
+			"From":    from,
+			"To":      to,
+			"Changes": diffRevisions(from, to),
+		})
+	}
+}
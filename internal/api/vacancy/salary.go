@@ -0,0 +1,32 @@
+package vacancy
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	s "main.go/internal/api/Struct"
+	"main.go/internal/salary"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+// checkSalary проверяет зарплату из запроса и подставляет значения по умолчанию. При ошибке отвечает 400 и возвращает false
+func checkSalary(ctx *gin.Context, tx *sqlx.Tx, sal *s.Salary) bool {
+	if err := salary.Normalize(sal); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "Неверно указана зарплата! Перепроверьте данные и попробуйте снова",
+			"Error":  err.Error(),
+		})
+		return false
+	}
+	if _, err := sqlp.GetCurrency(tx, sal.Currency); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "Такой валюты нету в системе! Список доступных валют можно получить в /currency",
+			"Error":  err.Error(),
+		})
+		return false
+	}
+	return true
+}
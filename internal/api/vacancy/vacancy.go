@@ -2,31 +2,42 @@ package vacancy
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
 	s "main.go/internal/api/Struct"
+	"main.go/internal/api/etag"
 	"main.go/internal/api/get"
+	"main.go/internal/api/page"
+	"main.go/internal/lifecycle"
+	"main.go/internal/notify"
+	"main.go/internal/salary"
 	sqlp "main.go/internal/storage/postSQL"
 )
 
-// @Summary Изменить видимость вакансии
-// @Description Позволяет изменить видимость вакансии. Доступно только пользователям группы employee и ADMIN
+// @Summary Изменить состояние вакансии
+// @Description Переводит вакансию в другое состояние: draft (черновик), moderation (на проверке), published (опубликована), closed (снята с публикации), archived (в архиве). Работодатель может: draft -> moderation, published или archived; moderation -> draft; published -> closed или archived; closed -> moderation, published или archived. Если включена модерация и статус работодателя не доверенный, то вместо published вакансия переходит в moderation (итоговое состояние возвращается в поле State). Из archived вакансию вывести нельзя. Администратор, кроме того, может опубликовать вакансию, которая на проверке. Доступно только пользователям группы employee и ADMIN
 // @Tags Vacancy
 // @Security ApiKeyAuth
 // @Accept json
 // @Produce json
-// @Param VacancyID query int true "ID вакансии, которую работодатель хочет скрыть или вернуть на всеобщее обозрение"
+// @Param VacancyID query int true "ID вакансии"
+// @Param State query string true "Новое состояние вакансии" Enums(draft, moderation, published, closed, archived)
+// @Param If-Match header string false "ETag, полученный вместе с данными вакансии. Если вакансия успела измениться, то вернётся 412"
 // @Success 200 {object} s.StatusInfo "Возвращает статус 'Ok!'"
-// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса или такой переход между состояниями запрещён"
 // @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 412 {object} s.InfoError "Возвращает ошибку, если вакансия уже была изменена кем-то другим"
 // @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
-// @Router /vac/visible [patch]
-func PatchVisibleVacancy(storag *sqlx.DB) gin.HandlerFunc {
+// @Router /vac/state [patch]
+func PatchVacancyState(storag *sqlx.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		tx := ctx.MustGet("tx").(*sqlx.Tx)
 		role, ok := get.GetUserRoleFromContext(ctx)
@@ -62,17 +73,54 @@ func PatchVisibleVacancy(storag *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 		data, err := sqlp.GetVacancyInfoByID(tx, vacID)
-		if err != nil {
+		if err != nil || (role == "employee" && data.Employer.ID != emp_id) {
 			ctx.JSON(http.StatusBadRequest, gin.H{
 				"Status": "Err",
 				"Info":   "Такой вакансии нету в системе! Перепроверьте данные и попробуйте снова",
+			})
+			return
+		}
+		state := ctx.Query("State")
+		// если включена модерация, то работодатель без доверенного статуса публикует вакансию через очередь модерации
+		if role == "employee" && state == lifecycle.Published {
+			state, err = publishState(tx, emp_id)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{
+					"Status": "Err",
+					"Info":   "Ошибка в SQL файле при проверке статуса работодателя",
+					"Error":  err.Error(),
+				})
+				return
+			}
+		}
+		if err := lifecycle.CanTransition(data.State, state, role == "ADMIN"); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Нельзя перевести вакансию в это состояние",
 				"Error":  err.Error(),
 			})
 			return
 		}
 
-		err = sqlp.PatchVisibilityVacancy(tx, vacID, emp_id, !data.IsVisible)
+		version, err := etag.FromIfMatch(ctx)
 		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в заголовке If-Match! Передайте в нём ETag, который был получен вместе с данными",
+				"Error":  err.Error(),
+			})
+			return
+		}
+
+		newVersion, err := sqlp.UpdateVacancyState(tx, vacID, data.Employer.ID, version, data.State, state)
+		if err == sqlp.ErrVersionMismatch {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{
+				"Status": "Err",
+				"Info":   "Данные вакансии уже были изменены кем-то другим! Получите актуальные данные и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		} else if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"Status": "Err",
 				"Info":   "Ошибка в SQL файле для обновления данных вакансии",
@@ -80,24 +128,157 @@ func PatchVisibleVacancy(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		if err := notify.VacancyStateChanged(tx, vacID, data.State, state); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при отправке события об изменении состояния вакансии",
+				"Error":  err.Error(),
+			})
+			return
+		}
 
+		etag.Set(ctx, newVersion)
 		ctx.JSON(200, gin.H{
 			"Status": "Ok!",
 			"Info":   "Данные успешно обновлены!",
+			"State":  state,
+		})
+	}
+}
+
+// @Summary Продлить вакансию
+// @Description Продлевает срок публикации вакансии на стандартный срок от текущего момента. Если вакансию уже сняли с публикации (состояние closed, например, из-за окончания срока), то она публикуется снова (при включённой модерации - отправляется на модерацию, если статус работодателя не доверенный). Ссылка на продление приходит работодателю в письме за несколько дней до окончания срока. Доступно только пользователям группы employee
+// @Tags Vacancy
+// @Security ApiKeyAuth
+// @Produce json
+// @Param VacancyID query int true "ID вакансии"
+// @Param If-Match header string false "ETag, полученный вместе с данными вакансии. Если вакансия успела измениться, то вернётся 412"
+// @Success 200 {object} s.ResponseRenewVacancy "Возвращает статус 'Ok!' и новый срок публикации"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса или вакансию в её состоянии нельзя продлить"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 412 {object} s.InfoError "Возвращает ошибку, если вакансия уже была изменена кем-то другим"
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /vac/renew [patch]
+func RenewVacancy(storag *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "employee" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		emp_id, ok := get.GetUserIDFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в попытке получить ID пользователя из заголовка токена",
+			})
+			return
+		}
+		vacID, err := strconv.Atoi(ctx.Query("VacancyID"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Error":  err.Error(),
+				"Info":   "Ошибка при попытке получить ID вакансии! проверьте его и попробуйте снова",
+			})
+			return
+		}
+		data, err := sqlp.GetVacancyInfoByID(tx, vacID)
+		if err != nil || data.Employer.ID != emp_id {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Такой вакансии нету в системе! Перепроверьте данные и попробуйте снова",
+			})
+			return
+		}
+		version, err := etag.FromIfMatch(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в заголовке If-Match! Передайте в нём ETag, который был получен вместе с данными",
+				"Error":  err.Error(),
+			})
+			return
+		}
+
+		var newVersion int
+		switch data.State {
+		case lifecycle.Published:
+			newVersion, err = sqlp.RenewVacancy(tx, vacID, emp_id, version, lifecycle.Lifetime())
+		case lifecycle.Closed:
+			// снятую с публикации вакансию публикуем снова (или отправляем на модерацию), срок при этом отсчитывается заново
+			var state string
+			state, err = publishState(tx, emp_id)
+			if err == nil {
+				newVersion, err = sqlp.UpdateVacancyState(tx, vacID, emp_id, version, data.State, state)
+			}
+			if err == nil {
+				err = notify.VacancyStateChanged(tx, vacID, data.State, state)
+			}
+		default:
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Продлить можно только опубликованную или снятую с публикации вакансию",
+			})
+			return
+		}
+		if err == sqlp.ErrVersionMismatch {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{
+				"Status": "Err",
+				"Info":   "Данные вакансии уже были изменены кем-то другим! Получите актуальные данные и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		} else if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле для обновления данных вакансии",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		renewed, err := sqlp.GetVacancyInfoByID(tx, vacID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле вакансий",
+				"Error":  err.Error(),
+			})
+			return
+		}
+
+		etag.Set(ctx, newVersion)
+		ctx.JSON(200, gin.H{
+			"Status":    "Ok!",
+			"ExpiresAt": renewed.ExpiresAt,
 		})
 	}
 }
 
 // @Summary Обновить информцию о вакансии
-// @Description Позволяет обновить всю основную информацию о вакансии. Доступно только пользователям группы employee и ADMIN
+// @Description Позволяет обновить всю основную информацию о вакансии, в том числе атрибуты: список навыков заменяется переданным целиком, а если Skills не передан, то навыки не меняются. Прошлое содержимое сохраняется в редакциях вакансии (/vac/revisions). Если включена модерация, статус работодателя не доверенный и у опубликованной вакансии изменились название, описание или контакты, то вакансия снова отправляется на модерацию. Доступно только пользователям группы employee и ADMIN
 // @Tags Vacancy
 // @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param VacancyInfo body s.VacancyPut  true "Данные о вакансии, на которые нужно обновить в системе"
+// @Param If-Match header string false "ETag, полученный вместе с данными вакансии. Если вакансия успела измениться, то вернётся 412"
 // @Success 200 {object} s.StatusInfo "Возвращает статус 'Ok!' и небольшую информацию"
 // @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
 // @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 404 {object} s.InfoError "Возвращает ошибку, если вакансия не найдена среди вакансий работодателя"
+// @Failure 412 {object} s.InfoError "Возвращает ошибку, если вакансия уже была изменена кем-то другим"
 // @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
 // @Router /vac [put]
 func PutVacancy(storag *sqlx.DB) gin.HandlerFunc {
@@ -127,7 +308,7 @@ func PutVacancy(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
-		if req.Email == "" || req.VacancyName == "" || req.ID <= 0 || req.PhoneNumber == "" || req.Price <= 0 || req.About == "" || req.ExperienceId <= 0 || req.Location == "" {
+		if req.Email == "" || req.VacancyName == "" || req.ID <= 0 || req.PhoneNumber == "" || req.About == "" || req.ExperienceId <= 0 || req.Location == "" {
 			ctx.JSON(http.StatusBadRequest, gin.H{
 				"Status": "Err",
 				"Info":   "Вы не передали все необходимые данные! Пожалуйста перепроверьте данные, которые вы передаете в Body запроса и попробуйте снова!",
@@ -135,6 +316,9 @@ func PutVacancy(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		if !checkSalary(ctx, tx, &req.Salary) || !checkAttributes(ctx, tx, &req.VacancyAttributesInput) {
+			return
+		}
 		uid, ok := get.GetUserIDFromContext(ctx)
 		if !ok {
 			ctx.JSON(http.StatusBadRequest, gin.H{
@@ -144,8 +328,39 @@ func PutVacancy(storag *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
-		err := sqlp.UpdateVacancyInfo(tx, req, uid)
+		version, err := etag.FromIfMatch(ctx)
 		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в заголовке If-Match! Передайте в нём ETag, который был получен вместе с данными",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		old, err := sqlp.GetVacancyInfoByID(tx, req.ID)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Такой вакансии нету в системе! Перепроверьте данные и попробуйте снова",
+			})
+			return
+		}
+		newVersion, err := sqlp.UpdateVacancyInfo(tx, req, uid, version)
+		if err == sqlp.ErrVersionMismatch {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{
+				"Status": "Err",
+				"Info":   "Данные вакансии уже были изменены кем-то другим! Получите актуальные данные и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		} else if errors.Is(err, sqlp.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"Status": "Err",
+				"Info":   "Вакансия не была найдена среди ваших вакансий! Перепроверьте данные и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		} else if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"Status": "Err",
 				"Info":   "Ошибка в SQL файле для обновления данных вакансии",
@@ -154,9 +369,39 @@ func PutVacancy(storag *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
+		if _, err := sqlp.AddVacancyRevision(tx, req.ID, role, uid); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле при сохранении редакции вакансии",
+				"Error":  err.Error(),
+			})
+			return
+		}
+
+		state := old.State
+		if role == "employee" && old.State == lifecycle.Published && isSubstantialEdit(old, req) {
+			state, err = publishState(tx, uid)
+			if err == nil && state == lifecycle.Moderation {
+				newVersion, err = sqlp.UpdateVacancyState(tx, req.ID, uid, newVersion, old.State, state)
+				if err == nil {
+					err = notify.VacancyStateChanged(tx, req.ID, old.State, state)
+				}
+			}
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{
+					"Status": "Err",
+					"Info":   "Ошибка при отправке вакансии на модерацию",
+					"Error":  err.Error(),
+				})
+				return
+			}
+		}
+
+		etag.Set(ctx, newVersion)
 		ctx.JSON(200, gin.H{
 			"Status": "Ok!",
 			"Info":   "Данные успешно обновлены!",
+			"State":  state,
 		})
 	}
 }
@@ -197,10 +442,17 @@ func GetVacanciesNumbers(storag *sqlx.DB) gin.HandlerFunc {
 // @Tags Vacancy
 // @Produce json
 // @Param ExpID query int false "ID опыта"
-// @Param Min query int false "Минимальная ЗП"
-// @Param Max query int false "Максимальная ЗП"
-// @Param Text query string false "Искомый текст в названии вакансии"
-// @Success 200 {object} s.VacanciesByLimitResponse "Возвращает статус 'Ok!' и массив всех данных вакансий"
+// @Param Min query int false "Минимальная ЗП в месяц до вычета налогов. Подходят вакансии, вилка зарплаты которых пересекается с диапазоном Min-Max, зарплата на руки пересчитывается с учётом налога"
+// @Param Max query int false "Максимальная ЗП в месяц до вычета налогов"
+// @Param Currency query string false "Валюта Min и Max, по умолчанию RUB. Зарплаты вакансий в других валютах и почасовая оплата пересчитываются по курсам из /currency"
+// @Param EmploymentTypeID query []int false "ID типа занятости из /employment. Можно передать несколько раз - подойдёт любой из них" collectionFormat(multi)
+// @Param ScheduleID query []int false "ID графика работы из /schedule. Можно передать несколько раз" collectionFormat(multi)
+// @Param WorkFormatID query []int false "ID формата работы (офис, гибрид, удалённо) из /format. Можно передать несколько раз" collectionFormat(multi)
+// @Param SkillID query []int false "ID навыка. Можно передать несколько раз - у вакансии должны быть все навыки" collectionFormat(multi)
+// @Param Text query string false "Искомый текст. Ищется по названию, описанию, городу и названию работодателя с учётом словоформ. Поддерживаются кавычки, 'or' и '-' для исключения слов"
+// @Param Cursor query string false "Курсор следующей страницы (NextCursor из предыдущего ответа). Если не передан - отдаётся первая страница"
+// @Param Limit query int false "Кол-во записей на странице. По умолчанию 20, максимум 100"
+// @Success 200 {object} s.VacanciesSearchResponse "Возвращает статус 'Ok!', массив данных вакансий, отсортированный по релевантности, с подсвеченными фрагментами текста (экранированный HTML, найденные слова в <b>) и курсор следующей страницы (пустой, если страница последняя)"
 // @Failure 400 {object} s.InfoError "Возвращает ошибку, если произошла при попытке получить передаваемые данные. Или если параметров для фильтрации вообще не будет"
 // @Failure 500 {object} s.InfoError "Возвращает ошибку, если произошла на стороне сервера."
 // @Router /vac/search [get]
@@ -213,14 +465,23 @@ func SearchVacancies(storage *sqlx.DB) gin.HandlerFunc {
 		isMin := queryParams.Has("Min")
 		isMax := queryParams.Has("Max")
 		isText := queryParams.Has("Text")
-		if !(isExp || isMin || isMax || isText) {
+		attrs, err := attributeFilter(queryParams)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Error":  err.Error(),
+				"Info":   "Ошибка при попытке получить фильтры по атрибутам вакансии! проверьте их и попробуйте снова",
+			})
+			return
+		}
+		isAttrs := len(attrs.EmploymentTypeIDs)+len(attrs.ScheduleIDs)+len(attrs.WorkFormatIDs)+len(attrs.SkillIDs) > 0
+		if !(isExp || isMin || isMax || isText || isAttrs) {
 			ctx.JSON(http.StatusBadRequest, gin.H{
 				"Status": "Err",
 				"Info":   "Вы передали ни одного фильтра!",
 			})
 			return
 		}
-		var err error
 		var ExpID int
 		var Min int
 		var Max int
@@ -262,7 +523,30 @@ func SearchVacancies(storage *sqlx.DB) gin.HandlerFunc {
 		if isText {
 			Text = queryParams.Get("Text")
 		}
-		data, err := sqlp.GetVacanciesToFind(tx, ExpID, Max, Min, Text, isExp, isMax, isMin, isText)
+		// границы зарплаты в запросе переводятся в базовую валюту, в которой сравниваются вакансии
+		if currency := strings.ToUpper(queryParams.Get("Currency")); (isMin || isMax) && currency != "" && currency != salary.BaseCurrency {
+			rate, err := sqlp.GetCurrency(tx, currency)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{
+					"Status": "Err",
+					"Error":  err.Error(),
+					"Info":   "Такой валюты нету в системе! Список доступных валют можно получить в /currency",
+				})
+				return
+			}
+			Min = int(math.Round(float64(Min) * rate.Rate))
+			Max = int(math.Round(float64(Max) * rate.Rate))
+		}
+		after, limit, err := page.FromQuery(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при попытке получить параметры страницы (Cursor, Limit)! проверьте их и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, err := sqlp.GetVacanciesToFind(tx, ExpID, Max, Min, Text, isExp, isMax, isMin, isText, attrs, nil, after, limit+1)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"Status": "Err",
@@ -271,28 +555,37 @@ func SearchVacancies(storage *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		data, next := page.Cut(data, limit, func(v s.VacancySearchResult) s.PageCursor { return s.PageCursor{ID: v.ID, Rank: v.Rank} })
 		ctx.JSON(200, gin.H{
 			"Status":      "Ok!",
 			"VacancyInfo": data,
+			"NextCursor":  next,
 		})
 	}
 }
 
 // @Summary Получение списка ВИДИМЫХ вакансий по 'странично'
 // @Description Позволяет получить всю основную информацию про все ВИДИМЫЕ вакансии, которые у есть, но в ограниченном количестве. Page - номер страницы. PerPage - сколько вакансий вмещается на странице.
+// @Description Если Page не передан, то выдача идёт по курсору: Cursor - NextCursor из предыдущего ответа, Limit - сколько вакансий вернуть. Такой способ не пропускает и не дублирует вакансии, когда добавляются новые.
 // @Tags Vacancy
 // @Accept json
 // @Produce json
-// @Param Page query int true "Номер страницы, которую нужно отобразить"
-// @Param PerPage query int true "Кол-во вакансий, в соответствии с которым нужно вернуть их"
-// @Success 200 {object} s.VacanciesByLimitResponse "Возвращает статус 'Ok!' и массив всех данных вакансий"
+// @Param Page query int false "Номер страницы, которую нужно отобразить"
+// @Param PerPage query int false "Кол-во вакансий, в соответствии с которым нужно вернуть их"
+// @Param Cursor query string false "Курсор следующей страницы (NextCursor из предыдущего ответа). Используется, только если не передан Page"
+// @Param Limit query int false "Кол-во вакансий на странице при выдаче по курсору. По умолчанию 20, максимум 100"
+// @Success 200 {object} s.VacanciesByLimitResponse "Возвращает статус 'Ok!' и массив всех данных вакансий. При выдаче по курсору также возвращается курсор следующей страницы"
 // @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
 // @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
 // @Router /vac [get]
 func GetVacancyWithLimit(storage *sqlx.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		tx := ctx.MustGet("tx").(*sqlx.Tx)
-		page, err := strconv.Atoi(ctx.Query("Page"))
+		if ctx.Query("Page") == "" {
+			getVacancyByCursor(ctx, tx)
+			return
+		}
+		pageNum, err := strconv.Atoi(ctx.Query("Page"))
 		if err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{
 				"Status": "Err",
@@ -311,7 +604,7 @@ func GetVacancyWithLimit(storage *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
-		data, err := sqlp.GetVacancyLimit(tx, page, perpage)
+		data, err := sqlp.GetVacancyLimit(tx, pageNum, perpage)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"Status": "Err",
@@ -327,6 +620,34 @@ func GetVacancyWithLimit(storage *sqlx.DB) gin.HandlerFunc {
 	}
 }
 
+// getVacancyByCursor отдаёт видимые вакансии по курсору (для /vac без параметра Page)
+func getVacancyByCursor(ctx *gin.Context, tx *sqlx.Tx) {
+	after, limit, err := page.FromQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"Status": "Err",
+			"Info":   "Ошибка при попытке получить параметры страницы (Cursor, Limit)! проверьте их и попробуйте снова",
+			"Error":  err.Error(),
+		})
+		return
+	}
+	data, err := sqlp.GetVacancyLimitAfterID(tx, after.ID, limit+1)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"Status": "Err",
+			"Info":   "Ошибка в SQL файле для получения данных о вакансиях",
+			"Error":  err.Error(),
+		})
+		return
+	}
+	data, next := page.Cut(data, limit, func(v s.VacancyData_Limit) s.PageCursor { return s.PageCursor{ID: v.ID} })
+	ctx.JSON(200, gin.H{
+		"Status":      "Ok!",
+		"VacancyInfo": data,
+		"NextCursor":  next,
+	})
+}
+
 // @Summary Получение списка вакансий по 'странично' по ВРЕМЕНИ
 // @Description Позволяет получить всю основную информацию про все вакансии, которые у есть, но в ограниченном количестве. Limit - кол-во вакансий, которое нужно вернуть. CreatedAt - время, после которого будет идти отсчёт limit.
 // @Tags Vacancy
@@ -425,6 +746,14 @@ func DeleteVacancy(storage *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		if err := notify.VacancyDeleted(tx, vac_id); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при отправке события об удалении вакансии",
+				"Error":  err.Error(),
+			})
+			return
+		}
 		ctx.JSON(200, gin.H{
 			"Status": "Ok!",
 			"Info":   "Успешно удалили данные!",
@@ -433,7 +762,7 @@ func DeleteVacancy(storage *sqlx.DB) gin.HandlerFunc {
 }
 
 // @Summary Добавить новую вакансию
-// @Description Позволяет добавлять новую вакансию в систему. В ответе клиент получит данные вакансии и работодателя. Доступ имеют роли Employee и ADMIN
+// @Description Позволяет добавлять новую вакансию в систему. Вакансия сразу публикуется, а если передан Draft = true, то сохраняется черновиком (состояние draft). Если включена модерация и статус работодателя не доверенный, то вместо публикации вакансия попадает в очередь модерации (состояние moderation). Тип занятости, график и формат работы передаются ID из справочников /employment, /schedule и /format, навыки - списком названий из справочника /skills (неизвестные названия отклоняются). В ответе клиент получит данные вакансии и работодателя. Доступ имеют роли Employee и ADMIN
 // @Security ApiKeyAuth
 // @Tags Vacancy
 // @Accept json
@@ -481,7 +810,7 @@ func PostNewVacancy(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
-		if req.Email == "" || req.VacancyName == "" || req.PhoneNumber == "" || req.Price <= 0 || req.About == "" || req.ExperienceId <= 0 || req.Location == "" {
+		if req.Email == "" || req.VacancyName == "" || req.PhoneNumber == "" || req.About == "" || req.ExperienceId <= 0 || req.Location == "" {
 			ctx.JSON(http.StatusBadRequest, gin.H{
 				"Status": "Err",
 				"Info":   "Вы не передали все необходимые данные! Пожалуйста перепроверьте данные, которые вы передаете в Body запроса и попробуйте снова!",
@@ -489,6 +818,9 @@ func PostNewVacancy(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		if !checkSalary(ctx, tx, &req.Salary) || !checkAttributes(ctx, tx, &req.VacancyAttributesInput) {
+			return
+		}
 		employee, err := sqlp.GetEmployeeByID(tx, emp_id)
 		if err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{
@@ -498,7 +830,19 @@ func PostNewVacancy(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
-		data, err := sqlp.PostNewVacancy(tx, req, emp_id)
+		state := lifecycle.Draft
+		if !req.Draft {
+			state, err = publishState(tx, emp_id)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{
+					"Status": "Err",
+					"Info":   "Ошибка в SQL файле при проверке статуса работодателя",
+					"Error":  err.Error(),
+				})
+				return
+			}
+		}
+		data, err := sqlp.PostNewVacancy(tx, req, emp_id, state)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"Status": "Err",
@@ -507,6 +851,14 @@ func PostNewVacancy(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		if _, err := sqlp.AddVacancyRevision(tx, data.ID, role, emp_id); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле при сохранении редакции вакансии",
+				"Error":  err.Error(),
+			})
+			return
+		}
 		ctx.JSON(200, gin.H{
 			"Status":       "Ok!",
 			"VacancyInfo":  data,
@@ -516,12 +868,14 @@ func PostNewVacancy(storag *sqlx.DB) gin.HandlerFunc {
 }
 
 // @Summary Данные вакансии по ID
-// @Description Позволяет получить все данные вакансии по её ID. Если да, то какой у неё статус.
+// @Description Позволяет получить все данные вакансии по её ID. Токен не обязателен: всем доступны только опубликованные вакансии, черновики, вакансии на модерации, закрытые и архивные видят только работодатель-владелец и ADMIN.
+// @Security ApiKeyAuth
 // @Tags Vacancy
 // @Accept json
 // @Produce json
 // @Param VacancyID query int true "ID вакансии, о которой хотите получить данные"
 // @Success 200 {object} s.ResponseInfoByVacancy "Возвращает информацию о вакансии"
+// @Header 200 {string} ETag "Версия данных, которую нужно передать в If-Match при обновлении"
 // @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
 // @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
 // @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
@@ -540,6 +894,10 @@ func GetVacancyInfoByID(storage *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 		data, err := sqlp.GetVacancyInfoByID(tx, vac_id)
+		if err == nil && data.State != lifecycle.Published && !canSeeUnpublished(ctx, data.Employer.ID) {
+			// неопубликованная вакансия для остальных выглядит так же, как несуществующая
+			err = sql.ErrNoRows
+		}
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusBadRequest, gin.H{
 				"Status": "Err",
@@ -556,6 +914,7 @@ func GetVacancyInfoByID(storage *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
+		etag.Set(ctx, data.Version)
 		ctx.JSON(200, gin.H{
 			"VacancyInfo": data,
 			"Status":      "Ok!",
@@ -564,6 +923,19 @@ func GetVacancyInfoByID(storage *sqlx.DB) gin.HandlerFunc {
 	}
 }
 
+// canSeeUnpublished - неопубликованную вакансию видят только ADMIN и работодатель, которому она принадлежит
+func canSeeUnpublished(ctx *gin.Context, empID int) bool {
+	role, ok := get.GetUserRoleFromContext(ctx)
+	if !ok {
+		return false
+	}
+	if role == "ADMIN" {
+		return true
+	}
+	uid, ok := get.GetUserIDFromContext(ctx)
+	return ok && role == "employee" && uid == empID
+}
+
 // @Summary Проверка отклика
 // @Description Позволяет узнать, откликнулся ли ранее пользователь на эту вакансию. Если да, то какой у неё статус.
 // @Security ApiKeyAuth
@@ -636,7 +1008,10 @@ func GetAllResponseByVacancy(storage *sqlx.DB) gin.HandlerFunc {
 // @Tags Vacancy
 // @Accept json
 // @Produce json
-// @Success 200 {array} s.ResponseAllVacancyByEmployee "Возвращает ID отклика, данные об этой вакансии, на которую откликнулся пользователь и статус отклика "
+// @Param Cursor query string false "Курсор следующей страницы (NextCursor из предыдущего ответа). Если не передан - отдаётся первая страница"
+// @Param Limit query int false "Кол-во записей на странице. По умолчанию 20, максимум 100"
+// @Param State query string false "Показать только вакансии в этом состоянии. Если не передан - вакансии в любом состоянии" Enums(draft, moderation, published, closed, archived)
+// @Success 200 {array} s.ResponseAllVacancyByEmployee "Возвращает ID работодателя, массив его вакансий и курсор следующей страницы (пустой, если страница последняя)"
 // @Failure 400 {array} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
 // @Failure 401 {array} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
 // @Failure 500 {array} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
@@ -667,7 +1042,24 @@ func GetAllVacanciesByEmployee(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
-		data, err := sqlp.GetAllVacanciesByEmployee(tx, emp_id)
+		after, limit, err := page.FromQuery(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при попытке получить параметры страницы (Cursor, Limit)! проверьте их и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		state := ctx.Query("State")
+		if state != "" && !lifecycle.IsState(state) {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Неизвестное состояние вакансии! Допустимые значения: " + strings.Join(lifecycle.States, ", "),
+			})
+			return
+		}
+		data, err := sqlp.GetAllVacanciesByEmployee(tx, emp_id, state, after.ID, limit+1)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"Status": "Err",
@@ -676,11 +1068,111 @@ func GetAllVacanciesByEmployee(storag *sqlx.DB) gin.HandlerFunc {
 			})
 			return
 		}
+		data, next := page.Cut(data, limit, func(v s.VacancyData) s.PageCursor { return s.PageCursor{ID: v.ID} })
 		ctx.JSON(200, gin.H{
 			"Status":        "Ok!",
 			"VacanciesInfo": data,
 			"EmployerID":    emp_id,
+			"NextCursor":    next,
+		})
+
+	}
+}
+
+// @Summary Список удалённых вакансий
+// @Description Позволяет получить список вакансий, которые были удалены, но ещё не стёрты из системы окончательно. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} s.ResponseDeletedEntities "Возвращает статус 'Ok!' и массив удалённых вакансий"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/vac/deleted [get]
+func GetDeletedVacancies(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "ADMIN" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		data, err := sqlp.GetDeletedVacancies(tx)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":      "Ok!",
+			"DeletedInfo": data,
 		})
+	}
+}
 
+// @Summary Восстановить удалённую вакансию
+// @Description Позволяет вернуть удалённую вакансию, пока она не была стёрта из системы окончательно. Доступ имеют только пользователи роли ADMIN
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Produce json
+// @Param VacancyID query int true "ID вакансии, которую нужно восстановить"
+// @Success 200 {object} s.StatusInfo "Возвращает статус и краткую информацию "
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /adm/vac/restore [patch]
+func RestoreVacancy(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		role, ok := get.GetUserRoleFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в попытке получить роль пользователя из заголовка токена",
+			})
+			return
+		}
+		if role != "ADMIN" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"Status": "Err",
+				"Info":   "У вас нету прав к этому функционалу!",
+			})
+			return
+		}
+		vac_id, err := strconv.Atoi(ctx.Query("VacancyID"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Error":  err.Error(),
+				"Info":   "Ошибка при попытке получить ID вакансии! проверьте его и попробуйте снова",
+			})
+			return
+		}
+		err = sqlp.RestoreVacancy(tx, vac_id)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+			"Info":   "Данные успешно восстановлены!",
+		})
 	}
 }
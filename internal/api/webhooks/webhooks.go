@@ -0,0 +1,272 @@
+package webhooks
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	s "main.go/internal/api/Struct"
+	"main.go/internal/api/get"
+	"main.go/internal/api/page"
+	sqlp "main.go/internal/storage/postSQL"
+	"main.go/internal/webhook"
+)
+
+func validate(req s.RequestWebhook) error {
+	if err := webhook.CheckURL(req.URL); err != nil {
+		return err
+	}
+	if len(req.Events) == 0 {
+		return fmt.Errorf("нужно указать хотя бы одно событие. Доступные: %s", strings.Join(webhook.Events, ", "))
+	}
+	for _, event := range req.Events {
+		if !webhook.IsEvent(event) {
+			return fmt.Errorf("неизвестное событие %s. Доступные: %s", event, strings.Join(webhook.Events, ", "))
+		}
+	}
+	return nil
+}
+
+// @Summary Вебхуки работодателя
+// @Description Позволяет получить все адреса, на которые работодатель получает события. Доступ имеет только роль employee
+// @Security ApiKeyAuth
+// @Tags Webhook
+// @Produce json
+// @Success 200 {object} s.ResponseWebhooks "Возвращает статус 'Ok!' и массив вебхуков"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /emp/webhook [get]
+func GetWebhooks(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		empID, ok := get.UserIDWithRole(ctx, "employee")
+		if !ok {
+			return
+		}
+		data, err := sqlp.GetWebhooksByEmployer(tx, empID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":   "Ok!",
+			"Webhooks": data,
+		})
+	}
+}
+
+// @Summary Добавить вебхук
+// @Description Позволяет зарегистрировать адрес, на который будут приходить POST запросы с событиями: response_created, response_withdrawn, vacancy_state_changed. Каждый запрос подписан заголовком X-Workall-Signature вида "t=<unix время>,v1=<hex HMAC-SHA256>", где подписывается строка "<unix время>.<тело запроса>" ключом Secret. Secret показывается только в ответе на этот запрос. Ответ получателя с кодом 2xx считается успешной доставкой, иначе доставка повторяется с увеличивающейся задержкой. Адрес не может вести на локальные, внутренние и служебные адреса (localhost, 10.0.0.0/8, 169.254.169.254 и т.п.), редиректы получателя не выполняются, тело его ответа не сохраняется - только код. Доступ имеет только роль employee
+// @Security ApiKeyAuth
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param Webhook body s.RequestWebhook true "Адрес и события, на которые нужно подписаться"
+// @Success 200 {object} s.ResponseNewWebhook "Возвращает статус 'Ok!', данные вебхука и ключ подписи"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /emp/webhook [post]
+func PostWebhook(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		empID, ok := get.UserIDWithRole(ctx, "employee")
+		if !ok {
+			return
+		}
+		var req s.RequestWebhook
+		if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в парсинге запроса! Пожалуйста перепроверьте ваши данные в Body запроса и попробуйте снова!",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		if err := validate(req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Неверные данные вебхука! Перепроверьте их и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		secret, err := webhook.NewSecret()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при создании ключа подписи",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, err := sqlp.CreateWebhook(tx, empID, req.URL, secret, req.Events)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":  "Ok!",
+			"Webhook": data,
+			"Secret":  secret,
+		})
+	}
+}
+
+// @Summary Удалить вебхук
+// @Description Позволяет удалить вебхук вместе с журналом его доставок. Доступ имеет только роль employee
+// @Security ApiKeyAuth
+// @Tags Webhook
+// @Produce json
+// @Param ID query int true "ID вебхука"
+// @Success 200 {object} s.StatusInfo "Возвращает статус 'Ok!' и небольшую информацию"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /emp/webhook [delete]
+func DeleteWebhook(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		empID, ok := get.UserIDWithRole(ctx, "employee")
+		if !ok {
+			return
+		}
+		id, err := strconv.Atoi(ctx.Query("ID"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при попытке получить ID вебхука! проверьте его и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		if err := sqlp.DeleteWebhook(tx, empID, id); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status": "Ok!",
+			"Info":   "Успешно удалили данные!",
+		})
+	}
+}
+
+// @Summary Журнал доставок вебхуков
+// @Description Позволяет получить доставки вебхуков работодателя от новых к старым: событие, данные, статус (pending, processing, failed, sent, dead), количество попыток, код ответа и последнюю ошибку. Доступ имеет только роль employee
+// @Security ApiKeyAuth
+// @Tags Webhook
+// @Produce json
+// @Param WebhookID query int false "ID вебхука. Если не передан - доставки всех вебхуков работодателя"
+// @Param Cursor query string false "Курсор следующей страницы (NextCursor из предыдущего ответа). Если не передан - отдаётся первая страница"
+// @Param Limit query int false "Кол-во записей на странице. По умолчанию 20, максимум 100"
+// @Success 200 {object} s.ResponseWebhookDeliveries "Возвращает статус 'Ok!', массив доставок и курсор следующей страницы (пустой, если страница последняя)"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /emp/webhook/deliveries [get]
+func GetDeliveries(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		empID, ok := get.UserIDWithRole(ctx, "employee")
+		if !ok {
+			return
+		}
+		webhookID := 0
+		if value := ctx.Query("WebhookID"); value != "" {
+			var err error
+			webhookID, err = strconv.Atoi(value)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{
+					"Status": "Err",
+					"Info":   "Ошибка при попытке получить ID вебхука! проверьте его и попробуйте снова",
+					"Error":  err.Error(),
+				})
+				return
+			}
+		}
+		before, limit, err := page.FromQuery(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при попытке получить параметры страницы (Cursor, Limit)! проверьте их и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, err := sqlp.GetWebhookDeliveries(tx, empID, webhookID, before.ID, limit+1)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		data, next := page.Cut(data, limit, func(d s.WebhookDelivery) s.PageCursor { return s.PageCursor{ID: d.ID} })
+		ctx.JSON(200, gin.H{
+			"Status":     "Ok!",
+			"Deliveries": data,
+			"NextCursor": next,
+		})
+	}
+}
+
+// @Summary Повторить доставку вебхука
+// @Description Позволяет вручную отправить событие из журнала ещё раз, например после того, как получатель починил свой сервер. Создаётся новая доставка с теми же данными, старая остаётся в журнале. Доступ имеет только роль employee
+// @Security ApiKeyAuth
+// @Tags Webhook
+// @Produce json
+// @Param DeliveryID query int true "ID доставки из журнала"
+// @Success 200 {object} s.StatusInfo "Возвращает статус 'Ok!' и ID новой доставки"
+// @Failure 400 {object} s.InfoError "Возвращает ошибку, если не удалось получить данные из запроса (токен или передача каких-либо других данных)"
+// @Failure 401 {object} s.InfoError "Возвращает ошибку, если у пользователя нету доступа к этому функционалу."
+// @Failure 500 {object} s.InfoError "Возвращает ошибку, если на сервере произошла непредвиденная ошибка."
+// @Router /emp/webhook/redeliver [post]
+func Redeliver(storage *sqlx.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tx := ctx.MustGet("tx").(*sqlx.Tx)
+		empID, ok := get.UserIDWithRole(ctx, "employee")
+		if !ok {
+			return
+		}
+		id, err := strconv.Atoi(ctx.Query("DeliveryID"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка при попытке получить ID доставки! проверьте его и попробуйте снова",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		newID, err := sqlp.RedeliverWebhook(tx, empID, id)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"Status": "Err",
+				"Info":   "Ошибка в SQL файле",
+				"Error":  err.Error(),
+			})
+			return
+		}
+		ctx.JSON(200, gin.H{
+			"Status":     "Ok!",
+			"Info":       "Доставка поставлена в очередь!",
+			"DeliveryID": newID,
+		})
+	}
+}
@@ -1,71 +1,159 @@
 package emailsender
 
 import (
-	"fmt"
 	"log"
 	"sync"
-
-	"github.com/go-mail/mail/v2"
+	"time"
 )
 
 type Mailer struct {
-	dialer    *mail.Dialer
+	transport Transport
 	sender    string
 	queue     chan Message
 	waitGroup sync.WaitGroup
+
+	idleTimeout time.Duration
+	rate        *rateLimiter
+	domains     *domainLimiter
 }
 
 type Message struct {
 	To      string
 	Subject string
 	Body    string
+	HTML    string
+
+	// result - куда воркер вернёт результат отправки
+	result chan error
 }
 
-func New(host string, port int, username, password, sender string, workers int) *Mailer {
-	dialer := mail.NewDialer(host, port, username, password)
+// Config - настройки пула воркеров Mailer
+type Config struct {
+	// Workers - количество горутин-воркеров, у каждого своё соединение с сервером
+	Workers int
+	// QueueSize - размер очереди писем
+	QueueSize int
+	// RatePerSecond - сколько писем в секунду можно отправить всеми воркерами вместе. 0 - без ограничения
+	RatePerSecond float64
+	// PerDomainLimit - сколько писем одновременно можно отправлять на один домен получателя. 0 - без ограничения
+	PerDomainLimit int
+	// IdleTimeout - через сколько простоя воркер закрывает соединение с сервером
+	IdleTimeout time.Duration
+}
+
+func New(transport Transport, sender string, cfg Config) *Mailer {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = 30 * time.Second
+	}
 
 	m := &Mailer{
-		dialer: dialer,
-		sender: sender,
-		queue:  make(chan Message, 100), // Буфер на 100 писем
+		transport:   transport,
+		sender:      sender,
+		queue:       make(chan Message, cfg.QueueSize),
+		idleTimeout: cfg.IdleTimeout,
+		rate:        newRateLimiter(cfg.RatePerSecond),
+		domains:     newDomainLimiter(cfg.PerDomainLimit),
 	}
 
 	// Запускаем N воркеров для обработки очереди
-	for i := 0; i < workers; i++ {
+	for i := 0; i < cfg.Workers; i++ {
 		go m.worker()
 	}
 
 	return m
 }
 
+// worker разбирает очередь. Если транспорт умеет держать соединение, то воркер держит своё соединение открытым
+// и закрывает его после idleTimeout простоя или после ошибки
 func (m *Mailer) worker() {
-	for msg := range m.queue {
-		if err := m.send(msg.To, msg.Subject, msg.Body); err != nil {
-			log.Printf("Failed to send email to %s: %v", msg.To, err)
-		} else {
-			log.Printf("\nSend to %s\n", msg.To)
+	var conn Connection
+	idle := time.NewTimer(m.idleTimeout)
+	defer idle.Stop()
+
+	for {
+		select {
+		case msg, ok := <-m.queue:
+			if !ok {
+				if conn != nil {
+					conn.Close()
+				}
+				return
+			}
+			err := m.deliver(&conn, msg)
+			if err != nil {
+				log.Printf("Failed to send email to %s: %v", msg.To, err)
+			} else {
+				log.Printf("\nSend to %s\n", msg.To)
+			}
+			if msg.result != nil {
+				msg.result <- err
+			}
+			m.waitGroup.Done()
+			idle.Reset(m.idleTimeout)
+		case <-idle.C:
+			if conn != nil {
+				conn.Close()
+				conn = nil
+			}
 		}
-		m.waitGroup.Done()
 	}
 }
 
-func (m *Mailer) send(to, subject, body string) error {
-	msg := mail.NewMessage()
-	msg.SetHeader("From", m.sender)
-	msg.SetHeader("To", to)
-	msg.SetHeader("Subject", subject)
-	msg.SetBody("text/plain", body)
+// deliver отправляет письмо с учётом ограничений по скорости и по доменам
+func (m *Mailer) deliver(conn *Connection, msg Message) error {
+	release := m.domains.Acquire(msg.To)
+	defer release()
+	m.rate.Wait()
 
-	if err := m.dialer.DialAndSend(msg); err != nil {
-		return fmt.Errorf("failed to send email: %w", err)
+	connector, ok := m.transport.(Connector)
+	if !ok {
+		return m.transport.Send(m.sender, msg)
+	}
+
+	reused := *conn != nil
+	if !reused {
+		c, err := connector.Connect()
+		if err != nil {
+			return err
+		}
+		*conn = c
+	}
+	err := (*conn).Send(m.sender, msg)
+	if err == nil {
+		return nil
+	}
+	(*conn).Close()
+	*conn = nil
+	if !reused {
+		return err
+	}
+
+	// сервер мог сам закрыть соединение, пока оно простаивало, поэтому пробуем ещё раз через новое
+	c, err := connector.Connect()
+	if err != nil {
+		return err
+	}
+	*conn = c
+	if err := c.Send(m.sender, msg); err != nil {
+		c.Close()
+		*conn = nil
+		return err
 	}
 	return nil
 }
 
-// SendAsync добавляет письмо в очередь и возвращает управление сразу
-func (m *Mailer) SendAsync(to, subject, body string) {
+// Send ставит письмо в очередь и ждёт, пока воркер его отправит. Возвращает ошибку отправки
+func (m *Mailer) Send(to, subject, body, html string) error {
+	result := make(chan error, 1)
 	m.waitGroup.Add(1)
-	m.queue <- Message{To: to, Subject: subject, Body: body}
+	m.queue <- Message{To: to, Subject: subject, Body: body, HTML: html, result: result}
+	return <-result
 }
 
 // Close ожидает завершения всех отправок и закрывает канал
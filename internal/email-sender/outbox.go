@@ -0,0 +1,140 @@
+package emailsender
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	s "main.go/internal/api/Struct"
+	"main.go/internal/retry"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+const (
+	outboxBatchSize = 20
+	outboxPollEvery = 5 * time.Second
+	// outboxLease - на сколько письма пачки закрепляются за отправщиком. Должно хватать на отправку всей пачки
+	outboxLease = 5 * time.Minute
+)
+
+// Outbox разбирает таблицу email_outbox и отправляет письма через Mailer.
+// Неудачные отправки повторяются с экспоненциальной задержкой, после maxAttempts попыток письмо уходит в статус 'dead'
+type Outbox struct {
+	storage     *sqlx.DB
+	mailer      *Mailer
+	maxAttempts int
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+func NewOutbox(storage *sqlx.DB, mailer *Mailer, maxAttempts int) *Outbox {
+	return &Outbox{
+		storage:     storage,
+		mailer:      mailer,
+		maxAttempts: maxAttempts,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Run опрашивает outbox, пока не будет вызван Close
+func (o *Outbox) Run() {
+	defer close(o.done)
+	ticker := time.NewTicker(outboxPollEvery)
+	defer ticker.Stop()
+
+	for {
+		// пока в очереди есть письма, которые пора отправить, разбираем её без пауз
+		for {
+			sent, err := o.dispatch()
+			if err != nil {
+				log.Printf("failed to dispatch email outbox: %v", err)
+				break
+			}
+			if sent < outboxBatchSize {
+				break
+			}
+		}
+		select {
+		case <-o.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close останавливает опрос и дожидается окончания текущей пачки писем
+func (o *Outbox) Close() {
+	close(o.stop)
+	<-o.done
+}
+
+// dispatch забирает пачку писем короткой транзакцией, отправляет их без открытой транзакции
+// и записывает результат каждого письма отдельной транзакцией
+func (o *Outbox) dispatch() (int, error) {
+	var emails []s.OutboxEmail
+	err := o.inTx(func(tx *sqlx.Tx) error {
+		var err error
+		emails, err = sqlp.ClaimOutboxEmails(tx, outboxBatchSize, outboxLease)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	// письма пачки отправляются параллельно всеми воркерами Mailer
+	results := make([]error, len(emails))
+	var wg sync.WaitGroup
+	for i, email := range emails {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = o.mailer.Send(email.Recipient, email.Subject, email.Body, email.HTMLBody)
+		}()
+	}
+	wg.Wait()
+
+	for i, email := range emails {
+		// если записать результат не удалось, письмо вернётся в очередь, когда истечёт аренда
+		if err := o.inTx(func(tx *sqlx.Tx) error { return o.finish(tx, email, results[i]) }); err != nil {
+			return 0, err
+		}
+	}
+	return len(emails), nil
+}
+
+func (o *Outbox) finish(tx *sqlx.Tx, email s.OutboxEmail, sendErr error) error {
+	if sendErr == nil {
+		return sqlp.MarkOutboxEmailSent(tx, email.ID)
+	}
+	attempts := email.Attempts + 1
+	dead := attempts >= o.maxAttempts
+	if dead {
+		log.Printf("email %d to %s moved to dead letters after %d attempts: %v", email.ID, email.Recipient, attempts, sendErr)
+	} else {
+		log.Printf("failed to send email %d to %s (attempt %d): %v", email.ID, email.Recipient, attempts, sendErr)
+	}
+	return sqlp.MarkOutboxEmailFailed(tx, email.ID, sendErr.Error(), retry.Backoff(attempts), dead)
+}
+
+func (o *Outbox) inTx(fn func(tx *sqlx.Tx) error) error {
+	tx, err := o.storage.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Enqueue собирает письмо по шаблону name и кладёт его в outbox в транзакции запроса
+func Enqueue(tx *sqlx.Tx, to, name, locale string, data any) error {
+	email, err := Render(name, locale, data)
+	if err != nil {
+		return err
+	}
+	return sqlp.EnqueueEmail(tx, to, email.Subject, email.Text, email.HTML)
+}
@@ -0,0 +1,257 @@
+package emailsender
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+)
+
+// DefaultLocale - язык писем, если у шаблона нет варианта на запрошенном языке
+const DefaultLocale = "ru"
+
+// Каждый шаблон письма - это пара файлов templates/<язык>/<имя>.html и templates/<язык>/<имя>.txt.
+// В .txt определяются блоки "subject" и "content" (текстовая версия письма), в .html - блок "content".
+// Общая обёртка письма лежит в templates/layout.html и templates/layout.txt, а подпись и кнопка - в partials своего языка
+//
+//go:embed templates
+var templateFiles embed.FS
+
+// Email - готовое к отправке письмо: тема, HTML-версия и текстовая версия для почтовых клиентов без HTML
+type Email struct {
+	Subject string `json:"Subject"`
+	HTML    string `json:"HTML"`
+	Text    string `json:"Text"`
+}
+
+type emailTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// Данные для шаблонов писем
+type (
+	ConfirmEmailData struct {
+		Name string
+		Link string
+	}
+	PasswordResetData struct {
+		Link string
+	}
+	NewPasswordData struct {
+		Email    string
+		Password string
+	}
+	ResponseStatusData struct {
+		Name         string
+		VacancyName  string
+		EmployerName string
+		StatusName   string
+		Link         string
+	}
+	NewResponseData struct {
+		Name          string
+		CandidateName string
+		VacancyName   string
+		Link          string
+	}
+	// ResponseDigestData - сводка новых откликов за период. Period - hourly или daily
+	ResponseDigestData struct {
+		Name      string
+		Period    string
+		Total     int
+		Vacancies []DigestVacancy
+	}
+	DigestVacancy struct {
+		Name      string
+		Responses []DigestResponse
+	}
+	DigestResponse struct {
+		CandidateName string
+		Link          string
+	}
+	// SavedSearchData - новые вакансии по сохранённому поиску. More - подходящих вакансий больше, чем в письме
+	SavedSearchData struct {
+		Name            string
+		SearchName      string
+		Vacancies       []SearchVacancy
+		More            bool
+		UnsubscribeLink string
+	}
+	SearchVacancy struct {
+		Name         string
+		EmployerName string
+		Location     string
+		Salary       string
+		Link         string
+	}
+	// VacancyExpiringData - напоминание о скором окончании срока публикации вакансии
+	VacancyExpiringData struct {
+		Name        string
+		VacancyName string
+		ExpiresAt   string
+		RenewLink   string
+	}
+	// VacancyModerationData - решение модератора по вакансии
+	VacancyModerationData struct {
+		Name        string
+		VacancyName string
+		Approved    bool
+		Reason      string
+		Link        string
+	}
+)
+
+// samples - данные, на которых админ может посмотреть, как выглядит письмо
+var samples = map[string]any{
+	"confirm_email":  ConfirmEmailData{Name: "Иван Иванов", Link: "https://isp-workall.online/api/v1/user/confirm-email?Token=sample"},
+	"password_reset": PasswordResetData{Link: "https://isp-workall.online/api/v1/user/pr?Token=sample"},
+	"new_password":   NewPasswordData{Email: "ivanov@example.com", Password: "Sample-Passw0rd"},
+	"response_status": ResponseStatusData{
+		Name: "Иван Иванов", VacancyName: "Go-разработчик", EmployerName: "ООО «Ромашка»",
+		StatusName: "Приглашение на собеседование", Link: "https://workall-9eca6.web.app/",
+	},
+	"new_response": NewResponseData{
+		Name: "ООО «Ромашка»", CandidateName: "Иван Иванов", VacancyName: "Go-разработчик",
+		Link: "https://workall-9eca6.web.app/vacancy/1/responses/1",
+	},
+	"response_digest": ResponseDigestData{
+		Name: "ООО «Ромашка»", Period: "daily", Total: 3,
+		Vacancies: []DigestVacancy{
+			{Name: "Go-разработчик", Responses: []DigestResponse{
+				{CandidateName: "Иван Иванов", Link: "https://workall-9eca6.web.app/vacancy/1/responses/1"},
+				{CandidateName: "Пётр Петров", Link: "https://workall-9eca6.web.app/vacancy/1/responses/2"},
+			}},
+			{Name: "Тестировщик", Responses: []DigestResponse{
+				{CandidateName: "Анна Смирнова", Link: "https://workall-9eca6.web.app/vacancy/2/responses/3"},
+			}},
+		},
+	},
+	"saved_search": SavedSearchData{
+		Name: "Иван Иванов", SearchName: "Go в Москве",
+		Vacancies: []SearchVacancy{
+			{Name: "Go-разработчик", EmployerName: "ООО «Ромашка»", Location: "Москва", Salary: "от 250 000 RUB в месяц до вычета налогов", Link: "https://workall-9eca6.web.app/vacancy/1"},
+			{Name: "Backend-разработчик (Go)", EmployerName: "АО «Лютик»", Location: "Москва", Salary: "от 300 000 до 350 000 RUB в месяц на руки", Link: "https://workall-9eca6.web.app/vacancy/2"},
+		},
+		More:            true,
+		UnsubscribeLink: "https://isp-workall.online/api/v1/user/search/unsubscribe?Token=sample",
+	},
+	"vacancy_expiring": VacancyExpiringData{
+		Name: "ООО «Ромашка»", VacancyName: "Go-разработчик", ExpiresAt: "21.10.2026 18:00",
+		RenewLink: "https://workall-9eca6.web.app/vacancy/1/renew",
+	},
+	"vacancy_moderation": VacancyModerationData{
+		Name: "ООО «Ромашка»", VacancyName: "Go-разработчик", Approved: false,
+		Reason: "В описании нет информации о компании и обязанностях", Link: "https://workall-9eca6.web.app/vacancy/1",
+	},
+}
+
+// templates[язык][имя шаблона]
+var templates = mustLoadTemplates()
+
+var htmlFuncs = htmltemplate.FuncMap{
+	"button": func(url, text string) map[string]string {
+		return map[string]string{"URL": url, "Text": text}
+	},
+}
+
+func mustLoadTemplates() map[string]map[string]emailTemplate {
+	result := map[string]map[string]emailTemplate{}
+
+	htmlLayout := htmltemplate.Must(htmltemplate.New("layout.html").Funcs(htmlFuncs).ParseFS(templateFiles, "templates/layout.html"))
+	textLayout := texttemplate.Must(texttemplate.New("layout.txt").ParseFS(templateFiles, "templates/layout.txt"))
+
+	files, err := fs.Glob(templateFiles, "templates/*/*.txt")
+	if err != nil {
+		panic(err)
+	}
+	for _, file := range files {
+		locale := path.Base(path.Dir(file))
+		name := strings.TrimSuffix(path.Base(file), ".txt")
+		if name == "partials" {
+			continue
+		}
+		dir := "templates/" + locale + "/"
+
+		html := htmltemplate.Must(htmltemplate.Must(htmlLayout.Clone()).ParseFS(templateFiles, dir+"partials.html", dir+name+".html"))
+		text := texttemplate.Must(texttemplate.Must(textLayout.Clone()).ParseFS(templateFiles, dir+"partials.txt", file))
+		if text.Lookup("subject") == nil {
+			panic(fmt.Sprintf("в шаблоне письма %s нет блока subject", file))
+		}
+
+		if result[locale] == nil {
+			result[locale] = map[string]emailTemplate{}
+		}
+		result[locale][name] = emailTemplate{html: html, text: text}
+	}
+	return result
+}
+
+// Render собирает письмо name на языке locale. Если шаблона на этом языке нет, то используется DefaultLocale
+func Render(name, locale string, data any) (Email, error) {
+	var email Email
+
+	tmpl, ok := templates[locale][name]
+	if !ok {
+		tmpl, ok = templates[DefaultLocale][name]
+	}
+	if !ok {
+		return email, fmt.Errorf("шаблона письма %s не существует", name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return email, fmt.Errorf("ошибка при сборке темы письма %s: %w", name, err)
+	}
+	email.Subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := tmpl.text.ExecuteTemplate(&buf, "layout", data); err != nil {
+		return email, fmt.Errorf("ошибка при сборке текста письма %s: %w", name, err)
+	}
+	email.Text = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := tmpl.html.ExecuteTemplate(&buf, "layout", data); err != nil {
+		return email, fmt.Errorf("ошибка при сборке HTML письма %s: %w", name, err)
+	}
+	email.HTML = buf.String()
+
+	return email, nil
+}
+
+// RenderSample собирает письмо на тестовых данных, чтобы посмотреть, как оно выглядит
+func RenderSample(name, locale string) (Email, error) {
+	data, ok := samples[name]
+	if !ok {
+		return Email{}, fmt.Errorf("шаблона письма %s не существует. Доступные шаблоны: %s", name, strings.Join(TemplateNames(), ", "))
+	}
+	return Render(name, locale, data)
+}
+
+// TemplateNames возвращает имена всех шаблонов писем
+func TemplateNames() []string {
+	names := make([]string, 0, len(templates[DefaultLocale]))
+	for name := range templates[DefaultLocale] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LocaleFromHeader выбирает язык письма по заголовку Accept-Language
+func LocaleFromHeader(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+		tag = strings.SplitN(tag, "-", 2)[0]
+		if _, ok := templates[tag]; ok {
+			return tag
+		}
+	}
+	return DefaultLocale
+}
@@ -0,0 +1,122 @@
+package emailsender
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderSamples(t *testing.T) {
+	for _, locale := range []string{"ru", "en"} {
+		for _, name := range TemplateNames() {
+			t.Run(locale+"/"+name, func(t *testing.T) {
+				email, err := RenderSample(name, locale)
+				if err != nil {
+					t.Fatalf("RenderSample: %v", err)
+				}
+				if email.Subject == "" || email.Text == "" || email.HTML == "" {
+					t.Fatalf("письмо собрано не полностью: %+v", email)
+				}
+				if strings.Contains(email.Text, "<no value>") || strings.Contains(email.HTML, "<no value>") {
+					t.Errorf("в письме осталось поле без значения")
+				}
+			})
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name        string
+		template    string
+		locale      string
+		data        any
+		wantSubject string
+		wantText    string
+		wantHTML    string
+		notInHTML   string
+		wantErr     bool
+	}{
+		{
+			name:        "русский шаблон",
+			template:    "confirm_email",
+			locale:      "ru",
+			data:        ConfirmEmailData{Name: "Иван", Link: "https://example.com/confirm"},
+			wantSubject: "Подтверждения почты!",
+			wantText:    "Здравствуйте, Иван!",
+			wantHTML:    "https://example.com/confirm",
+		},
+		{
+			name:        "английский шаблон",
+			template:    "confirm_email",
+			locale:      "en",
+			data:        ConfirmEmailData{Name: "John", Link: "https://example.com/confirm"},
+			wantSubject: "Confirm your email",
+			wantText:    "Hello, John!",
+		},
+		{
+			name:        "неизвестный язык - шаблон по умолчанию",
+			template:    "confirm_email",
+			locale:      "de",
+			data:        ConfirmEmailData{Name: "Иван"},
+			wantSubject: "Подтверждения почты!",
+		},
+		{
+			name:      "HTML из данных экранируется",
+			template:  "confirm_email",
+			locale:    "ru",
+			data:      ConfirmEmailData{Name: "<script>alert(1)</script>"},
+			wantHTML:  "&lt;script&gt;",
+			notInHTML: "<script>",
+		},
+		{
+			name:     "неизвестный шаблон",
+			template: "missing",
+			locale:   "ru",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email, err := Render(tt.template, tt.locale, tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Render не вернул ошибку")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if tt.wantSubject != "" && email.Subject != tt.wantSubject {
+				t.Errorf("Subject = %q, ожидалось %q", email.Subject, tt.wantSubject)
+			}
+			if !strings.Contains(email.Text, tt.wantText) {
+				t.Errorf("в тексте нет %q:\n%s", tt.wantText, email.Text)
+			}
+			if !strings.Contains(email.HTML, tt.wantHTML) {
+				t.Errorf("в HTML нет %q:\n%s", tt.wantHTML, email.HTML)
+			}
+			if tt.notInHTML != "" && strings.Contains(email.HTML, tt.notInHTML) {
+				t.Errorf("в HTML есть %q:\n%s", tt.notInHTML, email.HTML)
+			}
+		})
+	}
+}
+
+func TestLocaleFromHeader(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", DefaultLocale},
+		{"en-US,en;q=0.9", "en"},
+		{"de-DE, en;q=0.8", "en"},
+		{"fr", DefaultLocale},
+		{"RU", "ru"},
+	}
+	for _, tt := range tests {
+		if got := LocaleFromHeader(tt.header); got != tt.want {
+			t.Errorf("LocaleFromHeader(%q) = %q, ожидалось %q", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package emailsender
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimiter пропускает не больше perSecond писем в секунду, равномерно распределяя их во времени.
+// Если perSecond <= 0, то ограничения нет
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(perSecond float64) *rateLimiter {
+	if perSecond <= 0 {
+		return &rateLimiter{}
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// Wait ждёт, пока можно будет отправить следующее письмо
+func (l *rateLimiter) Wait() {
+	if l.interval == 0 {
+		return
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	time.Sleep(wait)
+}
+
+// domainLimiter ограничивает количество одновременных отправок на один почтовый домен получателя,
+// чтобы крупные провайдеры (gmail, mail.ru, yandex) не начали нас притормаживать.
+// Если perDomain <= 0, то ограничения нет
+type domainLimiter struct {
+	mu        sync.Mutex
+	perDomain int
+	slots     map[string]chan struct{}
+}
+
+func newDomainLimiter(perDomain int) *domainLimiter {
+	return &domainLimiter{perDomain: perDomain, slots: map[string]chan struct{}{}}
+}
+
+// Acquire занимает слот домена получателя и возвращает функцию, которая его освобождает
+func (l *domainLimiter) Acquire(to string) func() {
+	if l.perDomain <= 0 {
+		return func() {}
+	}
+	domain := strings.ToLower(to[strings.LastIndex(to, "@")+1:])
+
+	l.mu.Lock()
+	slot, ok := l.slots[domain]
+	if !ok {
+		slot = make(chan struct{}, l.perDomain)
+		l.slots[domain] = slot
+	}
+	l.mu.Unlock()
+
+	slot <- struct{}{}
+	return func() { <-slot }
+}
@@ -0,0 +1,183 @@
+package emailsender
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-mail/mail/v2"
+)
+
+// Transport - способ доставки письма. Mailer собирает письмо и передаёт его транспорту,
+// а куда оно уйдёт (SMTP, файл, лог или память) решает конфигурация
+type Transport interface {
+	Send(from string, msg Message) error
+}
+
+// Connector - транспорт, который умеет держать открытое соединение и отправлять через него несколько писем подряд.
+// Воркеры Mailer держат своё соединение и не тратят время на подключение и авторизацию для каждого письма
+type Connector interface {
+	Transport
+	Connect() (Connection, error)
+}
+
+// Connection - открытое соединение транспорта
+type Connection interface {
+	Send(from string, msg Message) error
+	Close() error
+}
+
+// TransportConfig - настройки для выбора транспорта через NewTransport
+type TransportConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// Dir - папка для .eml файлов (транспорт file)
+	Dir string
+}
+
+// NewTransport создаёт транспорт по имени: smtp (по умолчанию), file, log или memory
+func NewTransport(kind string, cfg TransportConfig) (Transport, error) {
+	switch strings.ToLower(kind) {
+	case "", "smtp":
+		return NewSMTPTransport(cfg.Host, cfg.Port, cfg.Username, cfg.Password), nil
+	case "file":
+		return NewFileTransport(cfg.Dir)
+	case "log":
+		return LogTransport{}, nil
+	case "memory":
+		return NewMemoryTransport(), nil
+	}
+	return nil, fmt.Errorf("неизвестный транспорт для писем: %s. Доступные: smtp, file, log, memory", kind)
+}
+
+// buildMessage собирает письмо go-mail. Если у письма есть HTML-версия, то оно уходит как multipart/alternative,
+// где текстовая версия - запасной вариант для клиентов, которые не показывают HTML
+func buildMessage(from string, message Message) *mail.Message {
+	msg := mail.NewMessage()
+	msg.SetHeader("From", from)
+	msg.SetHeader("To", message.To)
+	msg.SetHeader("Subject", message.Subject)
+	msg.SetDateHeader("Date", time.Now())
+	msg.SetBody("text/plain", message.Body)
+	if message.HTML != "" {
+		msg.AddAlternative("text/html", message.HTML)
+	}
+	return msg
+}
+
+// SMTPTransport отправляет письма через SMTP сервер. На порту 465 используется SSL,
+// на остальных (например, у локального SMTP-ловца писем) - STARTTLS, если сервер его поддерживает
+type SMTPTransport struct {
+	dialer *mail.Dialer
+}
+
+func NewSMTPTransport(host string, port int, username, password string) *SMTPTransport {
+	return &SMTPTransport{dialer: mail.NewDialer(host, port, username, password)}
+}
+
+func (t *SMTPTransport) Send(from string, msg Message) error {
+	if err := t.dialer.DialAndSend(buildMessage(from, msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// Connect подключается к SMTP серверу и авторизуется. Соединение можно использовать для нескольких писем
+func (t *SMTPTransport) Connect() (Connection, error) {
+	conn, err := t.dialer.Dial()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	return smtpConnection{conn: conn}, nil
+}
+
+type smtpConnection struct {
+	conn mail.SendCloser
+}
+
+func (c smtpConnection) Send(from string, msg Message) error {
+	if err := mail.Send(c.conn, buildMessage(from, msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+func (c smtpConnection) Close() error {
+	return c.conn.Close()
+}
+
+// FileTransport складывает письма в папку в виде .eml файлов, которые открываются любым почтовым клиентом
+type FileTransport struct {
+	dir     string
+	counter atomic.Int64
+}
+
+func NewFileTransport(dir string) (*FileTransport, error) {
+	if dir == "" {
+		dir = "mail"
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("не удалось создать папку для писем %s: %w", dir, err)
+	}
+	return &FileTransport{dir: dir}, nil
+}
+
+func (t *FileTransport) Send(from string, msg Message) error {
+	name := fmt.Sprintf("%s-%04d.eml", time.Now().Format("20060102-150405.000"), t.counter.Add(1))
+	file, err := os.Create(filepath.Join(t.dir, name))
+	if err != nil {
+		return fmt.Errorf("failed to create email file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := buildMessage(from, msg).WriteTo(file); err != nil {
+		return fmt.Errorf("failed to write email file: %w", err)
+	}
+	return nil
+}
+
+// LogTransport ничего не отправляет, а только пишет письмо в лог
+type LogTransport struct{}
+
+func (LogTransport) Send(from string, msg Message) error {
+	log.Printf("email from %s to %s\nSubject: %s\n\n%s", from, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// MemoryTransport запоминает отправленные письма, чтобы в тестах можно было проверить, что и кому ушло
+type MemoryTransport struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{}
+}
+
+func (t *MemoryTransport) Send(from string, msg Message) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = append(t.messages, msg)
+	return nil
+}
+
+// Messages возвращает копию всех писем, отправленных через транспорт
+func (t *MemoryTransport) Messages() []Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Message(nil), t.messages...)
+}
+
+// Reset забывает все отправленные письма
+func (t *MemoryTransport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = nil
+}
@@ -0,0 +1,56 @@
+package emailsender
+
+import (
+	"testing"
+)
+
+func TestNewTransport(t *testing.T) {
+	tests := []struct {
+		kind    string
+		wantErr bool
+	}{
+		{kind: ""},
+		{kind: "smtp"},
+		{kind: "SMTP"},
+		{kind: "log"},
+		{kind: "memory"},
+		{kind: "file"},
+		{kind: "pigeon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			_, err := NewTransport(tt.kind, TransportConfig{Host: "localhost", Port: 25, Dir: t.TempDir()})
+			if tt.wantErr != (err != nil) {
+				t.Errorf("NewTransport(%q) вернул ошибку %v", tt.kind, err)
+			}
+		})
+	}
+}
+
+func TestMemoryTransport(t *testing.T) {
+	transport := NewMemoryTransport()
+	mailer := New(transport, "noreply@example.com", Config{})
+	defer mailer.Close()
+
+	for _, locale := range []string{"ru", "en"} {
+		for _, name := range TemplateNames() {
+			t.Run(locale+"/"+name, func(t *testing.T) {
+				email, err := RenderSample(name, locale)
+				if err != nil {
+					t.Fatalf("RenderSample: %v", err)
+				}
+				transport.Reset()
+				if err := mailer.Send("user@example.com", email.Subject, email.Text, email.HTML); err != nil {
+					t.Fatalf("Send: %v", err)
+				}
+				sent := transport.Messages()
+				if len(sent) != 1 {
+					t.Fatalf("отправлено %d писем, ожидалось 1", len(sent))
+				}
+				if sent[0].To != "user@example.com" || sent[0].Subject != email.Subject || sent[0].Body != email.Text || sent[0].HTML != email.HTML {
+					t.Errorf("отправлено не то письмо: %+v", sent[0])
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package events
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Типы событий, которые получают подключённые клиенты
+const (
+	TypeResponseCreated       = "response_created"
+	TypeResponseStatusChanged = "response_status_changed"
+	TypeVacancyStateChanged   = "vacancy_state_changed"
+	TypeVacancyDeleted        = "vacancy_deleted"
+	TypeNotification          = "notification"
+)
+
+// bufferSize - сколько событий может ждать отправки одному подключению.
+// Если клиент не успевает их забирать, новые события для него теряются, а остальные подписчики не ждут
+const bufferSize = 32
+
+type Event struct {
+	Type string `json:"Type"`
+	Data any    `json:"Data"`
+}
+
+// Hub - pub/sub внутри процесса. У каждого пользователя (роль + ID) свой набор подписок - по одной на открытую вкладку.
+// Работает в пределах одного экземпляра сервера
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan Event]struct{}
+	pending     map[*sqlx.Tx][]delivery
+}
+
+type delivery struct {
+	role  string
+	uid   int
+	event Event
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: map[string]map[chan Event]struct{}{},
+		pending:     map[*sqlx.Tx][]delivery{},
+	}
+}
+
+// Default - хаб, через который работает весь сервер
+var Default = NewHub()
+
+func key(role string, uid int) string {
+	return fmt.Sprintf("%s:%d", role, uid)
+}
+
+// Subscribe подписывает пользователя на его события. Возвращает канал событий и функцию для отписки
+func (h *Hub) Subscribe(role string, uid int) (<-chan Event, func()) {
+	ch := make(chan Event, bufferSize)
+	k := key(role, uid)
+
+	h.mu.Lock()
+	if h.subscribers[k] == nil {
+		h.subscribers[k] = map[chan Event]struct{}{}
+	}
+	h.subscribers[k][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subscribers[k], ch)
+		if len(h.subscribers[k]) == 0 {
+			delete(h.subscribers, k)
+		}
+	}
+}
+
+// Publish сразу отправляет событие всем подключениям пользователя
+func (h *Hub) Publish(role string, uid int, event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers[key(role, uid)] {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("event %s for %s:%d dropped: subscriber is too slow", event.Type, role, uid)
+		}
+	}
+}
+
+// PublishAfterCommit откладывает событие до коммита транзакции tx.
+// Так клиенты не узнают об изменениях, которые потом откатятся
+func (h *Hub) PublishAfterCommit(tx *sqlx.Tx, role string, uid int, event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pending[tx] = append(h.pending[tx], delivery{role: role, uid: uid, event: event})
+}
+
+// Commit отправляет события, отложенные до коммита tx. Вызывается после успешного коммита
+func (h *Hub) Commit(tx *sqlx.Tx) {
+	h.mu.Lock()
+	deliveries := h.pending[tx]
+	delete(h.pending, tx)
+	h.mu.Unlock()
+
+	for _, d := range deliveries {
+		h.Publish(d.role, d.uid, d.event)
+	}
+}
+
+// Discard забывает события, отложенные до коммита tx. Вызывается, если транзакция откатилась
+func (h *Hub) Discard(tx *sqlx.Tx) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.pending, tx)
+}
+
+func Subscribe(role string, uid int) (<-chan Event, func()) {
+	return Default.Subscribe(role, uid)
+}
+
+func Publish(role string, uid int, event Event) {
+	Default.Publish(role, uid, event)
+}
+
+func PublishAfterCommit(tx *sqlx.Tx, role string, uid int, event Event) {
+	Default.PublishAfterCommit(tx, role, uid, event)
+}
+
+func Commit(tx *sqlx.Tx) {
+	Default.Commit(tx)
+}
+
+func Discard(tx *sqlx.Tx) {
+	Default.Discard(tx)
+}
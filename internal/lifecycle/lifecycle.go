@@ -0,0 +1,79 @@
+package lifecycle
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Состояния вакансии
+const (
+	Draft      = "draft"      // черновик, видит только работодатель
+	Moderation = "moderation" // ждёт проверки администратором
+	Published  = "published"  // опубликована, видна всем и на неё можно откликнуться
+	Closed     = "closed"     // снята с публикации (например, позиция закрыта), можно опубликовать снова
+	Archived   = "archived"   // в архиве, изменить состояние больше нельзя
+)
+
+var States = []string{Draft, Moderation, Published, Closed, Archived}
+
+// employerTransitions - переходы, которые может сделать работодатель
+var employerTransitions = map[string][]string{
+	Draft:      {Moderation, Published, Archived},
+	Moderation: {Draft},
+	Published:  {Closed, Archived},
+	Closed:     {Published, Moderation, Archived},
+}
+
+// adminTransitions - переходы, которые может сделать только администратор (решение по модерации)
+var adminTransitions = map[string][]string{
+	Moderation: {Published},
+}
+
+// IsState проверяет, что такое состояние существует
+func IsState(state string) bool {
+	return slices.Contains(States, state)
+}
+
+// CanTransition проверяет, что вакансию можно перевести из from в to. Администратор может всё то же, что и работодатель,
+// и, кроме того, принимать решения по модерации
+func CanTransition(from, to string, admin bool) error {
+	if !IsState(to) {
+		return fmt.Errorf("неизвестное состояние %s", to)
+	}
+	if slices.Contains(employerTransitions[from], to) || (admin && slices.Contains(adminTransitions[from], to)) {
+		return nil
+	}
+	return fmt.Errorf("вакансию нельзя перевести из состояния %s в %s", from, to)
+}
+
+// ModerationEnabled - включена ли модерация вакансий (VACANCY_MODERATION=on). Если включена, то вакансии работодателей
+// без доверенного статуса при публикации и при существенном изменении попадают в состояние moderation
+func ModerationEnabled() bool {
+	switch strings.ToLower(os.Getenv("VACANCY_MODERATION")) {
+	case "on", "true", "1":
+		return true
+	}
+	return false
+}
+
+// Lifetime - на сколько публикуется вакансия (VACANCY_LIFETIME_DAYS, по умолчанию 30 дней)
+func Lifetime() time.Duration {
+	return envDays("VACANCY_LIFETIME_DAYS", 30)
+}
+
+// ReminderBefore - за сколько до окончания срока работодателю приходит напоминание (VACANCY_EXPIRY_REMINDER_DAYS, по умолчанию 3 дня)
+func ReminderBefore() time.Duration {
+	return envDays("VACANCY_EXPIRY_REMINDER_DAYS", 3)
+}
+
+func envDays(name string, def int) time.Duration {
+	days, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || days <= 0 {
+		days = def
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
@@ -0,0 +1,39 @@
+package lifecycle
+
+import "testing"
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		admin    bool
+		wantErr  bool
+	}{
+		{from: Draft, to: Published},
+		{from: Draft, to: Moderation},
+		{from: Draft, to: Archived},
+		{from: Moderation, to: Draft},
+		{from: Published, to: Closed},
+		{from: Published, to: Archived},
+		{from: Closed, to: Published},
+		{from: Closed, to: Archived},
+		// закрытая вакансия работодателя без доверенного статуса при повторной публикации уходит на модерацию
+		{from: Closed, to: Moderation},
+		// решение по модерации принимает только администратор
+		{from: Moderation, to: Published, wantErr: true},
+		{from: Moderation, to: Published, admin: true},
+		{from: Published, to: Draft, wantErr: true},
+		{from: Published, to: Draft, admin: true, wantErr: true},
+		{from: Archived, to: Published, wantErr: true},
+		{from: Archived, to: Draft, admin: true, wantErr: true},
+		{from: Draft, to: Draft, wantErr: true},
+		{from: Draft, to: "deleted", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			err := CanTransition(tt.from, tt.to, tt.admin)
+			if tt.wantErr != (err != nil) {
+				t.Errorf("CanTransition(%s, %s, admin=%v) = %v", tt.from, tt.to, tt.admin, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"github.com/jmoiron/sqlx"
+	s "main.go/internal/api/Struct"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+func contactPayload(request s.ContactRequest) ContactRequestPayload {
+	return ContactRequestPayload{
+		RequestID:     request.ID,
+		EmployerID:    request.EmployerID,
+		EmployerName:  request.EmployerName,
+		CandidateID:   request.CandidateID,
+		CandidateName: request.CandidateName,
+		ResumeID:      request.ResumeID,
+		Status:        request.Status,
+	}
+}
+
+// ContactRequested сообщает соискателю, что работодатель нашёл его резюме и просит открыть контакты
+func ContactRequested(tx *sqlx.Tx, requestID int) error {
+	request, err := sqlp.GetContactRequest(tx, requestID)
+	if err != nil {
+		return err
+	}
+	return addNotification(tx, RoleCandidate, request.CandidateID, TypeContactRequested, contactPayload(request))
+}
+
+// ContactAnswered сообщает работодателю, что соискатель принял или отклонил его запрос контактов
+func ContactAnswered(tx *sqlx.Tx, requestID int) error {
+	request, err := sqlp.GetContactRequest(tx, requestID)
+	if err != nil {
+		return err
+	}
+	return addNotification(tx, RoleEmployer, request.EmployerID, TypeContactAnswered, contactPayload(request))
+}
@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+	mailer "main.go/internal/email-sender"
+	"main.go/internal/lifecycle"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+// reminderBatch - сколько вакансий обрабатывается за одну транзакцию
+const reminderBatch = 50
+
+// renewURL - страница веб-клиента, на которой работодатель продлевает вакансию
+func renewURL(vacID int) string {
+	return fmt.Sprintf("%s/vacancy/%d/renew", frontendURL(), vacID)
+}
+
+// VacancyExpired сообщает работодателю и откликнувшимся соискателям, что срок вакансии закончился и её сняли с публикации
+func VacancyExpired(tx *sqlx.Tx, vacID int) error {
+	if err := VacancyStateChanged(tx, vacID, lifecycle.Published, lifecycle.Closed); err != nil {
+		return err
+	}
+	vacancy, err := sqlp.GetVacancyInfoByID(tx, vacID)
+	if err != nil {
+		return err
+	}
+	return addNotification(tx, RoleEmployer, vacancy.Employer.ID, TypeVacancyExpired, VacancyExpiredPayload{
+		VacancyID:   vacancy.ID,
+		VacancyName: vacancy.Name,
+	})
+}
+
+// SendExpiryReminders ставит в outbox напоминания работодателям, у вакансий которых скоро закончится срок публикации.
+// Напоминание о каждой публикации отправляется один раз. Возвращает количество поставленных писем
+func SendExpiryReminders(storage *sqlx.DB) (int, error) {
+	sent := 0
+	for {
+		n, err := sendReminderBatch(storage)
+		sent += n
+		if err != nil || n < reminderBatch {
+			return sent, err
+		}
+	}
+}
+
+func sendReminderBatch(storage *sqlx.DB) (int, error) {
+	tx, err := storage.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	vacancies, err := sqlp.ClaimExpiringVacancies(tx, lifecycle.ReminderBefore(), reminderBatch)
+	if err != nil {
+		return 0, err
+	}
+	for _, vac := range vacancies {
+		err := mailer.Enqueue(tx, vac.EmployerEmail, "vacancy_expiring", mailer.DefaultLocale, mailer.VacancyExpiringData{
+			Name:        vac.EmployerName,
+			VacancyName: vac.Name,
+			ExpiresAt:   vac.ExpiresAt.Format("02.01.2006 15:04"),
+			RenewLink:   renewURL(vac.ID),
+		})
+		if err != nil {
+			return 0, err
+		}
+		if err := sqlp.MarkExpiryReminded(tx, vac.ID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	if len(vacancies) > 0 {
+		log.Printf("queued %d vacancy expiry reminders", len(vacancies))
+	}
+	return len(vacancies), nil
+}
@@ -0,0 +1,28 @@
+package notify
+
+import (
+	"github.com/jmoiron/sqlx"
+	"main.go/internal/scheduler"
+)
+
+// RegisterJobs добавляет в планировщик периодические рассылки
+func RegisterJobs(sc *scheduler.Scheduler) {
+	sc.Register(scheduler.Job{
+		Name:        "response_digests",
+		Description: "Ставит в outbox сводки новых откликов работодателям, выбравшим режим hourly или daily",
+		Schedule:    "*/5 * * * *",
+		Run: func(storage *sqlx.DB) error {
+			_, err := SendResponseDigests(storage)
+			return err
+		},
+	})
+	sc.Register(scheduler.Job{
+		Name:        "saved_search_alerts",
+		Description: "Ставит в outbox письма с новыми вакансиями по сохранённым поискам соискателей",
+		Schedule:    "* * * * *",
+		Run: func(storage *sqlx.DB) error {
+			_, err := SendSavedSearchAlerts(storage)
+			return err
+		},
+	})
+}
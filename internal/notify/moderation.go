@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	mailer "main.go/internal/email-sender"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+// vacancyURL - страница вакансии в веб-клиенте
+func vacancyURL(vacID int) string {
+	return fmt.Sprintf("%s/vacancy/%d", frontendURL(), vacID)
+}
+
+// VacancyModerated сообщает работодателю о решении модератора внутри приложения и письмом
+func VacancyModerated(tx *sqlx.Tx, vacID int, approved bool, reason string) error {
+	vacancy, err := sqlp.GetVacancyInfoByID(tx, vacID)
+	if err != nil {
+		return err
+	}
+	err = addNotification(tx, RoleEmployer, vacancy.Employer.ID, TypeVacancyModerated, VacancyModeratedPayload{
+		VacancyID:   vacancy.ID,
+		VacancyName: vacancy.Name,
+		Approved:    approved,
+		Reason:      reason,
+	})
+	if err != nil {
+		return err
+	}
+	return mailer.Enqueue(tx, vacancy.Employer.Email, "vacancy_moderation", mailer.DefaultLocale, mailer.VacancyModerationData{
+		Name:        vacancy.Employer.NameOrganization,
+		VacancyName: vacancy.Name,
+		Approved:    approved,
+		Reason:      reason,
+		Link:        vacancyURL(vacancy.ID),
+	})
+}
@@ -0,0 +1,319 @@
+package notify
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	s "main.go/internal/api/Struct"
+	mailer "main.go/internal/email-sender"
+	"main.go/internal/events"
+	sqlp "main.go/internal/storage/postSQL"
+	"main.go/internal/webhook"
+)
+
+// Получатели уведомлений внутри приложения - роли из токена
+const (
+	RoleCandidate = "candidate"
+	RoleEmployer  = "employee"
+)
+
+// Типы уведомлений внутри приложения
+const (
+	TypeResponseCreated       = "response_created"
+	TypeResponseStatusChanged = "response_status_changed"
+	TypeEmployerStatusChanged = "employer_status_changed"
+	TypeVacancyExpired        = "vacancy_expired"
+	TypeVacancyModerated      = "vacancy_moderated"
+	TypeContactRequested      = "contact_requested"
+	TypeContactAnswered       = "contact_answered"
+)
+
+// Payload уведомлений внутри приложения, событий для подключённых клиентов и вебхуков
+type (
+	ResponseCreatedPayload struct {
+		ResponseID    int    `json:"ResponseID"`
+		VacancyID     int    `json:"VacancyID"`
+		VacancyName   string `json:"VacancyName"`
+		CandidateID   int    `json:"CandidateID"`
+		CandidateName string `json:"CandidateName"`
+	}
+	ResponseStatusChangedPayload struct {
+		ResponseID   int    `json:"ResponseID"`
+		VacancyID    int    `json:"VacancyID"`
+		VacancyName  string `json:"VacancyName"`
+		EmployerName string `json:"EmployerName"`
+		StatusID     int    `json:"StatusID"`
+		StatusName   string `json:"StatusName"`
+	}
+	EmployerStatusChangedPayload struct {
+		StatusID   int    `json:"StatusID"`
+		StatusName string `json:"StatusName"`
+	}
+	VacancyStatePayload struct {
+		VacancyID int    `json:"VacancyID"`
+		From      string `json:"From"`
+		To        string `json:"To"`
+	}
+	VacancyExpiredPayload struct {
+		VacancyID   int    `json:"VacancyID"`
+		VacancyName string `json:"VacancyName"`
+	}
+	VacancyModeratedPayload struct {
+		VacancyID   int    `json:"VacancyID"`
+		VacancyName string `json:"VacancyName"`
+		Approved    bool   `json:"Approved"`
+		Reason      string `json:"Reason,omitempty"`
+	}
+	ContactRequestPayload struct {
+		RequestID     int    `json:"RequestID"`
+		EmployerID    int    `json:"EmployerID"`
+		EmployerName  string `json:"EmployerName"`
+		CandidateID   int    `json:"CandidateID"`
+		CandidateName string `json:"CandidateName"`
+		ResumeID      *int   `json:"ResumeID"`
+		Status        string `json:"Status"`
+	}
+	VacancyDeletedPayload struct {
+		VacancyID int `json:"VacancyID"`
+	}
+	ResponseWithdrawnPayload struct {
+		ResponseID  int `json:"ResponseID"`
+		VacancyID   int `json:"VacancyID"`
+		CandidateID int `json:"CandidateID"`
+	}
+)
+
+// frontendURL - адрес веб-клиента, на который ведут ссылки из уведомлений
+func frontendURL() string {
+	if url := os.Getenv("FRONTEND_URL"); url != "" {
+		return strings.TrimSuffix(url, "/")
+	}
+	return "https://workall-9eca6.web.app"
+}
+
+// responseURL - ссылка на отклик в кабинете работодателя
+func responseURL(vacancyID, responseID int) string {
+	return fmt.Sprintf("%s/vacancy/%d/responses/%d", frontendURL(), vacancyID, responseID)
+}
+
+// ResponseStatusChanged уведомляет соискателя о том, что работодатель поменял статус его отклика.
+// Вызывается в транзакции запроса, поэтому письмо уйдёт, только если изменение статуса сохранится
+func ResponseStatusChanged(tx *sqlx.Tx, responseID, statusID int) error {
+	notice, err := sqlp.GetResponseNotice(tx, responseID)
+	if err != nil {
+		return err
+	}
+	status, err := sqlp.GetStatusByID(tx, statusID)
+	if err != nil {
+		return err
+	}
+
+	payload := ResponseStatusChangedPayload{
+		ResponseID:   notice.ID,
+		VacancyID:    notice.Vacancy.ID,
+		VacancyName:  notice.Vacancy.Name,
+		EmployerName: notice.Employer.NameOrganization,
+		StatusID:     status.ID,
+		StatusName:   status.Name,
+	}
+	events.PublishAfterCommit(tx, RoleCandidate, notice.Candidate.ID, events.Event{Type: events.TypeResponseStatusChanged, Data: payload})
+	if err := addNotification(tx, RoleCandidate, notice.Candidate.ID, TypeResponseStatusChanged, payload); err != nil {
+		return err
+	}
+	if !notice.Candidate.NotifyResponseStatus {
+		return nil
+	}
+
+	return mailer.Enqueue(tx, notice.Candidate.Email, "response_status", mailer.DefaultLocale, mailer.ResponseStatusData{
+		Name:         notice.Candidate.Name,
+		VacancyName:  notice.Vacancy.Name,
+		EmployerName: notice.Employer.NameOrganization,
+		StatusName:   status.Name,
+		Link:         frontendURL(),
+	})
+}
+
+// ResponseCreated уведомляет работодателя о новом отклике внутри приложения и письмом, если он выбрал письмо на каждый отклик.
+// При режимах hourly и daily отклик попадёт в сводку SendResponseDigests
+func ResponseCreated(tx *sqlx.Tx, responseID int) error {
+	notice, err := sqlp.GetResponseNotice(tx, responseID)
+	if err != nil {
+		return err
+	}
+
+	payload := ResponseCreatedPayload{
+		ResponseID:    notice.ID,
+		VacancyID:     notice.Vacancy.ID,
+		VacancyName:   notice.Vacancy.Name,
+		CandidateID:   notice.Candidate.ID,
+		CandidateName: notice.Candidate.Name,
+	}
+	events.PublishAfterCommit(tx, RoleEmployer, notice.Employer.ID, events.Event{Type: events.TypeResponseCreated, Data: payload})
+	if err := webhook.Enqueue(tx, notice.Employer.ID, webhook.EventResponseCreated, payload); err != nil {
+		return err
+	}
+	if err := addNotification(tx, RoleEmployer, notice.Employer.ID, TypeResponseCreated, payload); err != nil {
+		return err
+	}
+	if notice.Employer.NotifyNewResponse != "immediate" {
+		return nil
+	}
+
+	return mailer.Enqueue(tx, notice.Employer.Email, "new_response", mailer.DefaultLocale, mailer.NewResponseData{
+		Name:          notice.Employer.NameOrganization,
+		CandidateName: notice.Candidate.Name,
+		VacancyName:   notice.Vacancy.Name,
+		Link:          responseURL(notice.Vacancy.ID, notice.ID),
+	})
+}
+
+// EmployerStatusChanged сообщает работодателю, что администратор поменял его статус
+func EmployerStatusChanged(tx *sqlx.Tx, empID, statusID int) error {
+	status, err := sqlp.GetStatusByID(tx, statusID)
+	if err != nil {
+		return err
+	}
+	return addNotification(tx, RoleEmployer, empID, TypeEmployerStatusChanged, EmployerStatusChangedPayload{
+		StatusID:   status.ID,
+		StatusName: status.Name,
+	})
+}
+
+// ResponseWithdrawn сообщает вебхукам работодателя, что соискатель отозвал отклик
+func ResponseWithdrawn(tx *sqlx.Tx, responseID, vacID, candidateID int) error {
+	empID, _, err := sqlp.GetVacancyAudience(tx, vacID)
+	if err != nil {
+		return err
+	}
+	return webhook.Enqueue(tx, empID, webhook.EventResponseWithdrawn, ResponseWithdrawnPayload{
+		ResponseID:  responseID,
+		VacancyID:   vacID,
+		CandidateID: candidateID,
+	})
+}
+
+// VacancyStateChanged сообщает подключённым работодателю и откликнувшимся соискателям, что вакансия перешла в другое состояние
+// (например, её сняли с публикации), а также отправляет событие на вебхуки работодателя
+func VacancyStateChanged(tx *sqlx.Tx, vacID int, from, to string) error {
+	payload := VacancyStatePayload{VacancyID: vacID, From: from, To: to}
+	empID, err := publishToVacancyAudience(tx, vacID, events.Event{Type: events.TypeVacancyStateChanged, Data: payload})
+	if err != nil {
+		return err
+	}
+	return webhook.Enqueue(tx, empID, webhook.EventVacancyStateChanged, payload)
+}
+
+// VacancyDeleted сообщает подключённым работодателю и откликнувшимся соискателям, что вакансию удалили
+func VacancyDeleted(tx *sqlx.Tx, vacID int) error {
+	_, err := publishToVacancyAudience(tx, vacID, events.Event{
+		Type: events.TypeVacancyDeleted,
+		Data: VacancyDeletedPayload{VacancyID: vacID},
+	})
+	return err
+}
+
+// publishToVacancyAudience отправляет событие работодателю вакансии и откликнувшимся соискателям. Возвращает ID работодателя
+func publishToVacancyAudience(tx *sqlx.Tx, vacID int, event events.Event) (int, error) {
+	empID, candidates, err := sqlp.GetVacancyAudience(tx, vacID)
+	if err != nil {
+		return 0, err
+	}
+	events.PublishAfterCommit(tx, RoleEmployer, empID, event)
+	for _, uid := range candidates {
+		events.PublishAfterCommit(tx, RoleCandidate, uid, event)
+	}
+	return empID, nil
+}
+
+// addNotification сохраняет уведомление и после коммита отправляет его подключённым клиентам пользователя
+func addNotification(tx *sqlx.Tx, role string, uid int, notificationType string, payload any) error {
+	notification, err := sqlp.AddNotification(tx, role, uid, notificationType, payload)
+	if err != nil {
+		return err
+	}
+	events.PublishAfterCommit(tx, role, uid, events.Event{Type: events.TypeNotification, Data: notification})
+	return nil
+}
+
+// digestBatch - сколько работодателей обрабатывается за одну транзакцию
+const digestBatch = 50
+
+// SendResponseDigests ставит в outbox сводки новых откликов всем работодателям, у которых подошло время.
+// Отклики в письме сгруппированы по вакансиям. Возвращает количество поставленных писем
+func SendResponseDigests(storage *sqlx.DB) (int, error) {
+	sent := 0
+	for {
+		n, more, err := sendDigestBatch(storage)
+		sent += n
+		if err != nil || !more {
+			return sent, err
+		}
+	}
+}
+
+func sendDigestBatch(storage *sqlx.DB) (int, bool, error) {
+	tx, err := storage.Beginx()
+	if err != nil {
+		return 0, false, err
+	}
+	defer tx.Rollback()
+
+	employers, err := sqlp.ClaimDigestEmployers(tx, digestBatch)
+	if err != nil {
+		return 0, false, err
+	}
+
+	sent := 0
+	for _, emp := range employers {
+		responses, err := sqlp.GetResponsesForDigest(tx, emp.ID)
+		if err != nil {
+			return 0, false, err
+		}
+		var included []int
+		if len(responses) > 0 {
+			if err := mailer.Enqueue(tx, emp.Email, "response_digest", mailer.DefaultLocale, digestData(emp, responses)); err != nil {
+				return 0, false, err
+			}
+			sent++
+			for _, resp := range responses {
+				included = append(included, resp.ID)
+			}
+		}
+		if err := sqlp.MarkDigestSent(tx, emp.ID, included); err != nil {
+			return 0, false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, false, err
+	}
+	if sent > 0 {
+		log.Printf("queued %d response digests", sent)
+	}
+	return sent, len(employers) == digestBatch, nil
+}
+
+// digestData группирует отклики по вакансиям. Отклики приходят отсортированными по вакансии
+func digestData(emp s.DigestEmployer, responses []s.DigestResponse) mailer.ResponseDigestData {
+	data := mailer.ResponseDigestData{
+		Name:   emp.NameOrganization,
+		Period: emp.NotifyNewResponse,
+		Total:  len(responses),
+	}
+	lastVacancy := 0
+	for _, resp := range responses {
+		if resp.VacancyID != lastVacancy || len(data.Vacancies) == 0 {
+			data.Vacancies = append(data.Vacancies, mailer.DigestVacancy{Name: resp.VacancyName})
+			lastVacancy = resp.VacancyID
+		}
+		vac := &data.Vacancies[len(data.Vacancies)-1]
+		vac.Responses = append(vac.Responses, mailer.DigestResponse{
+			CandidateName: resp.CandidateName,
+			Link:          responseURL(resp.VacancyID, resp.ID),
+		})
+	}
+	return data
+}
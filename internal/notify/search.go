@@ -0,0 +1,142 @@
+package notify
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	s "main.go/internal/api/Struct"
+	mailer "main.go/internal/email-sender"
+	"main.go/internal/salary"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+const (
+	// searchBatch - сколько сохранённых поисков обрабатывается за одну транзакцию
+	searchBatch = 50
+	// searchAlertLimit - сколько вакансий показывается в одном письме
+	searchAlertLimit = 10
+)
+
+// apiURL - адрес API, на который ведут ссылки из писем, которые не требуют веб-клиента (например, отписка)
+func apiURL() string {
+	if value := os.Getenv("API_URL"); value != "" {
+		return strings.TrimSuffix(value, "/")
+	}
+	return "https://isp-workall.online/api/v1"
+}
+
+// NewUnsubscribeToken генерирует токен для ссылки отписки от писем по сохранённому поиску
+func NewUnsubscribeToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// SendSavedSearchAlerts находит новые вакансии по сохранённым поискам, у которых подошло время,
+// и ставит письма в outbox. Если новых вакансий нет, то письмо не отправляется. Возвращает количество писем
+func SendSavedSearchAlerts(storage *sqlx.DB) (int, error) {
+	sent := 0
+	for {
+		n, more, err := sendSearchBatch(storage)
+		sent += n
+		if err != nil || !more {
+			return sent, err
+		}
+	}
+}
+
+func sendSearchBatch(storage *sqlx.DB) (int, bool, error) {
+	tx, err := storage.Beginx()
+	if err != nil {
+		return 0, false, err
+	}
+	defer tx.Rollback()
+
+	searches, err := sqlp.ClaimDueSavedSearches(tx, searchBatch)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(searches) == 0 {
+		return 0, false, nil
+	}
+	sent := 0
+	for _, search := range searches {
+		vacancies, err := sqlp.GetVacanciesToFind(tx,
+			deref(search.ExpID), deref(search.Max), deref(search.Min), derefString(search.Text),
+			search.ExpID != nil, search.Max != nil, search.Min != nil, search.Text != nil && *search.Text != "",
+			s.VacancyAttributeFilter{}, &search.LastPublishedAt, s.PageCursor{}, searchAlertLimit+1)
+		if err != nil {
+			return 0, false, err
+		}
+
+		// вакансии идут от старых публикаций к новым, поэтому граница сдвигается до последней вакансии в письме.
+		// Вакансии, которые не поместились в письмо, попадут в следующее. Черновики и вакансии на модерации
+		// попадут в письмо, когда их опубликуют
+		watermark := search.LastPublishedAt
+		if len(vacancies) > 0 {
+			if err := mailer.Enqueue(tx, search.CandidateEmail, "saved_search", mailer.DefaultLocale, searchData(search, vacancies)); err != nil {
+				return 0, false, err
+			}
+			sent++
+			included := vacancies[:min(len(vacancies), searchAlertLimit)]
+			if last := included[len(included)-1]; last.PublishedAt != nil {
+				watermark = *last.PublishedAt
+			}
+		}
+		if err := sqlp.MarkSavedSearchRun(tx, search.ID, watermark); err != nil {
+			return 0, false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, false, err
+	}
+	if sent > 0 {
+		log.Printf("queued %d saved search alerts", sent)
+	}
+	return sent, len(searches) == searchBatch, nil
+}
+
+func searchData(search s.DueSavedSearch, vacancies []s.VacancySearchResult) mailer.SavedSearchData {
+	data := mailer.SavedSearchData{
+		Name:            search.CandidateName,
+		SearchName:      search.Name,
+		More:            len(vacancies) > searchAlertLimit,
+		UnsubscribeLink: fmt.Sprintf("%s/user/search/unsubscribe?Token=%s", apiURL(), url.QueryEscape(search.UnsubscribeToken)),
+	}
+	if data.More {
+		vacancies = vacancies[:searchAlertLimit]
+	}
+	for _, vac := range vacancies {
+		data.Vacancies = append(data.Vacancies, mailer.SearchVacancy{
+			Name:         vac.Name,
+			EmployerName: vac.Employer.NameOrganization,
+			Location:     vac.Location,
+			Salary:       salary.Format(vac.Salary, mailer.DefaultLocale),
+			Link:         vacancyURL(vac.ID),
+		})
+	}
+	return data
+}
+
+func deref(value *int) int {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
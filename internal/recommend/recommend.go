@@ -0,0 +1,47 @@
+package recommend
+
+import (
+	"fmt"
+	"strings"
+
+	s "main.go/internal/api/Struct"
+)
+
+// Вклад каждого признака в оценку вакансии, по которой сортируются рекомендации. Максимальная оценка - 100
+const (
+	// WeightExperience - требуемый опыт совпадает с опытом в одном из резюме
+	WeightExperience = 25
+	// WeightSkill - за каждый навык из резюме, который требуется в вакансии, но не больше MaxSkills навыков
+	WeightSkill = 10
+	MaxSkills   = 4
+	// WeightSalary - зарплата пересекается с ожиданиями соискателя
+	WeightSalary = 15
+	// WeightLocation - вакансия в городе из резюме или в городе, где соискатель уже искал работу
+	WeightLocation = 10
+	// WeightSimilar - формат работы, тип занятости или навыки как у вакансий, на которые соискатель откликался
+	WeightSimilar = 10
+)
+
+// Reasons объясняет соискателю, почему вакансия попала в рекомендации
+func Reasons(v s.VacancyRecommendation, profile s.RecommendationProfile) []string {
+	reasons := []string{}
+	if v.Match.Experience {
+		reasons = append(reasons, fmt.Sprintf("Требуемый опыт (%s) совпадает с опытом в вашем резюме", v.Experience.Name))
+	}
+	if len(v.Match.SkillNames) > 0 {
+		reasons = append(reasons, "Совпадают навыки из вашего резюме: "+strings.Join(v.Match.SkillNames, ", "))
+	}
+	if v.Match.Salary && profile.SalaryMin != nil {
+		reasons = append(reasons, fmt.Sprintf("Зарплата подходит под ваши ожидания от %d ₽ в месяц", *profile.SalaryMin))
+	}
+	if v.Match.Location {
+		reasons = append(reasons, fmt.Sprintf("Город %s указан в вашем резюме или в вакансиях, на которые вы откликались", v.Location))
+	}
+	if v.Match.Similar {
+		reasons = append(reasons, "Похожа на вакансии, на которые вы откликались")
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "Новая вакансия")
+	}
+	return reasons
+}
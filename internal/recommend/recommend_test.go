@@ -0,0 +1,70 @@
+package recommend
+
+import (
+	"reflect"
+	"testing"
+
+	s "main.go/internal/api/Struct"
+)
+
+func TestReasons(t *testing.T) {
+	salaryMin := 150000
+	vacancy := func(match s.RecommendationMatch) s.VacancyRecommendation {
+		var v s.VacancyRecommendation
+		v.Experience.Name = "От 1 до 3 лет"
+		v.Location = "Москва"
+		v.Match = match
+		return v
+	}
+	tests := []struct {
+		name    string
+		match   s.RecommendationMatch
+		profile s.RecommendationProfile
+		want    []string
+	}{
+		{
+			name: "ничего не совпало",
+			want: []string{"Новая вакансия"},
+		},
+		{
+			name:  "опыт",
+			match: s.RecommendationMatch{Experience: true},
+			want:  []string{"Требуемый опыт (От 1 до 3 лет) совпадает с опытом в вашем резюме"},
+		},
+		{
+			name:  "навыки",
+			match: s.RecommendationMatch{SkillNames: []string{"Go", "PostgreSQL"}},
+			want:  []string{"Совпадают навыки из вашего резюме: Go, PostgreSQL"},
+		},
+		{
+			name:    "зарплата",
+			match:   s.RecommendationMatch{Salary: true},
+			profile: s.RecommendationProfile{SalaryMin: &salaryMin},
+			want:    []string{"Зарплата подходит под ваши ожидания от 150000 ₽ в месяц"},
+		},
+		{
+			name:  "зарплата без ожиданий в профиле не объясняется",
+			match: s.RecommendationMatch{Salary: true},
+			want:  []string{"Новая вакансия"},
+		},
+		{
+			name:    "все признаки по порядку",
+			match:   s.RecommendationMatch{Experience: true, SkillNames: []string{"Go"}, Salary: true, Location: true, Similar: true},
+			profile: s.RecommendationProfile{SalaryMin: &salaryMin},
+			want: []string{
+				"Требуемый опыт (От 1 до 3 лет) совпадает с опытом в вашем резюме",
+				"Совпадают навыки из вашего резюме: Go",
+				"Зарплата подходит под ваши ожидания от 150000 ₽ в месяц",
+				"Город Москва указан в вашем резюме или в вакансиях, на которые вы откликались",
+				"Похожа на вакансии, на которые вы откликались",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Reasons(vacancy(tt.match), tt.profile); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reasons = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
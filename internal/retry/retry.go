@@ -0,0 +1,23 @@
+package retry
+
+import "time"
+
+const (
+	// BaseBackoff - задержка перед второй попыткой
+	BaseBackoff = 30 * time.Second
+	// MaxBackoff - больше этого между попытками не ждём
+	MaxBackoff = 6 * time.Hour
+)
+
+// Backoff - задержка перед следующей попыткой после attempts неудачных: 30с, 1м, 2м, 4м... но не больше MaxBackoff.
+// Общая для outbox писем и доставок вебхуков
+func Backoff(attempts int) time.Duration {
+	delay := BaseBackoff
+	for i := 1; i < attempts && delay < MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > MaxBackoff {
+		delay = MaxBackoff
+	}
+	return delay
+}
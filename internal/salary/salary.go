@@ -0,0 +1,146 @@
+package salary
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	s "main.go/internal/api/Struct"
+)
+
+// Периоды, за которые указывается зарплата
+const (
+	Month = "month"
+	Hour  = "hour"
+)
+
+// BaseCurrency - валюта, к которой приводятся зарплаты при поиске. Её курс в таблице currency_rates всегда 1
+const BaseCurrency = "RUB"
+
+// DefaultGross - зарплата без явного Gross считается указанной до вычета налогов, как и в колонке salary_gross по умолчанию
+const DefaultGross = true
+
+// HoursPerMonth - сколько рабочих часов в месяце при сравнении почасовой и помесячной оплаты (SALARY_HOURS_PER_MONTH, по умолчанию 168)
+func HoursPerMonth() int {
+	hours, err := strconv.Atoi(os.Getenv("SALARY_HOURS_PER_MONTH"))
+	if err != nil || hours <= 0 {
+		return 168
+	}
+	return hours
+}
+
+// TaxPercent - налог в процентах, по которому зарплата на руки пересчитывается в зарплату до вычета налогов
+// при сравнении зарплат (SALARY_TAX_PERCENT, по умолчанию 13)
+func TaxPercent() int {
+	percent, err := strconv.Atoi(os.Getenv("SALARY_TAX_PERCENT"))
+	if err != nil || percent < 0 || percent >= 100 {
+		return 13
+	}
+	return percent
+}
+
+// IsGross сообщает, указана ли зарплата до вычета налогов. Не указанный Gross равен DefaultGross
+func IsGross(sal s.Salary) bool {
+	if sal.Gross == nil {
+		return DefaultGross
+	}
+	return *sal.Gross
+}
+
+// Normalize проверяет зарплату из запроса и подставляет значения по умолчанию: валюту BaseCurrency, период Month и Gross = DefaultGross.
+// Наличие валюты в таблице курсов проверяется отдельно
+func Normalize(sal *s.Salary) error {
+	if sal.From != nil && *sal.From <= 0 {
+		return fmt.Errorf("нижняя граница зарплаты должна быть больше нуля")
+	}
+	if sal.To != nil && *sal.To <= 0 {
+		return fmt.Errorf("верхняя граница зарплаты должна быть больше нуля")
+	}
+	if sal.From != nil && sal.To != nil && *sal.From > *sal.To {
+		return fmt.Errorf("нижняя граница зарплаты больше верхней")
+	}
+	sal.Currency = strings.ToUpper(strings.TrimSpace(sal.Currency))
+	if sal.Currency == "" {
+		sal.Currency = BaseCurrency
+	}
+	if sal.Gross == nil {
+		gross := DefaultGross
+		sal.Gross = &gross
+	}
+	switch sal.Period {
+	case "":
+		sal.Period = Month
+	case Month, Hour:
+	default:
+		return fmt.Errorf("неизвестный период зарплаты %q, допустимы %s и %s", sal.Period, Month, Hour)
+	}
+	return nil
+}
+
+// Format возвращает зарплату для писем, например "от 80 000 до 120 000 RUB в месяц до вычета налогов"
+func Format(sal s.Salary, locale string) string {
+	if locale == "en" {
+		return formatEn(sal)
+	}
+	var amount string
+	switch {
+	case sal.From == nil && sal.To == nil:
+		return "по договорённости"
+	case sal.To == nil:
+		amount = "от " + group(*sal.From)
+	case sal.From == nil:
+		amount = "до " + group(*sal.To)
+	case *sal.From == *sal.To:
+		amount = group(*sal.From)
+	default:
+		amount = "от " + group(*sal.From) + " до " + group(*sal.To)
+	}
+	period := "в месяц"
+	if sal.Period == Hour {
+		period = "в час"
+	}
+	taxes := "на руки"
+	if IsGross(sal) {
+		taxes = "до вычета налогов"
+	}
+	return fmt.Sprintf("%s %s %s %s", amount, sal.Currency, period, taxes)
+}
+
+func formatEn(sal s.Salary) string {
+	var amount string
+	switch {
+	case sal.From == nil && sal.To == nil:
+		return "negotiable"
+	case sal.To == nil:
+		amount = "from " + group(*sal.From)
+	case sal.From == nil:
+		amount = "up to " + group(*sal.To)
+	case *sal.From == *sal.To:
+		amount = group(*sal.From)
+	default:
+		amount = group(*sal.From) + "–" + group(*sal.To)
+	}
+	period := "per month"
+	if sal.Period == Hour {
+		period = "per hour"
+	}
+	taxes := "net"
+	if IsGross(sal) {
+		taxes = "gross"
+	}
+	return fmt.Sprintf("%s %s %s, %s", amount, sal.Currency, period, taxes)
+}
+
+// group разбивает число на разряды: 120000 -> "120 000"
+func group(value int) string {
+	digits := strconv.Itoa(value)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
@@ -0,0 +1,103 @@
+package salary
+
+import (
+	"testing"
+
+	s "main.go/internal/api/Struct"
+)
+
+func intPtr(v int) *int { return &v }
+
+func boolPtr(v bool) *bool { return &v }
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      s.Salary
+		want    s.Salary
+		wantErr bool
+	}{
+		{
+			name: "значения по умолчанию",
+			in:   s.Salary{From: intPtr(100)},
+			want: s.Salary{From: intPtr(100), Currency: BaseCurrency, Gross: boolPtr(DefaultGross), Period: Month},
+		},
+		{
+			name: "валюта приводится к верхнему регистру",
+			in:   s.Salary{To: intPtr(10), Currency: " usd ", Gross: boolPtr(false), Period: Hour},
+			want: s.Salary{To: intPtr(10), Currency: "USD", Gross: boolPtr(false), Period: Hour},
+		},
+		{
+			name: "вилка из одного числа",
+			in:   s.Salary{From: intPtr(100), To: intPtr(100)},
+			want: s.Salary{From: intPtr(100), To: intPtr(100), Currency: BaseCurrency, Gross: boolPtr(DefaultGross), Period: Month},
+		},
+		{name: "нулевая нижняя граница", in: s.Salary{From: intPtr(0)}, wantErr: true},
+		{name: "отрицательная верхняя граница", in: s.Salary{To: intPtr(-1)}, wantErr: true},
+		{name: "нижняя граница больше верхней", in: s.Salary{From: intPtr(200), To: intPtr(100)}, wantErr: true},
+		{name: "неизвестный период", in: s.Salary{Period: "year"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.in
+			err := Normalize(&got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Normalize не вернул ошибку")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize: %v", err)
+			}
+			if got.Currency != tt.want.Currency || got.Period != tt.want.Period || IsGross(got) != IsGross(tt.want) || got.Gross == nil {
+				t.Errorf("Normalize = %+v, ожидалось %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		salary s.Salary
+		locale string
+		want   string
+	}{
+		{"без зарплаты", s.Salary{}, "ru", "по договорённости"},
+		{"вилка", s.Salary{From: intPtr(80000), To: intPtr(120000), Currency: "RUB", Period: Month}, "ru", "от 80 000 до 120 000 RUB в месяц до вычета налогов"},
+		{"только нижняя граница", s.Salary{From: intPtr(1500), Currency: "USD", Gross: boolPtr(false), Period: Month}, "ru", "от 1 500 USD в месяц на руки"},
+		{"только верхняя граница", s.Salary{To: intPtr(900), Currency: "RUB", Gross: boolPtr(true), Period: Hour}, "ru", "до 900 RUB в час до вычета налогов"},
+		{"одно число", s.Salary{From: intPtr(1000000), To: intPtr(1000000), Currency: "RUB", Period: Month}, "ru", "1 000 000 RUB в месяц до вычета налогов"},
+		{"en без зарплаты", s.Salary{}, "en", "negotiable"},
+		{"en вилка", s.Salary{From: intPtr(3000), To: intPtr(4000), Currency: "EUR", Gross: boolPtr(false), Period: Month}, "en", "3 000–4 000 EUR per month, net"},
+		{"en только верхняя граница", s.Salary{To: intPtr(50), Currency: "USD", Period: Hour}, "en", "up to 50 USD per hour, gross"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Format(tt.salary, tt.locale); got != tt.want {
+				t.Errorf("Format = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaxPercent(t *testing.T) {
+	tests := []struct {
+		env  string
+		want int
+	}{
+		{"", 13},
+		{"30", 30},
+		{"0", 0},
+		{"100", 13},
+		{"-5", 13},
+		{"abc", 13},
+	}
+	for _, tt := range tests {
+		t.Setenv("SALARY_TAX_PERCENT", tt.env)
+		if got := TaxPercent(); got != tt.want {
+			t.Errorf("TaxPercent() при SALARY_TAX_PERCENT=%q = %d, ожидалось %d", tt.env, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"пустое расписание", ""},
+		{"мало полей", "* * * *"},
+		{"много полей", "* * * * * *"},
+		{"минута вне диапазона", "60 * * * *"},
+		{"час вне диапазона", "0 24 * * *"},
+		{"нулевой день месяца", "0 0 0 * *"},
+		{"месяц вне диапазона", "0 0 1 13 *"},
+		{"день недели вне диапазона", "0 0 * * 8"},
+		{"обратный диапазон", "0 5-3 * * *"},
+		{"нулевой шаг", "*/0 * * * *"},
+		{"не число", "a * * * *"},
+		{"неизвестный макрос", "@yearly"},
+		{"неверный интервал", "@every soon"},
+		{"интервал меньше секунды", "@every 500ms"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.spec); err == nil {
+				t.Fatalf("Parse(%q) не вернул ошибку", tt.spec)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	// 2026-10-19 - понедельник
+	after := time.Date(2026, 10, 19, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 10, 19, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 19, 10, 15, 0, 0, time.UTC)},
+		{"0,30 * * * *", time.Date(2026, 10, 19, 10, 30, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2026, 10, 20, 3, 0, 0, 0, time.UTC)},
+		{"0 9-18/3 * * *", time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)},
+		{"0 3 * * 6", time.Date(2026, 10, 24, 3, 0, 0, 0, time.UTC)},
+		{"0 3 * * 0", time.Date(2026, 10, 25, 3, 0, 0, 0, time.UTC)},
+		{"0 3 * * 7", time.Date(2026, 10, 25, 3, 0, 0, 0, time.UTC)},
+		{"0 3 * * 1-5", time.Date(2026, 10, 20, 3, 0, 0, 0, time.UTC)},
+		// заданы и день месяца, и день недели - подходит любой из них
+		{"0 0 1 * 3", time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 10, 19, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 5m", time.Date(2026, 10, 19, 10, 10, 0, 0, time.UTC)},
+		{"@every 1h30m", time.Date(2026, 10, 19, 10, 30, 0, 0, time.UTC)},
+		// 31 февраля не бывает
+		{"0 0 31 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.spec, err)
+			}
+			if got := schedule.Next(after); !got.Equal(tt.want) {
+				t.Errorf("Next = %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}
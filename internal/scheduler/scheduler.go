@@ -0,0 +1,305 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	s "main.go/internal/api/Struct"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+
+	tickEvery = time.Second
+	// staleCheckEvery - как часто ищем запуски, оставшиеся в статусе running после падения любого экземпляра
+	staleCheckEvery = time.Minute
+)
+
+var (
+	ErrUnknownJob = errors.New("задача не найдена")
+	ErrJobRunning = errors.New("задача уже выполняется")
+)
+
+// Job - фоновая задача. Задачи регистрирует пакет, которому они принадлежат
+type Job struct {
+	Name        string
+	Description string
+	Schedule    string // формат описан у Parse
+	Run         func(storage *sqlx.DB) error
+}
+
+type entry struct {
+	job      Job
+	schedule Schedule
+	next     time.Time
+	running  bool
+}
+
+// Scheduler запускает зарегистрированные задачи по расписанию.
+// Если экземпляров сервера несколько, то задачу выполняет только один из них: одновременный запуск исключает
+// advisory lock в Postgres, а повторный запуск на то же время расписания - уникальная запись в истории job_runs
+type Scheduler struct {
+	storage  *sqlx.DB
+	instance string
+
+	mu      sync.Mutex
+	jobs    map[string]*entry
+	stop    chan struct{}
+	done    chan struct{}
+	running sync.WaitGroup
+}
+
+// New создаёт планировщик. Записи в истории запусков старше historyRetention удаляет встроенная задача purge_job_runs
+func New(storage *sqlx.DB, historyRetention time.Duration) *Scheduler {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	sc := &Scheduler{
+		storage:  storage,
+		instance: fmt.Sprintf("%s:%d", host, os.Getpid()),
+		jobs:     make(map[string]*entry),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	sc.Register(Job{
+		Name:        "purge_job_runs",
+		Description: "Удаляет старые записи из истории запусков задач",
+		Schedule:    "30 4 * * *",
+		Run: func(storage *sqlx.DB) error {
+			return sc.withTx(func(tx *sqlx.Tx) error {
+				_, err := sqlp.PurgeJobRuns(tx, time.Now().Add(-historyRetention))
+				return err
+			})
+		},
+	})
+	return sc
+}
+
+// Register добавляет задачу. Неверное расписание или повторное имя - ошибка в коде, поэтому паника
+func (sc *Scheduler) Register(job Job) {
+	schedule := MustParse(job.Schedule)
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if _, ok := sc.jobs[job.Name]; ok {
+		panic(fmt.Sprintf("задача %s уже зарегистрирована", job.Name))
+	}
+	sc.jobs[job.Name] = &entry{job: job, schedule: schedule, next: schedule.Next(time.Now())}
+}
+
+// Run запускает задачи по расписанию, пока не будет вызван Close
+func (sc *Scheduler) Run() {
+	defer close(sc.done)
+	sc.abortStale()
+
+	ticker := time.NewTicker(tickEvery)
+	defer ticker.Stop()
+	staleTicker := time.NewTicker(staleCheckEvery)
+	defer staleTicker.Stop()
+	for {
+		select {
+		case <-sc.stop:
+			return
+		case now := <-ticker.C:
+			sc.runDue(now)
+		case <-staleTicker.C:
+			sc.abortStale()
+		}
+	}
+}
+
+// abortStale закрывает запуски, которые остались в статусе running после падения или перезапуска любого экземпляра
+func (sc *Scheduler) abortStale() {
+	sc.mu.Lock()
+	locks := make(map[string]int64, len(sc.jobs))
+	for name := range sc.jobs {
+		locks[name] = lockKey(name)
+	}
+	sc.mu.Unlock()
+
+	var aborted int64
+	if err := sc.withTx(func(tx *sqlx.Tx) error {
+		var err error
+		aborted, err = sqlp.AbortJobRuns(tx, locks)
+		return err
+	}); err != nil {
+		log.Printf("failed to abort stale job runs: %v", err)
+		return
+	}
+	if aborted > 0 {
+		log.Printf("marked %d stale job runs as failed", aborted)
+	}
+}
+
+// Close перестаёт запускать новые задачи и дожидается окончания уже запущенных
+func (sc *Scheduler) Close() {
+	close(sc.stop)
+	<-sc.done
+	sc.running.Wait()
+}
+
+// Trigger запускает задачу вне расписания. Задача выполняется в фоне, результат пишется в историю запусков
+func (sc *Scheduler) Trigger(name string) error {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	e, ok := sc.jobs[name]
+	if !ok {
+		return ErrUnknownJob
+	}
+	if e.running {
+		return ErrJobRunning
+	}
+	sc.start(e, TriggerManual, nil)
+	return nil
+}
+
+// Jobs возвращает зарегистрированные задачи, отсортированные по имени
+func (sc *Scheduler) Jobs() []s.Job {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	result := make([]s.Job, 0, len(sc.jobs))
+	for _, e := range sc.jobs {
+		result = append(result, s.Job{
+			Name:        e.job.Name,
+			Description: e.job.Description,
+			Schedule:    e.job.Schedule,
+			NextRunAt:   e.next,
+			Running:     e.running,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func (sc *Scheduler) runDue(now time.Time) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for _, e := range sc.jobs {
+		// нулевое время - у расписания больше нет запусков (например, "0 0 31 2 *")
+		if e.next.IsZero() || now.Before(e.next) {
+			continue
+		}
+		slot := e.next
+		e.next = e.schedule.Next(now)
+		// если прошлый запуск ещё идёт, то этот пропускается, а не встаёт в очередь
+		if e.running {
+			continue
+		}
+		sc.start(e, TriggerSchedule, &slot)
+	}
+}
+
+// start вызывается под sc.mu
+func (sc *Scheduler) start(e *entry, trigger string, slot *time.Time) {
+	e.running = true
+	sc.running.Add(1)
+	go func() {
+		defer sc.running.Done()
+		defer func() {
+			sc.mu.Lock()
+			e.running = false
+			sc.mu.Unlock()
+		}()
+		if err := sc.execute(e.job, trigger, slot); err != nil {
+			log.Printf("job %s: %v", e.job.Name, err)
+		}
+	}()
+}
+
+// execute выполняет задачу под advisory lock и записывает запуск в историю
+func (sc *Scheduler) execute(job Job, trigger string, slot *time.Time) error {
+	ctx := context.Background()
+	// session-level lock живёт, пока живёт соединение, поэтому задача держит отдельное соединение до конца выполнения
+	conn, err := sc.storage.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection for lock: %w", err)
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.GetContext(ctx, &locked, "SELECT pg_try_advisory_lock($1)", lockKey(job.Name)); err != nil {
+		return fmt.Errorf("failed to take advisory lock: %w", err)
+	}
+	if !locked {
+		// по расписанию задачу уже выполняет другой экземпляр - это нормально. Ручной запуск отмечаем в истории
+		if trigger == TriggerManual {
+			return sc.withTx(func(tx *sqlx.Tx) error {
+				_, _, err := sqlp.StartJobRun(tx, job.Name, trigger, "skipped", sc.instance, nil)
+				return err
+			})
+		}
+		return nil
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockKey(job.Name))
+
+	var scheduledAt *time.Time
+	if slot != nil {
+		utc := slot.UTC()
+		scheduledAt = &utc
+	}
+	var runID int
+	var started bool
+	if err := sc.withTx(func(tx *sqlx.Tx) error {
+		runID, started, err = sqlp.StartJobRun(tx, job.Name, trigger, "running", sc.instance, scheduledAt)
+		return err
+	}); err != nil {
+		return err
+	}
+	// это время расписания уже отработал другой экземпляр
+	if !started {
+		return nil
+	}
+
+	runErr := safeRun(job, sc.storage)
+	status := "success"
+	var errText *string
+	if runErr != nil {
+		status = "failed"
+		text := runErr.Error()
+		errText = &text
+		log.Printf("job %s failed: %v", job.Name, runErr)
+	}
+	return sc.withTx(func(tx *sqlx.Tx) error {
+		return sqlp.FinishJobRun(tx, runID, status, errText)
+	})
+}
+
+// safeRun не даёт панике в задаче уронить сервер
+func safeRun(job Job, storage *sqlx.DB) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(storage)
+}
+
+func (sc *Scheduler) withTx(fn func(tx *sqlx.Tx) error) error {
+	tx, err := sc.storage.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// lockKey превращает имя задачи в ключ для pg_try_advisory_lock
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("scheduler:" + name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,52 @@
+package skill
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxPerItem - сколько навыков можно указать у одной вакансии или одного резюме
+	MaxPerItem = 30
+	// MaxLength - максимальная длина названия навыка или синонима в символах
+	MaxLength = 50
+)
+
+// Clean убирает лишние пробелы из названия навыка
+func Clean(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// CheckName проверяет название навыка или синонима, которое уже прошло через Clean
+func CheckName(name string) error {
+	if name == "" {
+		return fmt.Errorf("название навыка не может быть пустым")
+	}
+	if utf8.RuneCountInString(name) > MaxLength {
+		return fmt.Errorf("название навыка должно быть не длиннее %d символов", MaxLength)
+	}
+	return nil
+}
+
+// Normalize очищает список навыков из запроса: убирает пустые названия и повторы без учёта регистра.
+// Синонимы и неизвестные навыки разбираются при сохранении
+func Normalize(names []string) ([]string, error) {
+	result := make([]string, 0, len(names))
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = Clean(name)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		if err := CheckName(name); err != nil {
+			return nil, err
+		}
+		seen[strings.ToLower(name)] = true
+		result = append(result, name)
+	}
+	if len(result) > MaxPerItem {
+		return nil, fmt.Errorf("навыков может быть не больше %d", MaxPerItem)
+	}
+	return result, nil
+}
@@ -0,0 +1,45 @@
+package skill
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tooMany := make([]string, MaxPerItem+1)
+	for i := range tooMany {
+		tooMany[i] = "skill" + strings.Repeat("x", i)
+	}
+	tests := []struct {
+		name    string
+		in      []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "пустой список", in: nil, want: []string{}},
+		{name: "лишние пробелы", in: []string{"  Go ", "REST   API"}, want: []string{"Go", "REST API"}},
+		{name: "пустые названия пропускаются", in: []string{"", "   ", "SQL"}, want: []string{"SQL"}},
+		{name: "повторы без учёта регистра", in: []string{"Go", "go", " GO", "Docker"}, want: []string{"Go", "Docker"}},
+		{name: "длинное название", in: []string{strings.Repeat("я", MaxLength+1)}, wantErr: true},
+		{name: "название максимальной длины", in: []string{strings.Repeat("я", MaxLength)}, want: []string{strings.Repeat("я", MaxLength)}},
+		{name: "слишком много навыков", in: tooMany, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Normalize не вернул ошибку")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Normalize = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package sqlite
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -11,10 +13,19 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/jmoiron/sqlx"
 	s "main.go/internal/api/Struct"
+	"main.go/internal/lifecycle"
+	"main.go/internal/recommend"
+	"main.go/internal/salary"
 )
 
 var psql = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
+// ErrVersionMismatch возвращается, если запись успели изменить после того, как клиент получил её ETag
+var ErrVersionMismatch = errors.New("данные были изменены другим пользователем! Получите актуальную версию и попробуйте снова")
+
+// ErrNotFound возвращается, если обновляемой записи нет или она уже удалена
+var ErrNotFound = errors.New("запись не найдена")
+
 func GetStatusByName(storage *sqlx.Tx, name string) (s.GetStatus, error) {
 
 	var result s.GetStatus
@@ -90,7 +101,7 @@ func GetEmployeeLogin(storage *sqlx.Tx, email, password string) (s.SuccessEmploy
 	).
 		From("employer e").
 		Join("status s ON e.status_id = s.id").
-		Where(sq.Eq{"email": email, "password": password}).ToSql()
+		Where(sq.Eq{"email": email, "password": password, "e.deleted_at": nil}).ToSql()
 	if err != nil {
 		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
 	}
@@ -108,12 +119,12 @@ func GetEmployeeLogin(storage *sqlx.Tx, email, password string) (s.SuccessEmploy
 func GetEmployeeByID(storage *sqlx.Tx, emp_id int) (s.SuccessEmployer, error) {
 	var result s.SuccessEmployer
 	query, args, err := psql.Select(
-		"e.id", "e.name_organization", "e.phone_number", "e.email", "e.inn", "e.password", "e.created_at", "e.updated_at",
+		"e.id", "e.name_organization", "e.phone_number", "e.email", "e.inn", "e.password", "e.created_at", "e.updated_at", "e.version",
 		"s.id as \"status.id\"", "s.name as \"status.name\"", "s.created_at as \"status.created_at\"",
 	).
 		From("employer e").
 		Join("status s ON e.status_id = s.id").
-		Where(sq.Eq{"e.id": emp_id}).ToSql()
+		Where(sq.Eq{"e.id": emp_id, "e.deleted_at": nil}).ToSql()
 	if err != nil {
 		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
 	}
@@ -134,7 +145,7 @@ func GetEmployeeByEmail(storage *sqlx.Tx, email string) (s.SuccessEmployer, erro
 	).
 		From("employer e").
 		Join("status s ON e.status_id = s.id").
-		Where(sq.Eq{"email": email}).ToSql()
+		Where(sq.Eq{"email": email, "e.deleted_at": nil}).ToSql()
 	if err != nil {
 		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
 	}
@@ -150,7 +161,7 @@ func GetEmployeeByEmail(storage *sqlx.Tx, email string) (s.SuccessEmployer, erro
 func GetNumberOfVacancies(storage *sqlx.Tx) (int, error) {
 	var number int = -1
 
-	query, args, err := psql.Select("count(id)").From("vacancy").Where(sq.Eq{"is_visible": true}).ToSql()
+	query, args, err := psql.Select("count(id)").From("vacancy").Where(sq.Eq{"state": lifecycle.Published, "deleted_at": nil}).ToSql()
 
 	if err != nil {
 		return number, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
@@ -167,12 +178,18 @@ func GetVacancyByID(storage *sqlx.Tx, id int) (s.VacancyData, error) {
 	var result s.VacancyData
 
 	query, args, err := psql.Select(
-		"v.id", "v.name", "v.email", "v.price", "v.phone_number", "v.location", "v.about_work", "v.is_visible", "v.created_at", "v.updated_at",
+		"v.id", "v.name", "v.email", "v.phone_number", "v.location", "v.about_work", "v.state", "v.created_at", "v.updated_at",
+		"v.salary_from as \"salary.from\"", "v.salary_to as \"salary.to\"", "v.salary_currency as \"salary.currency\"", "v.salary_gross as \"salary.gross\"", "v.salary_period as \"salary.period\"",
+		vacancyEmploymentType, vacancySchedule, vacancyWorkFormat, vacancySkills,
+		"v.state_changed_at", "v.submitted_at", "v.published_at", "v.closed_at", "v.archived_at", "v.expires_at", "v.moderation_reason",
 		"e.id as \"experience.id\"", "e.name as \"experience.name\"", "e.created_at as \"experience.created_at\"",
 	).
 		From("vacancy v").
-		Join("experience e ON v.experience_id = e.id").Where(sq.Eq{
-		"v.id": id,
+		Join("experience e ON v.experience_id = e.id").
+		Join("employer em ON v.emp_id = em.id").Where(sq.Eq{
+		"v.id":          id,
+		"v.deleted_at":  nil,
+		"em.deleted_at": nil,
 	}).
 		ToSql()
 	if err != nil {
@@ -187,18 +204,28 @@ func GetVacancyByID(storage *sqlx.Tx, id int) (s.VacancyData, error) {
 	return result, nil
 }
 
-func GetAllVacanciesByEmployee(storage *sqlx.Tx, emp_id int) ([]s.VacancyData, error) {
+// GetAllVacanciesByEmployee возвращает вакансии работодателя. Пустой state - вакансии в любом состоянии
+func GetAllVacanciesByEmployee(storage *sqlx.Tx, emp_id int, state string, afterID, limit int) ([]s.VacancyData, error) {
 	var result []s.VacancyData
 
-	query, args, err := psql.Select(
-		"v.id", "v.name", "v.price", "v.email", "v.phone_number", "v.location", "v.about_work", "v.is_visible", "v.created_at", "v.updated_at",
+	builder := psql.Select(
+		"v.id", "v.name", "v.email", "v.phone_number", "v.location", "v.about_work", "v.state", "v.created_at", "v.updated_at",
+		"v.salary_from as \"salary.from\"", "v.salary_to as \"salary.to\"", "v.salary_currency as \"salary.currency\"", "v.salary_gross as \"salary.gross\"", "v.salary_period as \"salary.period\"",
+		vacancyEmploymentType, vacancySchedule, vacancyWorkFormat, vacancySkills,
+		"v.state_changed_at", "v.submitted_at", "v.published_at", "v.closed_at", "v.archived_at", "v.expires_at", "v.moderation_reason",
 		"e.id as \"experience.id\"", "e.name as \"experience.name\"", "e.created_at as \"experience.created_at\"",
 	).
 		From("vacancy v").
-		Join("experience e ON v.experience_id = e.id").Where(sq.Eq{
-		"v.emp_id": emp_id,
-	}).OrderBy("id ASC").
-		ToSql()
+		Join("experience e ON v.experience_id = e.id").
+		Join("employer em ON v.emp_id = em.id").Where(sq.Eq{
+		"v.emp_id":      emp_id,
+		"v.deleted_at":  nil,
+		"em.deleted_at": nil,
+	}).Where(sq.Gt{"v.id": afterID}).OrderBy("v.id ASC").Limit(uint64(limit))
+	if state != "" {
+		builder = builder.Where(sq.Eq{"v.state": state})
+	}
+	query, args, err := builder.ToSql()
 	if err != nil {
 		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
 	}
@@ -212,7 +239,9 @@ func GetAllVacanciesByEmployee(storage *sqlx.Tx, emp_id int) ([]s.VacancyData, e
 func GetVacancyInfoByID(storage *sqlx.Tx, vac_id int) (s.VacancyData_Limit, error) {
 	var result s.VacancyData_Limit
 	query, args, err := psql.Select(
-		"v.id", "v.name", "v.price", "v.email", "v.phone_number", "v.location", "v.about_work", "v.is_visible", "v.created_at", "v.updated_at",
+		"v.id", "v.name", "v.email", "v.phone_number", "v.location", "v.about_work", "v.state", "v.published_at", "v.expires_at", "v.created_at", "v.updated_at", "v.version",
+		"v.salary_from as \"salary.from\"", "v.salary_to as \"salary.to\"", "v.salary_currency as \"salary.currency\"", "v.salary_gross as \"salary.gross\"", "v.salary_period as \"salary.period\"",
+		vacancyEmploymentType, vacancySchedule, vacancyWorkFormat, vacancySkills,
 
 		"e.id as \"experience.id\"", "e.name as \"experience.name\"", "e.created_at as \"experience.created_at\"",
 
@@ -226,7 +255,7 @@ func GetVacancyInfoByID(storage *sqlx.Tx, vac_id int) (s.VacancyData_Limit, erro
 		Join("experience e ON v.experience_id = e.id").
 		Join("employer em ON v.emp_id = em.id").
 		Join("status s ON em.status_id = s.id").OrderBy("v.id ASC").
-		Where(sq.Eq{"v.id": vac_id}).ToSql()
+		Where(sq.Eq{"v.id": vac_id, "v.deleted_at": nil, "em.deleted_at": nil}).ToSql()
 	if err != nil {
 		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
 	}
@@ -242,7 +271,9 @@ func GetVacancyInfoByID(storage *sqlx.Tx, vac_id int) (s.VacancyData_Limit, erro
 func GetVacancyLimitByTimes(storage *sqlx.Tx, limit int, time time.Time) ([]s.VacancyData_Limit, error) {
 	var result []s.VacancyData_Limit
 	query, args, err := psql.Select(
-		"v.id", "v.name", "v.price", "v.email", "v.phone_number", "v.location", "v.about_work", "v.is_visible", "v.created_at", "v.updated_at",
+		"v.id", "v.name", "v.email", "v.phone_number", "v.location", "v.about_work", "v.state", "v.published_at", "v.expires_at", "v.created_at", "v.updated_at",
+		"v.salary_from as \"salary.from\"", "v.salary_to as \"salary.to\"", "v.salary_currency as \"salary.currency\"", "v.salary_gross as \"salary.gross\"", "v.salary_period as \"salary.period\"",
+		vacancyEmploymentType, vacancySchedule, vacancyWorkFormat, vacancySkills,
 
 		"e.id as \"experience.id\"", "e.name as \"experience.name\"", "e.created_at as \"experience.created_at\"",
 
@@ -256,7 +287,8 @@ func GetVacancyLimitByTimes(storage *sqlx.Tx, limit int, time time.Time) ([]s.Va
 		Join("experience e ON v.experience_id = e.id").
 		Join("employer em ON v.emp_id = em.id").
 		Join("status s ON em.status_id = s.id").OrderBy("v.id ASC").
-		Where(sq.Gt{"v.created_at": time}).Limit(uint64(limit)).ToSql()
+		Where(sq.Gt{"v.created_at": time}).
+		Where(sq.Eq{"v.state": lifecycle.Published, "v.deleted_at": nil, "em.deleted_at": nil}).Limit(uint64(limit)).ToSql()
 	if err != nil {
 		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
 	}
@@ -267,11 +299,15 @@ func GetVacancyLimitByTimes(storage *sqlx.Tx, limit int, time time.Time) ([]s.Va
 	return result, nil
 }
 
-func GetVacanciesToFind(storage *sqlx.Tx, ExpID, Max, Min int, Text string, IsExp, IsMax, IsMin, IsText bool) ([]s.VacancyData_Limit, error) {
-	var result []s.VacancyData_Limit
+// GetVacanciesToFind ищет видимые вакансии по фильтрам. Если publishedAfter != nil, то только вакансии, опубликованные
+// после него, от старых публикаций к новым (так сохранённые поиски находят вакансии, появившиеся после прошлого письма)
+func GetVacanciesToFind(storage *sqlx.Tx, ExpID, Max, Min int, Text string, IsExp, IsMax, IsMin, IsText bool, attrs s.VacancyAttributeFilter, publishedAfter *time.Time, after s.PageCursor, limit int) ([]s.VacancySearchResult, error) {
+	var result []s.VacancySearchResult
 
 	queryBuilder := psql.Select(
-		"v.id", "v.name", "v.price", "v.email", "v.phone_number", "v.location", "v.about_work", "v.is_visible", "v.created_at", "v.updated_at",
+		"v.id", "v.name", "v.email", "v.phone_number", "v.location", "v.about_work", "v.state", "v.published_at", "v.expires_at", "v.created_at", "v.updated_at",
+		"v.salary_from as \"salary.from\"", "v.salary_to as \"salary.to\"", "v.salary_currency as \"salary.currency\"", "v.salary_gross as \"salary.gross\"", "v.salary_period as \"salary.period\"",
+		vacancyEmploymentType, vacancySchedule, vacancyWorkFormat, vacancySkills,
 
 		"e.id as \"experience.id\"", "e.name as \"experience.name\"", "e.created_at as \"experience.created_at\"",
 
@@ -284,24 +320,34 @@ func GetVacanciesToFind(storage *sqlx.Tx, ExpID, Max, Min int, Text string, IsEx
 	).From("vacancy v").
 		Join("experience e ON v.experience_id = e.id").
 		Join("employer em ON v.emp_id = em.id").
-		Join("status s ON em.status_id = s.id").OrderBy("v.id ASC").
-		Where(sq.Eq{"v.is_visible": true})
+		Join("status s ON em.status_id = s.id").
+		Where(sq.Eq{"v.state": lifecycle.Published, "v.deleted_at": nil, "em.deleted_at": nil})
 
-	if IsExp {
-		queryBuilder = queryBuilder.Where(sq.Eq{"e.id": ExpID})
+	if publishedAfter != nil {
+		queryBuilder = queryBuilder.Where(sq.Gt{"v.published_at": *publishedAfter}).OrderBy("v.published_at ASC")
 	}
 
-	if IsMax && IsMin {
-		queryBuilder = queryBuilder.Where(sq.GtOrEq{"v.price": Min}).Where(sq.LtOrEq{"v.price": Max})
-	} else if IsMax {
-		queryBuilder = queryBuilder.Where(sq.LtOrEq{"v.price": Max})
-	} else if IsMin {
-		queryBuilder = queryBuilder.Where(sq.GtOrEq{"v.price": Min})
+	if IsText {
+		queryBuilder = withFullTextSearch(queryBuilder, Text)
+		// выдача отсортирована по (rank DESC, id ASC), поэтому и продолжаем её с этой же пары
+		if after.ID > 0 {
+			const rank = "ts_rank(v.search_vector, websearch_to_tsquery('russian', ?))"
+			queryBuilder = queryBuilder.Where("("+rank+" < ? OR ("+rank+" = ? AND v.id > ?))", Text, after.Rank, Text, after.Rank, after.ID)
+		}
+	} else {
+		queryBuilder = queryBuilder.Columns("0 as rank", "'' as \"highlight.name\"", "'' as \"highlight.about_work\"").
+			Where(sq.Gt{"v.id": after.ID}).OrderBy("v.id ASC")
 	}
+	queryBuilder = queryBuilder.Limit(uint64(limit))
 
-	if IsText {
-		queryBuilder = queryBuilder.Where(sq.ILike{"v.name": "%" + Text + "%"})
+	if IsExp {
+		queryBuilder = queryBuilder.Where(sq.Eq{"e.id": ExpID})
+	}
+
+	if IsMax || IsMin {
+		queryBuilder = queryBuilder.Where(salaryOverlaps(Min, Max, IsMin, IsMax))
 	}
+	queryBuilder = withAttributeFilter(queryBuilder, attrs)
 
 	query, args, err := queryBuilder.ToSql()
 
@@ -315,11 +361,181 @@ func GetVacanciesToFind(storage *sqlx.Tx, ExpID, Max, Min int, Text string, IsEx
 	return result, nil
 }
 
+// GetRecommendationProfile собирает то, что известно о соискателе для подбора вакансий: опыт, навыки, города и ожидаемую
+// зарплату из резюме и признаки вакансий, на которые он откликался. Если зарплата в резюме не указана, то берётся
+// из сохранённых поисков
+func GetRecommendationProfile(storage *sqlx.Tx, uid int) (s.RecommendationProfile, error) {
+	var profile s.RecommendationProfile
+
+	lists := []struct {
+		dest  *[]int
+		query string
+	}{
+		{&profile.ExperienceIDs, "SELECT DISTINCT experience_id FROM resume WHERE candidate_id = $1"},
+		{&profile.SkillIDs, "SELECT DISTINCT rs.skill_id FROM resume_skills rs JOIN resume r ON r.id = rs.resume_id WHERE r.candidate_id = $1"},
+		{&profile.SimilarSkillIDs, "SELECT DISTINCT vs.skill_id FROM response r JOIN vacancy_skills vs ON vs.vacancy_id = r.vacancy_id WHERE r.candidates_id = $1"},
+		{&profile.WorkFormatIDs, "SELECT DISTINCT v.work_format_id FROM response r JOIN vacancy v ON v.id = r.vacancy_id " +
+			"WHERE r.candidates_id = $1 AND v.work_format_id IS NOT NULL"},
+		{&profile.EmploymentTypeIDs, "SELECT DISTINCT v.employment_type_id FROM response r JOIN vacancy v ON v.id = r.vacancy_id " +
+			"WHERE r.candidates_id = $1 AND v.employment_type_id IS NOT NULL"},
+	}
+	for _, list := range lists {
+		if err := storage.Select(list.dest, list.query, uid); err != nil {
+			return profile, fmt.Errorf("ошибка в получении данных соискателя для рекомендаций! error: %s", err.Error())
+		}
+	}
+	const locations = "SELECT lower(trim(v.location)) FROM response r JOIN vacancy v ON v.id = r.vacancy_id " +
+		"WHERE r.candidates_id = $1 AND trim(v.location) <> '' " +
+		"UNION SELECT lower(trim(city)) FROM resume WHERE candidate_id = $1 AND trim(city) <> ''"
+	if err := storage.Select(&profile.Locations, locations, uid); err != nil {
+		return profile, fmt.Errorf("ошибка в получении данных соискателя для рекомендаций! error: %s", err.Error())
+	}
+	const salaryMin = `SELECT COALESCE(
+    (SELECT min(r.salary_expectation * cur.rate)::int FROM resume r JOIN currency_rates cur ON cur.code = r.salary_currency
+     WHERE r.candidate_id = $1),
+    (SELECT max(min_price) FROM saved_searches WHERE candidate_id = $1))`
+	if err := storage.Get(&profile.SalaryMin, salaryMin, uid); err != nil {
+		return profile, fmt.Errorf("ошибка в получении данных соискателя для рекомендаций! error: %s", err.Error())
+	}
+	return profile, nil
+}
+
+// GetRecommendedVacancies возвращает видимые вакансии, на которые соискатель uid ещё не откликался, по убыванию оценки
+// (веса признаков - в пакете recommend), а при равной оценке - сначала новые. Курсор - оценка (Rank) и ID последней вакансии
+func GetRecommendedVacancies(storage *sqlx.Tx, uid int, profile s.RecommendationProfile, after s.PageCursor, limit int) ([]s.VacancyRecommendation, error) {
+	var result []s.VacancyRecommendation
+
+	salaryMatch := sq.Sqlizer(sq.Expr("false"))
+	if profile.SalaryMin != nil {
+		salaryMatch = sq.Expr("COALESCE((?), false)", salaryOverlaps(*profile.SalaryMin, 0, true, false))
+	}
+	// подзапрос собирается через sq, а не psql: плейсхолдеры нумерует внешний запрос
+	matches := sq.Select(
+		"v.id", "v.name", "v.email", "v.phone_number", "v.location", "v.about_work", "v.state", "v.published_at", "v.expires_at", "v.created_at", "v.updated_at",
+		"v.salary_from as \"salary.from\"", "v.salary_to as \"salary.to\"", "v.salary_currency as \"salary.currency\"", "v.salary_gross as \"salary.gross\"", "v.salary_period as \"salary.period\"",
+		vacancyEmploymentType, vacancySchedule, vacancyWorkFormat, vacancySkills,
+
+		"e.id as \"experience.id\"", "e.name as \"experience.name\"", "e.created_at as \"experience.created_at\"",
+
+		"em.id as \"employer.id\"", "em.name_organization as \"employer.name_organization\"",
+		"em.phone_number as \"employer.phone_number\"", "em.email as \"employer.email\"",
+		"em.inn as \"employer.inn\"",
+		"em.created_at as \"employer.created_at\"", "em.updated_at as \"employer.updated_at\"",
+
+		"s.id as \"employer.status.id\"", "s.name as \"employer.status.name\"", "s.created_at as \"employer.status.created_at\"",
+	).
+		Column(sq.Alias(sq.Expr("COALESCE(v.experience_id = ANY(?::int[]), false)", profile.ExperienceIDs), "\"match.experience\"")).
+		Column(sq.Alias(sq.Expr("COALESCE((SELECT json_agg(sk.name ORDER BY sk.name) FROM vacancy_skills vs JOIN skills sk ON sk.id = vs.skill_id "+
+			"WHERE vs.vacancy_id = v.id AND vs.skill_id = ANY(?::int[])), '[]')", profile.SkillIDs), "\"match.skills\"")).
+		Column(sq.Alias(salaryMatch, "\"match.salary\"")).
+		Column(sq.Alias(sq.Expr("COALESCE(lower(trim(v.location)) = ANY(?::text[]), false)", profile.Locations), "\"match.location\"")).
+		Column(sq.Alias(sq.Expr("COALESCE(v.work_format_id = ANY(?::int[]) OR v.employment_type_id = ANY(?::int[]) OR "+
+			"EXISTS (SELECT 1 FROM vacancy_skills vs WHERE vs.vacancy_id = v.id AND vs.skill_id = ANY(?::int[])), false)",
+			profile.WorkFormatIDs, profile.EmploymentTypeIDs, profile.SimilarSkillIDs), "\"match.similar\"")).
+		From("vacancy v").
+		Join("experience e ON v.experience_id = e.id").
+		Join("employer em ON v.emp_id = em.id").
+		Join("status s ON em.status_id = s.id").
+		Where(sq.Eq{"v.state": lifecycle.Published, "v.deleted_at": nil, "em.deleted_at": nil}).
+		Where("NOT EXISTS (SELECT 1 FROM response r WHERE r.vacancy_id = v.id AND r.candidates_id = ?)", uid)
+
+	score := fmt.Sprintf("(CASE WHEN c.\"match.experience\" THEN %d ELSE 0 END + LEAST(json_array_length(c.\"match.skills\"), %d) * %d + "+
+		"CASE WHEN c.\"match.salary\" THEN %d ELSE 0 END + CASE WHEN c.\"match.location\" THEN %d ELSE 0 END + "+
+		"CASE WHEN c.\"match.similar\" THEN %d ELSE 0 END)",
+		recommend.WeightExperience, recommend.MaxSkills, recommend.WeightSkill,
+		recommend.WeightSalary, recommend.WeightLocation, recommend.WeightSimilar)
+
+	queryBuilder := psql.Select("c.*", score+" as score").FromSelect(matches, "c").
+		OrderBy("score DESC", "c.id DESC").Limit(uint64(limit))
+	if after.ID > 0 {
+		queryBuilder = queryBuilder.Where("("+score+" < ? OR ("+score+" = ? AND c.id < ?))", int(after.Rank), int(after.Rank), after.ID)
+	}
+
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка в маппинге данных вакансий! error: %s", err.Error())
+	}
+	for i := range result {
+		if err := json.Unmarshal(result[i].Match.Skills, &result[i].Match.SkillNames); err != nil {
+			return result, fmt.Errorf("ошибка в разборе совпавших навыков! error: %s", err.Error())
+		}
+	}
+	return result, nil
+}
+
+// withAttributeFilter добавляет фильтры по атрибутам вакансии. Внутри одного атрибута подходит любое из значений,
+// а навыки должны быть у вакансии все
+func withAttributeFilter(queryBuilder sq.SelectBuilder, attrs s.VacancyAttributeFilter) sq.SelectBuilder {
+	if len(attrs.EmploymentTypeIDs) > 0 {
+		queryBuilder = queryBuilder.Where(sq.Eq{"v.employment_type_id": attrs.EmploymentTypeIDs})
+	}
+	if len(attrs.ScheduleIDs) > 0 {
+		queryBuilder = queryBuilder.Where(sq.Eq{"v.schedule_id": attrs.ScheduleIDs})
+	}
+	if len(attrs.WorkFormatIDs) > 0 {
+		queryBuilder = queryBuilder.Where(sq.Eq{"v.work_format_id": attrs.WorkFormatIDs})
+	}
+	if len(attrs.SkillIDs) > 0 {
+		// подзапрос собирается через sq, а не psql: плейсхолдеры нумерует внешний запрос
+		skills := sq.Select("count(*)").From("vacancy_skills vs").
+			Where("vs.vacancy_id = v.id").Where(sq.Eq{"vs.skill_id": attrs.SkillIDs})
+		queryBuilder = queryBuilder.Where(sq.Expr("(?) = ?", skills, len(attrs.SkillIDs)))
+	}
+	return queryBuilder
+}
+
+// salaryOverlaps - условие, что вилка зарплаты вакансии пересекается с диапазоном [min, max]. Границы диапазона указываются
+// в базовой валюте за месяц до вычета налогов, а зарплата вакансии приводится к ним по курсу из currency_rates,
+// SALARY_HOURS_PER_MONTH и, если она указана на руки, SALARY_TAX_PERCENT.
+// Не указанная граница вилки считается открытой, вакансии без зарплаты под фильтр не попадают
+func salaryOverlaps(min, max int, isMin, isMax bool) sq.Sqlizer {
+	monthly := func(column string) string {
+		return fmt.Sprintf("%s * (SELECT cr.rate FROM currency_rates cr WHERE cr.code = v.salary_currency) * "+
+			"CASE v.salary_period WHEN '%s' THEN %d ELSE 1 END * "+
+			"CASE WHEN v.salary_gross THEN 1 ELSE 100.0 / (100 - %d) END",
+			column, salary.Hour, salary.HoursPerMonth(), salary.TaxPercent())
+	}
+	cond := sq.And{sq.Or{sq.NotEq{"v.salary_from": nil}, sq.NotEq{"v.salary_to": nil}}}
+	if isMin {
+		cond = append(cond, sq.Or{sq.Eq{"v.salary_to": nil}, sq.Expr(monthly("v.salary_to")+" >= ?", min)})
+	}
+	if isMax {
+		cond = append(cond, sq.Or{sq.Eq{"v.salary_from": nil}, sq.Expr(monthly("v.salary_from")+" <= ?", max)})
+	}
+	return cond
+}
+
+// htmlEscaped экранирует HTML в тексте колонки. Текст вакансии пишет работодатель, а подсветка отдаётся
+// как HTML с тегами <b>, поэтому его разметка не должна попасть в ответ как есть
+func htmlEscaped(column string) string {
+	return "replace(replace(replace(replace(replace(" + column +
+		", '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '\"', '&quot;'), '''', '&#39;')"
+}
+
+// withFullTextSearch добавляет к запросу полнотекстовый поиск по вакансии (название, описание, город и название работодателя)
+// с учётом словоформ, сортировку по релевантности и подсвеченные фрагменты текста
+func withFullTextSearch(queryBuilder sq.SelectBuilder, text string) sq.SelectBuilder {
+	const tsQuery = "websearch_to_tsquery('russian', ?)"
+	const headlineOptions = "'StartSel=<b>, StopSel=</b>, MaxFragments=2, MinWords=5, MaxWords=25'"
+
+	return queryBuilder.
+		Column(sq.Expr("ts_rank(v.search_vector, "+tsQuery+") as rank", text)).
+		Column(sq.Expr("ts_headline('russian', "+htmlEscaped("v.name")+", "+tsQuery+", 'HighlightAll=true') as \"highlight.name\"", text)).
+		Column(sq.Expr("ts_headline('russian', "+htmlEscaped("v.about_work")+", "+tsQuery+", "+headlineOptions+") as \"highlight.about_work\"", text)).
+		Where("v.search_vector @@ "+tsQuery, text).
+		OrderBy("rank DESC", "v.id ASC")
+}
+
 func GetVacanciesByFilter(storage *sqlx.Tx, ExpID, Max, Min int, IsExp, IsMax, IsMin bool) ([]s.VacancyData_Limit, error) {
 	var result []s.VacancyData_Limit
 
 	queryBuilder := psql.Select(
-		"v.id", "v.name", "v.price", "v.email", "v.phone_number", "v.location", "v.about_work", "v.is_visible", "v.created_at", "v.updated_at",
+		"v.id", "v.name", "v.email", "v.phone_number", "v.location", "v.about_work", "v.state", "v.published_at", "v.expires_at", "v.created_at", "v.updated_at",
+		"v.salary_from as \"salary.from\"", "v.salary_to as \"salary.to\"", "v.salary_currency as \"salary.currency\"", "v.salary_gross as \"salary.gross\"", "v.salary_period as \"salary.period\"",
+		vacancyEmploymentType, vacancySchedule, vacancyWorkFormat, vacancySkills,
 
 		"e.id as \"experience.id\"", "e.name as \"experience.name\"", "e.created_at as \"experience.created_at\"",
 
@@ -333,18 +549,14 @@ func GetVacanciesByFilter(storage *sqlx.Tx, ExpID, Max, Min int, IsExp, IsMax, I
 		Join("experience e ON v.experience_id = e.id").
 		Join("employer em ON v.emp_id = em.id").
 		Join("status s ON em.status_id = s.id").OrderBy("v.id ASC").
-		Where(sq.Eq{"v.is_visible": true})
+		Where(sq.Eq{"v.state": lifecycle.Published, "v.deleted_at": nil, "em.deleted_at": nil})
 
 	if IsExp {
 		queryBuilder = queryBuilder.Where(sq.Eq{"e.id": ExpID})
 	}
 
-	if IsMax && IsMin {
-		queryBuilder = queryBuilder.Where(sq.GtOrEq{"v.price": Min}).Where(sq.LtOrEq{"v.price": Max})
-	} else if IsMax {
-		queryBuilder = queryBuilder.Where(sq.LtOrEq{"v.price": Max})
-	} else if IsMin {
-		queryBuilder = queryBuilder.Where(sq.GtOrEq{"v.price": Min})
+	if IsMax || IsMin {
+		queryBuilder = queryBuilder.Where(salaryOverlaps(Min, Max, IsMin, IsMax))
 	}
 
 	query, args, err := queryBuilder.ToSql()
@@ -359,11 +571,13 @@ func GetVacanciesByFilter(storage *sqlx.Tx, ExpID, Max, Min int, IsExp, IsMax, I
 	return result, nil
 }
 
-func GetVacanciesBySearchingSubstring(storage *sqlx.Tx, substring string) ([]s.VacancyData_Limit, error) {
-	var result []s.VacancyData_Limit
+func GetVacanciesBySearchingSubstring(storage *sqlx.Tx, substring string) ([]s.VacancySearchResult, error) {
+	var result []s.VacancySearchResult
 
 	queryBuilder := psql.Select(
-		"v.id", "v.name", "v.price", "v.email", "v.phone_number", "v.location", "v.about_work", "v.is_visible", "v.created_at", "v.updated_at",
+		"v.id", "v.name", "v.email", "v.phone_number", "v.location", "v.about_work", "v.state", "v.published_at", "v.expires_at", "v.created_at", "v.updated_at",
+		"v.salary_from as \"salary.from\"", "v.salary_to as \"salary.to\"", "v.salary_currency as \"salary.currency\"", "v.salary_gross as \"salary.gross\"", "v.salary_period as \"salary.period\"",
+		vacancyEmploymentType, vacancySchedule, vacancyWorkFormat, vacancySkills,
 
 		"e.id as \"experience.id\"", "e.name as \"experience.name\"", "e.created_at as \"experience.created_at\"",
 
@@ -376,9 +590,9 @@ func GetVacanciesBySearchingSubstring(storage *sqlx.Tx, substring string) ([]s.V
 	).From("vacancy v").
 		Join("experience e ON v.experience_id = e.id").
 		Join("employer em ON v.emp_id = em.id").
-		Join("status s ON em.status_id = s.id").OrderBy("v.id ASC").
-		Where(sq.Eq{"v.is_visible": true}).
-		Where(sq.ILike{"v.name": "%" + substring + "%"})
+		Join("status s ON em.status_id = s.id").
+		Where(sq.Eq{"v.state": lifecycle.Published, "v.deleted_at": nil, "em.deleted_at": nil})
+	queryBuilder = withFullTextSearch(queryBuilder, substring)
 
 	query, args, err := queryBuilder.ToSql()
 
@@ -398,7 +612,45 @@ func GetVacancyLimit(storage *sqlx.Tx, page, perPage int) ([]s.VacancyData_Limit
 	offset := (page - 1) * perPage
 
 	queryBuilder := psql.Select(
-		"v.id", "v.name", "v.price", "v.email", "v.phone_number", "v.location", "v.about_work", "v.is_visible", "v.created_at", "v.updated_at",
+		"v.id", "v.name", "v.email", "v.phone_number", "v.location", "v.about_work", "v.state", "v.published_at", "v.expires_at", "v.created_at", "v.updated_at",
+		"v.salary_from as \"salary.from\"", "v.salary_to as \"salary.to\"", "v.salary_currency as \"salary.currency\"", "v.salary_gross as \"salary.gross\"", "v.salary_period as \"salary.period\"",
+		vacancyEmploymentType, vacancySchedule, vacancyWorkFormat, vacancySkills,
+
+		"e.id as \"experience.id\"", "e.name as \"experience.name\"", "e.created_at as \"experience.created_at\"",
+
+		"em.id as \"employer.id\"", "em.name_organization as \"employer.name_organization\"",
+		"em.phone_number as \"employer.phone_number\"", "em.email as \"employer.email\"",
+		"em.inn as \"employer.inn\"", "em.password as \"employer.password\"",
+		"em.created_at as \"employer.created_at\"", "em.updated_at as \"employer.updated_at\"",
+
+		"s.id as \"employer.status.id\"", "s.name as \"employer.status.name\"", "s.created_at as \"employer.status.created_at\"",
+	).From("vacancy v").
+		Join("experience e ON v.experience_id = e.id").
+		Join("employer em ON v.emp_id = em.id").
+		Join("status s ON em.status_id = s.id").OrderBy("v.id ASC").
+		Where(sq.Eq{"v.state": lifecycle.Published, "v.deleted_at": nil, "em.deleted_at": nil}).Limit(uint64(perPage)).Offset(uint64(offset))
+
+	query, args, err := queryBuilder.ToSql()
+
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	err = storage.Select(&result, query, args...)
+	if err != nil {
+		return result, fmt.Errorf("ошибка в маппинге данных вакансий! error: %s", err.Error())
+	}
+	return result, nil
+}
+
+// GetVacancyLimitAfterID - то же, что и GetVacancyLimit, но следующая страница начинается сразу после вакансии afterID,
+// поэтому выдача не съезжает, когда между запросами появляются новые вакансии
+func GetVacancyLimitAfterID(storage *sqlx.Tx, afterID, limit int) ([]s.VacancyData_Limit, error) {
+	var result []s.VacancyData_Limit
+
+	queryBuilder := psql.Select(
+		"v.id", "v.name", "v.email", "v.phone_number", "v.location", "v.about_work", "v.state", "v.published_at", "v.expires_at", "v.created_at", "v.updated_at",
+		"v.salary_from as \"salary.from\"", "v.salary_to as \"salary.to\"", "v.salary_currency as \"salary.currency\"", "v.salary_gross as \"salary.gross\"", "v.salary_period as \"salary.period\"",
+		vacancyEmploymentType, vacancySchedule, vacancyWorkFormat, vacancySkills,
 
 		"e.id as \"experience.id\"", "e.name as \"experience.name\"", "e.created_at as \"experience.created_at\"",
 
@@ -412,7 +664,8 @@ func GetVacancyLimit(storage *sqlx.Tx, page, perPage int) ([]s.VacancyData_Limit
 		Join("experience e ON v.experience_id = e.id").
 		Join("employer em ON v.emp_id = em.id").
 		Join("status s ON em.status_id = s.id").OrderBy("v.id ASC").
-		Where(sq.Eq{"v.is_visible": true}).Limit(uint64(perPage)).Offset(uint64(offset))
+		Where(sq.Eq{"v.state": lifecycle.Published, "v.deleted_at": nil, "em.deleted_at": nil}).
+		Where(sq.Gt{"v.id": afterID}).Limit(uint64(limit))
 
 	query, args, err := queryBuilder.ToSql()
 
@@ -426,30 +679,59 @@ func GetVacancyLimit(storage *sqlx.Tx, page, perPage int) ([]s.VacancyData_Limit
 	return result, nil
 }
 
-func PostNewVacancy(storage *sqlx.Tx, req s.ResponseVac, emp_id int) (s.VacancyData, error) {
+// PostNewVacancy создаёт вакансию в состоянии state: draft, moderation или published
+func PostNewVacancy(storage *sqlx.Tx, req s.ResponseVac, emp_id int, state string) (s.VacancyData, error) {
 	var result s.VacancyData
 
+	var submittedAt, publishedAt, expiresAt any
+	switch state {
+	case lifecycle.Moderation:
+		submittedAt = sq.Expr("now()")
+	case lifecycle.Published:
+		publishedAt, expiresAt = sq.Expr("now()"), expiresIn(lifecycle.Lifetime())
+	}
+
 	query, args, err := psql.Insert("vacancy").
 		Columns(
 			"emp_id",
 			"name",
-			"price",
+			"salary_from",
+			"salary_to",
+			"salary_currency",
+			"salary_gross",
+			"salary_period",
+			"employment_type_id",
+			"schedule_id",
+			"work_format_id",
 			"email",
 			"phone_number",
 			"location",
 			"experience_id",
 			"about_work",
-			"is_visible",
+			"state",
+			"submitted_at",
+			"published_at",
+			"expires_at",
 		).Values(
 		emp_id,
 		req.VacancyName,
-		req.Price,
+		req.Salary.From,
+		req.Salary.To,
+		req.Salary.Currency,
+		salary.IsGross(req.Salary),
+		req.Salary.Period,
+		req.EmploymentTypeID,
+		req.ScheduleID,
+		req.WorkFormatID,
 		req.Email,
 		req.PhoneNumber,
 		req.Location,
 		req.ExperienceId,
 		req.About,
-		req.IsVisible,
+		state,
+		submittedAt,
+		publishedAt,
+		expiresAt,
 	).Suffix("RETURNING id").
 		ToSql()
 	if err != nil {
@@ -460,6 +742,11 @@ func PostNewVacancy(storage *sqlx.Tx, req s.ResponseVac, emp_id int) (s.VacancyD
 	if err != nil {
 		return result, fmt.Errorf("ошибка в маппинге добавленных данных. error: %s", err.Error())
 	}
+	if req.Skills != nil {
+		if err := SetVacancySkills(storage, id, *req.Skills); err != nil {
+			return result, err
+		}
+	}
 
 	result, err = GetVacancyByID(storage, id)
 	if err != nil {
@@ -469,82 +756,63 @@ func PostNewVacancy(storage *sqlx.Tx, req s.ResponseVac, emp_id int) (s.VacancyD
 	return result, nil
 }
 
-func UpdateVacancyInfo(storage *sqlx.Tx, req s.VacancyPut, uid int) error {
+func UpdateVacancyInfo(storage *sqlx.Tx, req s.VacancyPut, uid, version int) (int, error) {
 
-	query, args, err := psql.Update("vacancy").
+	builder := psql.Update("vacancy").
 		Set("name", req.VacancyName).
-		Set("price", req.Price).
+		Set("salary_from", req.Salary.From).
+		Set("salary_to", req.Salary.To).
+		Set("salary_currency", req.Salary.Currency).
+		Set("salary_gross", salary.IsGross(req.Salary)).
+		Set("salary_period", req.Salary.Period).
+		Set("employment_type_id", req.EmploymentTypeID).
+		Set("schedule_id", req.ScheduleID).
+		Set("work_format_id", req.WorkFormatID).
 		Set("email", req.Email).
 		Set("phone_number", req.PhoneNumber).
 		Set("location", req.Location).
 		Set("experience_id", req.ExperienceId).
-		Set("about_work", req.About).
-		Set("is_visible", req.IsVisible).
-		Where(sq.Eq{"id": req.ID, "emp_id": uid}).
-		ToSql()
-	if err != nil {
-		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
-	}
-	result, err := storage.Exec(query, args...)
+		Set("about_work", req.About)
+
+	newVersion, err := updateVersioned(storage, builder, "vacancy", sq.Eq{"id": req.ID, "emp_id": uid, "deleted_at": nil}, version,
+		"данные не были обновлены, так как обновляемой вакансии не было найдено! Перепроверьте данные и попробуйте снова")
 	if err != nil {
-		return err
+		return newVersion, err
 	}
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return fmt.Errorf("данные не были обновлены, так как обновляемой вакансии не было найдено! Перепроверьте данные и попробуйте снова")
+	if req.Skills == nil {
+		return newVersion, nil
 	}
-
-	return nil
+	return newVersion, SetVacancySkills(storage, req.ID, *req.Skills)
 }
 
-func UpdateCandidateInfo(storage *sqlx.Tx, req s.RequestCandidate, id int) error {
-	var args []interface{}
-	var query string
-	var err error
-
-	if len(req.Password) <= 3 {
-		query, args, err = psql.Update("candidates").
-			Set("name", req.Name).
-			Set("phone_number", req.PhoneNumber).
-			Set("email", req.Email).
-			// Set("password", req.Password).
-			Set("status_id", req.Status_id).
-			Where(sq.Eq{"id": id}).
-			ToSql()
-	} else {
-		query, args, err = psql.Update("candidates").
-			Set("name", req.Name).
-			Set("phone_number", req.PhoneNumber).
-			Set("email", req.Email).
-			Set("password", req.Password).
-			Set("status_id", req.Status_id).
-			Where(sq.Eq{"id": id}).
-			ToSql()
-	}
+func UpdateCandidateInfo(storage *sqlx.Tx, req s.RequestCandidate, id, version int) (int, error) {
 
-	if err != nil {
-		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
-	}
-	_, err = storage.Exec(query, args...)
-	if err != nil {
-		return err
+	builder := psql.Update("candidates").
+		Set("name", req.Name).
+		Set("phone_number", req.PhoneNumber).
+		Set("email", req.Email).
+		Set("status_id", req.Status_id)
+	if len(req.Password) > 3 {
+		builder = builder.Set("password", req.Password)
 	}
-	return nil
+
+	return updateVersioned(storage, builder, "candidates", sq.Eq{"id": id, "deleted_at": nil}, version,
+		"данные не были обновлены, так как обновляемого соискателя не было найдено! Перепроверьте данные и попробуйте снова")
 }
 
-func GetResponseByCandidate(storage *sqlx.Tx, uid int) ([]s.ResponseByVac, error) {
+func GetResponseByCandidate(storage *sqlx.Tx, uid, afterID, limit int) ([]s.ResponseByVac, error) {
 	var result []s.ResponseByVac
 
 	query, args, err := psql.Select(
-		"r.id",
+		"r.id", "r.vacancy_revision_id",
 		"v.id as \"vacancy.id\"",
 		"v.name as \"vacancy.name\"",
-		"v.price as \"vacancy.price\"",
+		"v.salary_from as \"vacancy.salary.from\"", "v.salary_to as \"vacancy.salary.to\"", "v.salary_currency as \"vacancy.salary.currency\"", "v.salary_gross as \"vacancy.salary.gross\"", "v.salary_period as \"vacancy.salary.period\"",
 		"v.email as \"vacancy.email\"",
 		"v.phone_number as \"vacancy.phone_number\"",
 		"v.location as \"vacancy.location\"",
 		"v.about_work as \"vacancy.about_work\"",
-		"v.is_visible as \"vacancy.is_visible\"",
+		"v.state as \"vacancy.state\"",
 		"v.created_at as \"vacancy.created_at\"",
 		"v.updated_at as \"vacancy.updated_at\"",
 		"em.name_organization as \"vacancy.employee_name\"",
@@ -558,7 +826,8 @@ func GetResponseByCandidate(storage *sqlx.Tx, uid int) ([]s.ResponseByVac, error
 		Join("experience ex ON v.experience_id = ex.id").
 		Join("status s ON r.status_id = s.id").
 		Join("employer em ON v.emp_id = em.id").
-		Where(sq.Eq{"r.candidates_id": uid}).OrderBy("r.id ASC").
+		Where(sq.Eq{"r.candidates_id": uid, "v.deleted_at": nil, "em.deleted_at": nil}).
+		Where(sq.Gt{"r.id": afterID}).OrderBy("r.id ASC").Limit(uint64(limit)).
 		ToSql()
 	if err != nil {
 		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
@@ -598,10 +867,12 @@ func GetResponseOnVacancy(storage *sqlx.Tx, uid, vac_id int) (s.ResponseOnVacanc
 	return result, nil
 }
 
+// Удалённые (deleted_at) записи не считаются действующими, но их почта остаётся занятой так же, как в CheckEmailIsValid,
+// поэтому для них возвращается отдельная ошибка, а не сообщение об отсутствии пользователя
 func CheckEmailInSystem(storage *sqlx.Tx, email string) (bool, int, error) {
 	countEmp, countUsr := -1, -1
 
-	query1, args1, err := psql.Select("count(id)").From("employer").Where(sq.Eq{"email": email}).ToSql()
+	query1, args1, err := psql.Select("count(id)").From("employer").Where(sq.Eq{"email": email, "deleted_at": nil}).ToSql()
 	if err != nil {
 		return false, -1, err
 	}
@@ -613,7 +884,7 @@ func CheckEmailInSystem(storage *sqlx.Tx, email string) (bool, int, error) {
 		return false, -1, fmt.Errorf("ошибка при выполнении скрипта на добавления данных. error: %s", err.Error())
 	}
 
-	query2, args2, err := psql.Select("count(id)").From("candidates").Where(sq.Eq{"email": email}).ToSql()
+	query2, args2, err := psql.Select("count(id)").From("candidates").Where(sq.Eq{"email": email, "deleted_at": nil}).ToSql()
 
 	if err != nil {
 		return false, -1, err
@@ -626,12 +897,27 @@ func CheckEmailInSystem(storage *sqlx.Tx, email string) (bool, int, error) {
 	}
 
 	if countEmp == 0 && countUsr == 0 {
+		countDeleted := 0
+		query3, args3, err := psql.Select("count(*)").
+			FromSelect(psql.Select("id").From("employer").Where(sq.Eq{"email": email}).
+				Suffix("UNION ALL SELECT id FROM candidates WHERE email = ?", email), "emails").ToSql()
+		if err != nil {
+			return false, -1, err
+		}
+		err = storage.Get(&countDeleted, query3, args3...)
+		if err != nil {
+			return false, -1, fmt.Errorf("ошибка при выполнении скрипта на получения данных. error: %s", err.Error())
+		}
+		if countDeleted != 0 {
+			return true, -1, fmt.Errorf("аккаунт с такой почтой удалён. Почта остаётся занятой, пока администратор не восстановит или окончательно не сотрёт его")
+		}
 		return true, -1, fmt.Errorf("пользователя с такой почтой не существует в системе. Проверьте почту и попробуйте снова")
 	}
 
 	return true, countEmp, nil
 }
 
+// Удалённые (deleted_at) записи тоже учитываются: почта остаётся занятой, пока запись не будет удалена окончательно
 func CheckEmailIsValid(storage *sqlx.Tx, email string) (bool, error) {
 	var amnt_emp int = -1
 	var amnt_cnd int = -1
@@ -667,7 +953,7 @@ func CheckEmailIsValid(storage *sqlx.Tx, email string) (bool, error) {
 
 func CheckUserByEmailOnEmployer(storage *sqlx.Tx, email string) (bool, error) {
 	var amnt_emp int = -1
-	query, args, err := psql.Select("count(id)").From("employer").Where(sq.Eq{"email": email}).ToSql()
+	query, args, err := psql.Select("count(id)").From("employer").Where(sq.Eq{"email": email, "deleted_at": nil}).ToSql()
 
 	if err != nil {
 		return false, err
@@ -687,9 +973,9 @@ func GetResponseByVacancy(storage *sqlx.Tx, vac_id int) (s.SuccessResponse, erro
 	var result s.SuccessResponse
 
 	query, args, err := psql.Select(
-		"r.id", "r.created_at",
+		"r.id", "r.created_at", "r.vacancy_revision_id",
 		"c.id as \"candidate.id\"", "c.name as \"candidate.name\"", "c.phone_number as \"candidate.phone_number\"", "c.email as \"candidate.email\"",
-		"c.password as \"candidate.password\"", "c.created_at as \"candidate.created_at\"", "c.updated_at as \"candidate.updated_at\"",
+		"c.created_at as \"candidate.created_at\"", "c.updated_at as \"candidate.updated_at\"",
 		"s2.id as \"candidate.status.id\"", "s2.name as \"candidate.status.name\"", "s2.created_at as \"candidate.status.created_at\"",
 		"s.id as \"status.id\"", "s.name as \"status.name\"", "s.created_at as \"status.created_at\"",
 	).
@@ -697,7 +983,7 @@ func GetResponseByVacancy(storage *sqlx.Tx, vac_id int) (s.SuccessResponse, erro
 		Join("candidates c ON r.candidates_id = c.id").
 		Join("status s2 ON c.status_id = s2.id").
 		Join("status s ON r.status_id = s.id  ").
-		Where(sq.Eq{"r.vacancy_id": vac_id}).OrderBy("r.created_at ASC").
+		Where(sq.Eq{"r.vacancy_id": vac_id, "c.deleted_at": nil}).OrderBy("r.created_at ASC").
 		ToSql()
 	if err != nil {
 		return result, fmt.Errorf("ошибка в создании SQL скрипта для добавления данных! error: %s", err.Error())
@@ -711,32 +997,52 @@ func GetResponseByVacancy(storage *sqlx.Tx, vac_id int) (s.SuccessResponse, erro
 
 func DeleteVacancy(storage *sqlx.Tx, uid, id int, role string) error {
 
-	if role == "ADMIN" {
-		query, args, err := psql.Delete("vacancy").Where(sq.Eq{"id": id}).ToSql()
-		if err != nil {
-			return fmt.Errorf("ошибка в создании SQL скрипта для удаления данных! error: %s", err.Error())
-		}
-		result, err := storage.Exec(query, args...)
-		if err != nil {
-			return fmt.Errorf("ошибка в исполнении SQL скрипта на удаление! error: %s", err.Error())
-		}
-		rowsAffected, _ := result.RowsAffected()
-		if rowsAffected == 0 {
-			return fmt.Errorf("таких записей не было найдено у данного пользователя! Перепроверьте данные и попробуйте снова")
-		}
-	} else {
-		query, args, err := psql.Delete("vacancy").Where(sq.Eq{"id": id, "emp_id": uid}).ToSql()
-		if err != nil {
-			return fmt.Errorf("ошибка в создании SQL скрипта для удаления данных! error: %s", err.Error())
-		}
-		result, err := storage.Exec(query, args...)
-		if err != nil {
-			return fmt.Errorf("ошибка в исполнении SQL скрипта на удаление! error: %s", err.Error())
-		}
-		rowsAffected, _ := result.RowsAffected()
-		if rowsAffected == 0 {
-			return fmt.Errorf("таких записей не было найдено у данного пользователя! Перепроверьте данные и попробуйте снова")
-		}
+	builder := psql.Update("vacancy").Set("deleted_at", sq.Expr("now()")).Where(sq.Eq{"id": id, "deleted_at": nil})
+	if role != "ADMIN" {
+		builder = builder.Where(sq.Eq{"emp_id": uid})
+	}
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для удаления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка в исполнении SQL скрипта на удаление! error: %s", err.Error())
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("таких записей не было найдено у данного пользователя! Перепроверьте данные и попробуйте снова")
+	}
+	return nil
+}
+
+func GetDeletedVacancies(storage *sqlx.Tx) ([]s.DeletedEntity, error) {
+	var result []s.DeletedEntity
+
+	query, args, err := psql.Select("id", "name", "email", "deleted_at").From("vacancy").
+		Where(sq.NotEq{"deleted_at": nil}).OrderBy("deleted_at DESC").ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	err = storage.Select(&result, query, args...)
+	if err != nil {
+		return result, fmt.Errorf("ошибка в получении и маппинге данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+func RestoreVacancy(storage *sqlx.Tx, id int) error {
+	query, args, err := psql.Update("vacancy").Set("deleted_at", nil).Where(sq.Eq{"id": id}).Where(sq.NotEq{"deleted_at": nil}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("данные не были восстановлены, так как удалённой вакансии не было найдено! Перепроверьте данные и попробуйте снова")
 	}
 	return nil
 }
@@ -748,8 +1054,10 @@ func DeleteVacancy(storage *sqlx.Tx, uid, id int, role string) error {
 func PostResponse(storage *sqlx.Tx, id, vac_id int) (int, error) {
 	var res_id int
 
-	query, args, err := psql.Insert("response").Columns("candidates_id", "vacancy_id", "status_id").
-		Values(id, vac_id, 3).Suffix("ON CONFLICT (candidates_id, vacancy_id) DO NOTHING RETURNING id").ToSql()
+	// отклик запоминает редакцию вакансии, которую видел соискатель
+	revision := sq.Expr("(SELECT max(id) FROM vacancy_revisions WHERE vacancy_id = ?)", vac_id)
+	query, args, err := psql.Insert("response").Columns("candidates_id", "vacancy_id", "status_id", "vacancy_revision_id").
+		Values(id, vac_id, 3, revision).Suffix("ON CONFLICT (candidates_id, vacancy_id) DO NOTHING RETURNING id").ToSql()
 	if err != nil {
 		return -1, fmt.Errorf("ошибка в создании SQL скрипта для добавления данных! error: %s", err.Error())
 	}
@@ -781,7 +1089,7 @@ func ConfirmUserEmail(storage *sqlx.Tx, email string) error {
 
 func PatchStatusEmployer(storage *sqlx.Tx, statusID, empID int) error {
 
-	query, args, err := psql.Update("employer").Set("status_id", statusID).Where(sq.Eq{"id": empID}).ToSql()
+	query, args, err := psql.Update("employer").Set("status_id", statusID).Where(sq.Eq{"id": empID, "deleted_at": nil}).ToSql()
 	if err != nil {
 		return err
 	}
@@ -797,114 +1105,379 @@ func PatchStatusEmployer(storage *sqlx.Tx, statusID, empID int) error {
 	return nil
 }
 
-func PatchVisibilityVacancy(storage *sqlx.Tx, vacID, empID int, visible bool) error {
+// stateTimeColumns - колонка, в которую записывается время перехода вакансии в состояние
+var stateTimeColumns = map[string]string{
+	lifecycle.Moderation: "submitted_at",
+	lifecycle.Published:  "published_at",
+	lifecycle.Closed:     "closed_at",
+	lifecycle.Archived:   "archived_at",
+}
+
+func expiresIn(lifetime time.Duration) sq.Sqlizer {
+	return sq.Expr("now() + ? * interval '1 second'", int(lifetime.Seconds()))
+}
+
+// RenewVacancy продлевает срок опубликованной вакансии на lifetime от текущего момента
+func RenewVacancy(storage *sqlx.Tx, vacID, empID, version int, lifetime time.Duration) (int, error) {
+	builder := psql.Update("vacancy").
+		Set("expires_at", expiresIn(lifetime)).
+		Set("expiry_reminded_at", nil)
+
+	return updateVersioned(storage, builder, "vacancy", sq.Eq{"id": vacID, "emp_id": empID, "state": lifecycle.Published, "deleted_at": nil}, version,
+		"срок не был продлён, так как опубликованной вакансии не было найдено! Перепроверьте данные и попробуйте снова")
+}
+
+// ExpireVacancies снимает с публикации до limit вакансий, срок которых закончился, и возвращает их ID
+func ExpireVacancies(storage *sqlx.Tx, limit int) ([]int, error) {
+	var ids []int
 
-	query, args, err := psql.Update("vacancy").Set("is_visible", visible).Where(sq.Eq{"id": vacID, "emp_id": empID}).ToSql()
+	// вложенный запрос собирается с плейсхолдерами "?", иначе внутри sq.Expr нумерация $n начнётся заново
+	due := sq.Select("id").From("vacancy").
+		Where(sq.Eq{"state": lifecycle.Published, "deleted_at": nil}).
+		Where("expires_at <= now()").
+		OrderBy("expires_at").Limit(uint64(limit)).Suffix("FOR UPDATE SKIP LOCKED")
+	query, args, err := psql.Update("vacancy").
+		Set("state", lifecycle.Closed).
+		Set("closed_at", sq.Expr("now()")).
+		Set("state_changed_at", sq.Expr("now()")).
+		Set("version", sq.Expr("version + 1")).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Expr("id IN (?)", due)).
+		Suffix("RETURNING id").ToSql()
 	if err != nil {
-		return err
+		return ids, fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
 	}
-	result, err := storage.Exec(query, args...)
-	if err != nil {
-		return err
+	if err := storage.Select(&ids, query, args...); err != nil {
+		return ids, fmt.Errorf("ошибка при снятии с публикации истёкших вакансий! error: %s", err.Error())
 	}
+	return ids, nil
+}
 
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return fmt.Errorf("данные не были обновлены, так как обновляемой вакансии не было найдено! Перепроверьте данные и попробуйте снова")
+// ClaimExpiringVacancies возвращает опубликованные вакансии, срок которых закончится в ближайшие before
+// и о которых работодателю ещё не напоминали
+func ClaimExpiringVacancies(storage *sqlx.Tx, before time.Duration, limit int) ([]s.ExpiringVacancy, error) {
+	var result []s.ExpiringVacancy
+
+	query, args, err := psql.Select("v.id", "v.name", "v.expires_at", "em.name_organization as employer_name", "em.email as employer_email").
+		From("vacancy v").Join("employer em ON v.emp_id = em.id").
+		Where(sq.Eq{"v.state": lifecycle.Published, "v.deleted_at": nil, "v.expiry_reminded_at": nil, "em.deleted_at": nil}).
+		Where("v.expires_at > now()").
+		Where(sq.Expr("v.expires_at <= now() + ? * interval '1 second'", int(before.Seconds()))).
+		OrderBy("v.expires_at").Limit(uint64(limit)).Suffix("FOR UPDATE OF v SKIP LOCKED").ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
 	}
-	return nil
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка в получении вакансий с истекающим сроком! error: %s", err.Error())
+	}
+	return result, nil
 }
 
-func PatchResponse(storage *sqlx.Tx, req s.ResponsePatch) error {
-
-	query, args, err := psql.Update("response").
-		Set("status_id", req.Status_id).
-		Where(sq.Eq{"id": req.Response_id}).
-		ToSql()
+func MarkExpiryReminded(storage *sqlx.Tx, vacID int) error {
+	query, args, err := psql.Update("vacancy").Set("expiry_reminded_at", sq.Expr("now()")).Where(sq.Eq{"id": vacID}).ToSql()
 	if err != nil {
 		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
 	}
-	result, err := storage.Exec(query, args...)
-	if err != nil {
-		return err
+	if _, err := storage.Exec(query, args...); err != nil {
+		return fmt.Errorf("ошибка при отметке напоминания о сроке вакансии! error: %s", err.Error())
 	}
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return fmt.Errorf("данные не были обновлены, так как обновляемого отклика не было найдено! Перепроверьте данные и попробуйте снова")
+	return nil
+}
+
+// UpdateVacancyState переводит вакансию из состояния from в to. Если состояние успело измениться, то вакансия не обновляется.
+// empID = 0 - вакансия любого работодателя (для администратора)
+func UpdateVacancyState(storage *sqlx.Tx, vacID, empID, version int, from, to string) (int, error) {
+	builder := psql.Update("vacancy").
+		Set("state", to).
+		Set("state_changed_at", sq.Expr("now()"))
+	if column, ok := stateTimeColumns[to]; ok {
+		builder = builder.Set(column, sq.Expr("now()"))
+	}
+	// при каждой публикации срок отсчитывается заново
+	if to == lifecycle.Published {
+		builder = builder.Set("expires_at", expiresIn(lifecycle.Lifetime())).Set("expiry_reminded_at", nil)
 	}
 
-	return nil
+	where := sq.Eq{"id": vacID, "state": from, "deleted_at": nil}
+	if empID > 0 {
+		where["emp_id"] = empID
+	}
+	return updateVersioned(storage, builder, "vacancy", where, version,
+		"состояние не было изменено, так как вакансии не было найдено или её состояние уже изменилось! Перепроверьте данные и попробуйте снова")
 }
 
-func GetCandidateById(storage *sqlx.Tx, id int) (s.InfoCandidate, error) {
-	var result s.InfoCandidate
+// PatchResponse обновляет статус отклика и возвращает статус, который был у отклика до обновления.
+// Если empID > 0, то обновится только отклик на вакансию этого работодателя. Если отклика нет - sql.ErrNoRows
+func PatchResponse(storage *sqlx.Tx, req s.ResponsePatch, empID int) (int, error) {
+	var previous int
 
-	query, args, err := psql.Select(
-		"c.id", "c.name", "c.phone_number", "c.email", "c.password", "c.created_at", "c.updated_at",
-		"s.id as \"status.id\"", "s.name as \"status.name\"", "s.created_at as \"status.created_at\"",
-	).From("candidates c").Join("status s ON c.status_id = s.id").
-		Where(sq.Eq{"c.id": id}).ToSql()
+	builder := psql.Update("response r").
+		Set("status_id", req.Status_id).
+		From("response old").
+		Where("r.id = old.id").
+		Where(sq.Eq{"r.id": req.Response_id}).
+		Suffix("RETURNING old.status_id")
+	if empID > 0 {
+		builder = builder.Where("r.vacancy_id IN (SELECT id FROM vacancy WHERE emp_id = ?)", empID)
+	}
+	query, args, err := builder.ToSql()
 	if err != nil {
-		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+		return previous, fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
 	}
-
-	err = storage.Get(&result, query, args...)
+	err = storage.Get(&previous, query, args...)
 	if err == sql.ErrNoRows {
-		return result, fmt.Errorf("такого пользователя не было найдено! error: %s", err.Error())
+		return previous, err
 	} else if err != nil {
-		return result, fmt.Errorf("ошибка в маппинге данных! error: %s", err.Error())
+		return previous, err
 	}
 
-	return result, nil
+	return previous, nil
 }
 
-func GetCandidateByLogin(storage *sqlx.Tx, email, password string) (s.InfoCandidate, error) {
-	var result s.InfoCandidate
+// GetResponseNotice возвращает данные отклика, которые нужны для уведомлений: кто откликнулся, на какую вакансию и чью
+func GetResponseNotice(storage *sqlx.Tx, responseID int) (s.ResponseNotice, error) {
+	var result s.ResponseNotice
 
 	query, args, err := psql.Select(
-		"c.id", "c.name", "c.phone_number", "c.email", "c.password", "c.created_at", "c.updated_at",
-		"s.id as \"status.id\"", "s.name as \"status.name\"", "s.created_at as \"status.created_at\"",
-	).From("candidates c").Join("status s ON c.status_id = s.id").
-		Where(sq.Eq{"email": email, "password": password}).ToSql()
+		"r.id", "r.created_at",
+		"c.id as \"candidate.id\"", "c.name as \"candidate.name\"", "c.email as \"candidate.email\"",
+		"c.notify_response_status as \"candidate.notify_response_status\"",
+		"v.id as \"vacancy.id\"", "v.name as \"vacancy.name\"",
+		"em.id as \"employer.id\"", "em.name_organization as \"employer.name_organization\"", "em.email as \"employer.email\"",
+		"em.notify_new_response as \"employer.notify_new_response\"",
+	).From("response r").
+		Join("candidates c ON r.candidates_id = c.id").
+		Join("vacancy v ON r.vacancy_id = v.id").
+		Join("employer em ON v.emp_id = em.id").
+		Where(sq.Eq{"r.id": responseID}).ToSql()
 	if err != nil {
 		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
 	}
-
 	err = storage.Get(&result, query, args...)
-	if err == sql.ErrNoRows {
-		return result, fmt.Errorf("неверный логин или пароль. Такого соискателя нету в системе! error: %v", err)
-	} else if err != nil {
-		return result, fmt.Errorf("ошибка в маппинге данных! error: %s", err.Error())
+	if err != nil {
+		return result, fmt.Errorf("ошибка в получении данных отклика для уведомления! error: %s", err.Error())
 	}
-
 	return result, nil
 }
 
-func GetCandidateByEmail(storage *sqlx.Tx, email string) (s.InfoCandidate, error) {
-	var result s.InfoCandidate
+func GetCandidateNotifySettings(storage *sqlx.Tx, uid int) (s.CandidateNotifySettings, error) {
+	var result s.CandidateNotifySettings
 
-	query, args, err := psql.Select(
-		"c.id", "c.name", "c.phone_number", "c.email", "c.password", "c.created_at", "c.updated_at",
-		"s.id as \"status.id\"", "s.name as \"status.name\"", "s.created_at as \"status.created_at\"",
-	).From("candidates c").Join("status s ON c.status_id = s.id").
-		Where(sq.Eq{"email": email}).ToSql()
+	query, args, err := psql.Select("notify_response_status").From("candidates").
+		Where(sq.Eq{"id": uid, "deleted_at": nil}).ToSql()
 	if err != nil {
 		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
 	}
-
 	err = storage.Get(&result, query, args...)
-	if err != nil {
+	if err == sql.ErrNoRows {
+		return result, fmt.Errorf("соискатель не найден! Перепроверьте данные и попробуйте снова")
+	} else if err != nil {
 		return result, fmt.Errorf("ошибка в маппинге данных! error: %s", err.Error())
 	}
-
 	return result, nil
 }
 
-func PatchEmployerPassword(storage *sqlx.Tx, email, password string) error {
-
+func UpdateCandidateNotifySettings(storage *sqlx.Tx, uid int, req s.CandidateNotifySettings) error {
+	query, args, err := psql.Update("candidates").
+		Set("notify_response_status", req.ResponseStatusEmail).
+		Where(sq.Eq{"id": uid, "deleted_at": nil}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("данные не были обновлены, так как соискателя не было найдено! Перепроверьте данные и попробуйте снова")
+	}
+	return nil
+}
+
+func GetEmployerNotifySettings(storage *sqlx.Tx, empID int) (s.EmployerNotifySettings, error) {
+	var result s.EmployerNotifySettings
+
+	query, args, err := psql.Select("notify_new_response").From("employer").
+		Where(sq.Eq{"id": empID, "deleted_at": nil}).ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	err = storage.Get(&result, query, args...)
+	if err == sql.ErrNoRows {
+		return result, fmt.Errorf("работодатель не найден! Перепроверьте данные и попробуйте снова")
+	} else if err != nil {
+		return result, fmt.Errorf("ошибка в маппинге данных! error: %s", err.Error())
+	}
+	return result, nil
+}
+
+// UpdateEmployerNotifySettings сохраняет режим уведомлений о новых откликах.
+// Все отклики, которые ещё не попали в сводку, отмечаются попавшими, чтобы в следующую сводку не попали отклики,
+// о которых уже писали сразу
+func UpdateEmployerNotifySettings(storage *sqlx.Tx, empID int, req s.EmployerNotifySettings) error {
+	query, args, err := psql.Update("employer").
+		Set("notify_new_response", req.NewResponse).
+		Set("notify_digest_sent_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": empID, "deleted_at": nil}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("данные не были обновлены, так как работодателя не было найдено! Перепроверьте данные и попробуйте снова")
+	}
+
+	query, args, err = psql.Update("response").Set("digested_at", sq.Expr("now()")).
+		Where(sq.Eq{"digested_at": nil}).
+		Where("vacancy_id IN (SELECT id FROM vacancy WHERE emp_id = ?)", empID).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	if _, err := storage.Exec(query, args...); err != nil {
+		return fmt.Errorf("ошибка при отметке откликов для сводки! error: %s", err.Error())
+	}
+	return nil
+}
+
+// ClaimDigestEmployers возвращает работодателей, которым пора отправить сводку откликов (hourly - раз в час, daily - раз в сутки).
+// Строки блокируются до конца транзакции, поэтому несколько экземпляров сервера не отправят одну сводку дважды
+func ClaimDigestEmployers(storage *sqlx.Tx, limit int) ([]s.DigestEmployer, error) {
+	var result []s.DigestEmployer
+
+	query, args, err := psql.Select("id", "name_organization", "email", "notify_new_response").From("employer").
+		Where(sq.Eq{"deleted_at": nil}).
+		Where(sq.Or{
+			sq.And{sq.Eq{"notify_new_response": "hourly"}, sq.Expr("notify_digest_sent_at <= now() - interval '1 hour'")},
+			sq.And{sq.Eq{"notify_new_response": "daily"}, sq.Expr("notify_digest_sent_at <= now() - interval '1 day'")},
+		}).
+		OrderBy("notify_digest_sent_at").Limit(uint64(limit)).Suffix("FOR UPDATE SKIP LOCKED").ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка в получении работодателей для сводки откликов! error: %s", err.Error())
+	}
+	return result, nil
+}
+
+// GetResponsesForDigest возвращает отклики на вакансии работодателя, которые ещё не попадали в сводку.
+// Отклики удалённых соискателей и на удалённые вакансии в сводку не попадают
+func GetResponsesForDigest(storage *sqlx.Tx, empID int) ([]s.DigestResponse, error) {
+	var result []s.DigestResponse
+
+	query, args, err := psql.Select(
+		"r.id", "v.id as vacancy_id", "v.name as vacancy_name", "c.name as candidate_name",
+	).From("response r").
+		Join("vacancy v ON r.vacancy_id = v.id").
+		Join("candidates c ON r.candidates_id = c.id").
+		Where(sq.Eq{"v.emp_id": empID, "r.digested_at": nil, "v.deleted_at": nil, "c.deleted_at": nil}).
+		OrderBy("v.id", "r.id").ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка в получении откликов для сводки! error: %s", err.Error())
+	}
+	return result, nil
+}
+
+// MarkDigestSent отмечает отправку сводки и то, что отклики responseIDs в неё попали
+func MarkDigestSent(storage *sqlx.Tx, empID int, responseIDs []int) error {
+	query, args, err := psql.Update("employer").Set("notify_digest_sent_at", sq.Expr("now()")).Where(sq.Eq{"id": empID}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	if _, err := storage.Exec(query, args...); err != nil {
+		return fmt.Errorf("ошибка при отметке отправки сводки откликов! error: %s", err.Error())
+	}
+	if len(responseIDs) == 0 {
+		return nil
+	}
+
+	query, args, err = psql.Update("response").Set("digested_at", sq.Expr("now()")).Where(sq.Eq{"id": responseIDs}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	if _, err := storage.Exec(query, args...); err != nil {
+		return fmt.Errorf("ошибка при отметке откликов, попавших в сводку! error: %s", err.Error())
+	}
+	return nil
+}
+
+func GetCandidateById(storage *sqlx.Tx, id int) (s.InfoCandidate, error) {
+	var result s.InfoCandidate
+
+	query, args, err := psql.Select(
+		"c.id", "c.name", "c.phone_number", "c.email", "c.created_at", "c.updated_at", "c.version",
+		"s.id as \"status.id\"", "s.name as \"status.name\"", "s.created_at as \"status.created_at\"",
+	).From("candidates c").Join("status s ON c.status_id = s.id").
+		Where(sq.Eq{"c.id": id, "c.deleted_at": nil}).ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+
+	err = storage.Get(&result, query, args...)
+	if err == sql.ErrNoRows {
+		return result, fmt.Errorf("такого пользователя не было найдено! error: %s", err.Error())
+	} else if err != nil {
+		return result, fmt.Errorf("ошибка в маппинге данных! error: %s", err.Error())
+	}
+
+	return result, nil
+}
+
+func GetCandidateByLogin(storage *sqlx.Tx, email, password string) (s.InfoCandidate, error) {
+	var result s.InfoCandidate
+
+	query, args, err := psql.Select(
+		"c.id", "c.name", "c.phone_number", "c.email", "c.created_at", "c.updated_at",
+		"s.id as \"status.id\"", "s.name as \"status.name\"", "s.created_at as \"status.created_at\"",
+	).From("candidates c").Join("status s ON c.status_id = s.id").
+		Where(sq.Eq{"email": email, "password": password, "c.deleted_at": nil}).ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+
+	err = storage.Get(&result, query, args...)
+	if err == sql.ErrNoRows {
+		return result, fmt.Errorf("неверный логин или пароль. Такого соискателя нету в системе! error: %v", err)
+	} else if err != nil {
+		return result, fmt.Errorf("ошибка в маппинге данных! error: %s", err.Error())
+	}
+
+	return result, nil
+}
+
+func GetCandidateByEmail(storage *sqlx.Tx, email string) (s.InfoCandidate, error) {
+	var result s.InfoCandidate
+
+	query, args, err := psql.Select(
+		"c.id", "c.name", "c.phone_number", "c.email", "c.created_at", "c.updated_at",
+		"s.id as \"status.id\"", "s.name as \"status.name\"", "s.created_at as \"status.created_at\"",
+	).From("candidates c").Join("status s ON c.status_id = s.id").
+		Where(sq.Eq{"email": email, "c.deleted_at": nil}).ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+
+	err = storage.Get(&result, query, args...)
+	if err != nil {
+		return result, fmt.Errorf("ошибка в маппинге данных! error: %s", err.Error())
+	}
+
+	return result, nil
+}
+
+func PatchEmployerPassword(storage *sqlx.Tx, email, password string) error {
+
 	query, args, err := psql.Update("employer").
 		Set("password", password).
-		Where(sq.Eq{"email": email}).
+		Where(sq.Eq{"email": email, "deleted_at": nil}).
 		ToSql()
 	if err != nil {
 		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
@@ -925,7 +1498,7 @@ func PatchCandidatePassword(storage *sqlx.Tx, email, password string) error {
 
 	query, args, err := psql.Update("candidates").
 		Set("password", password).
-		Where(sq.Eq{"email": email}).
+		Where(sq.Eq{"email": email, "deleted_at": nil}).
 		ToSql()
 	if err != nil {
 		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
@@ -942,20 +1515,22 @@ func PatchCandidatePassword(storage *sqlx.Tx, email, password string) error {
 	return nil
 }
 
-func DeleteResponse(storage *sqlx.Tx, id, uid int) error {
-	query, args, err := psql.Delete("response").Where(sq.Eq{"vacancy_id": id, "candidates_id": uid}).ToSql()
-	if err != nil {
-		return fmt.Errorf("ошибка в создании SQL скрипта для удаления данных! error: %s", err.Error())
-	}
-	result, err := storage.Exec(query, args...)
+// DeleteResponse удаляет отклик соискателя на вакансию и возвращает ID удалённого отклика
+func DeleteResponse(storage *sqlx.Tx, id, uid int) (int, error) {
+	var respID int
+
+	query, args, err := psql.Delete("response").Where(sq.Eq{"vacancy_id": id, "candidates_id": uid}).
+		Suffix("RETURNING id").ToSql()
 	if err != nil {
-		return fmt.Errorf("ошибка в исполнении SQL скрипта на удаление! error: %s", err.Error())
+		return respID, fmt.Errorf("ошибка в создании SQL скрипта для удаления данных! error: %s", err.Error())
 	}
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("таких записей не было найдено у данного пользователя! Перепроверьте данные и попробуйте снова")
+	err = storage.Get(&respID, query, args...)
+	if err == sql.ErrNoRows {
+		return respID, fmt.Errorf("таких записей не было найдено у данного пользователя! Перепроверьте данные и попробуйте снова")
+	} else if err != nil {
+		return respID, fmt.Errorf("ошибка в исполнении SQL скрипта на удаление! error: %s", err.Error())
 	}
-	return nil
+	return respID, nil
 }
 
 func DeleteResume(storage *sqlx.Tx, id, uid int) error {
@@ -975,26 +1550,26 @@ func DeleteResume(storage *sqlx.Tx, id, uid int) error {
 	return nil
 }
 
-func UpdateCandidateResume(storage *sqlx.Tx, req s.RequestResumeUpdate, uid int) error {
+func UpdateCandidateResume(storage *sqlx.Tx, req s.RequestResumeUpdate, uid, version int) (int, error) {
 
-	query, args, err := psql.Update("resume").
+	builder := psql.Update("resume").
 		Set("experience_id", req.Experience).
 		Set("description", req.Description).
-		Where(sq.Eq{"id": req.Resume_id, "candidate_id": uid}).
-		ToSql()
-	if err != nil {
-		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
-	}
-	result, err := storage.Exec(query, args...)
+		Set("title", req.Title).
+		Set("city", req.City).
+		Set("relocation", req.Relocation).
+		Set("salary_expectation", req.SalaryExpectation).
+		Set("salary_currency", req.SalaryCurrency)
+
+	newVersion, err := updateVersioned(storage, builder, "resume", sq.Eq{"id": req.Resume_id, "candidate_id": uid}, version,
+		"данные не были обновлены, так как обновляемого резюме не было найдено! Перепроверьте данные и попробуйте снова")
 	if err != nil {
-		return err
+		return newVersion, err
 	}
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return fmt.Errorf("данные не были обновлены, так как обновляемого резюме не было найдено! Перепроверьте данные и попробуйте снова")
+	if req.Skills == nil {
+		return newVersion, nil
 	}
-
-	return nil
+	return newVersion, SetResumeSkills(storage, req.Resume_id, *req.Skills)
 }
 
 func GetAllResumeByCandidate(storage *sqlx.Tx, id int) (s.ResumeResult, error) {
@@ -1005,7 +1580,6 @@ func GetAllResumeByCandidate(storage *sqlx.Tx, id int) (s.ResumeResult, error) {
 		"c.name",
 		"c.phone_number",
 		"c.email ",
-		"c.password",
 		"c.created_at ",
 		"c.updated_at ",
 		// ! status
@@ -1014,7 +1588,7 @@ func GetAllResumeByCandidate(storage *sqlx.Tx, id int) (s.ResumeResult, error) {
 		"s.created_at as \"status.created_at\"",
 	).From("candidates c").
 		Join("status s ON c.status_id = s.id").
-		Where(sq.Eq{"c.id": id}).OrderBy("c.id ASC").
+		Where(sq.Eq{"c.id": id, "c.deleted_at": nil}).OrderBy("c.id ASC").
 		ToSql()
 	if err != nil {
 		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
@@ -1030,6 +1604,9 @@ func GetAllResumeByCandidate(storage *sqlx.Tx, id int) (s.ResumeResult, error) {
 		"r.description ",
 		"r.created_at ",
 		"r.updated_at",
+		"r.version",
+		resumeSkills, resumeWork, resumeEducation, resumeLanguages,
+		"r.title", "r.city", "r.relocation", "r.salary_expectation", "r.salary_currency", "r.visibility",
 
 		"ex.id as \"experience.id\"",
 		"ex.name as \"experience.name\"",
@@ -1051,105 +1628,368 @@ func GetAllResumeByCandidate(storage *sqlx.Tx, id int) (s.ResumeResult, error) {
 	return result, nil
 }
 
-func GetAllCandidates(storage *sqlx.Tx) ([]s.InfoCandidate, error) {
-	var result []s.InfoCandidate
-
-	query, args, err := psql.Select(
-		"c.id", "c.name", "c.phone_number", "c.email", "c.password", "c.created_at", "c.updated_at",
-		"s.id as \"status.id\"", "s.name as \"status.name\"", "s.created_at as \"status.created_at\"",
-	).From("candidates c").Join("status s ON c.status_id = s.id").OrderBy("c.id ASC").ToSql()
+// SetResumeVisibility меняет видимость резюме для работодателей: public, responded или hidden
+func SetResumeVisibility(storage *sqlx.Tx, resumeID, uid int, visibility string) error {
+	query, args, err := psql.Update("resume").Set("visibility", visibility).Where(sq.Eq{"id": resumeID, "candidate_id": uid}).ToSql()
 	if err != nil {
-		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
 	}
-
-	err = storage.Select(&result, query, args...)
+	result, err := storage.Exec(query, args...)
 	if err != nil {
-		return result, fmt.Errorf("ошибка в получении и маппинге данных. error: %s", err.Error())
+		return fmt.Errorf("ошибка при выполнении скрипта на обновление данных. error: %s", err.Error())
 	}
-	return result, nil
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("такого резюме не было найдено у данного пользователя! Перепроверьте данные и попробуйте снова")
+	}
+	return nil
+}
+
+// Условия доступа работодателя к соискателю c.id. Плейсхолдер - ID работодателя
+const (
+	respondedToEmployer = "EXISTS (SELECT 1 FROM response rsp JOIN vacancy rv ON rv.id = rsp.vacancy_id WHERE rsp.candidates_id = c.id AND rv.emp_id = ?)"
+	contactAccepted     = "EXISTS (SELECT 1 FROM contact_requests ctr WHERE ctr.candidate_id = c.id AND ctr.employer_id = ? AND ctr.status = 'accepted')"
+)
 
+// resumeVisibleTo - резюме r видно работодателю: открыто всем или соискатель откликался на его вакансии
+func resumeVisibleTo(empID int) sq.Sqlizer {
+	return sq.Or{
+		sq.Eq{"r.visibility": "public"},
+		sq.And{sq.Eq{"r.visibility": "responded"}, sq.Expr(respondedToEmployer, empID)},
+	}
 }
 
-func DeleteCandidate(storage *sqlx.Tx, uid int) error {
-	query, args, err := psql.Delete("candidates").Where(sq.Eq{"id": uid}).ToSql()
-	if err != nil {
-		return fmt.Errorf("ошибка в создании SQL скрипта для удаления данных! error: %s", err.Error())
+// ContactAccess говорит, откликался ли соискатель на вакансии работодателя и видны ли работодателю его контакты
+// (после отклика или принятого запроса контактов)
+func ContactAccess(storage *sqlx.Tx, empID, candidateID int) (responded, contacts bool, err error) {
+	var access struct {
+		Responded bool `db:"responded"`
+		Accepted  bool `db:"accepted"`
 	}
-	result, err := storage.Exec(query, args...)
+
+	query, args, err := psql.Select().
+		Column(sq.Alias(sq.Expr(respondedToEmployer, empID), "responded")).
+		Column(sq.Alias(sq.Expr(contactAccepted, empID), "accepted")).
+		From("candidates c").Where(sq.Eq{"c.id": candidateID}).ToSql()
 	if err != nil {
-		return fmt.Errorf("ошибка в исполнении SQL скрипта на удаление! error: %s", err.Error())
+		return false, false, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
 	}
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("таких записей не было найдено! Перепроверьте данные и попробуйте снова")
+	err = storage.Get(&access, query, args...)
+	if err == sql.ErrNoRows {
+		return false, false, nil
+	} else if err != nil {
+		return false, false, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
 	}
-	return nil
-
+	return access.Responded, access.Responded || access.Accepted, nil
 }
 
-func PostNewCandidate(storage *sqlx.Tx, req s.RequestCandidate) (s.InfoCandidate, error) {
-	var result s.InfoCandidate
-
-	query, args, err := psql.Insert("candidates").
-		Columns("name", "phone_number", "email", "password", "status_id").
-		Values(req.Name, req.PhoneNumber, req.Email, req.Password, req.Status_id).
-		ToSql()
+// SearchResumes ищет резюме, видимые работодателю empID. С текстом выдача отсортирована по релевантности (rank DESC, id ASC),
+// без него - сначала новые резюме. Почта и телефон соискателя заполняются, только если работодателю видны его контакты
+func SearchResumes(storage *sqlx.Tx, empID int, filter s.ResumeSearchFilter, after s.PageCursor, limit int) ([]s.ResumeCard, error) {
+	var result []s.ResumeCard
 
-	if err != nil {
-		return result, fmt.Errorf("ошибка в формировании запроса на добавление новых данных в таблицу. error: %s", err.Error())
+	queryBuilder := psql.Select(
+		"r.id", "r.candidate_id", "c.name as candidate_name", "r.description", resumeSkills,
+		"r.title", "r.city", "r.relocation", "r.salary_expectation", "r.salary_currency", "r.updated_at", "c.email", "c.phone_number",
+		"ex.id as \"experience.id\"", "ex.name as \"experience.name\"", "ex.created_at as \"experience.created_at\"",
+	).
+		Column(sq.Alias(sq.Expr(respondedToEmployer+" OR "+contactAccepted, empID, empID), "contacts_visible")).
+		From("resume r").
+		Join("candidates c ON c.id = r.candidate_id").
+		Join("experience ex ON ex.id = r.experience_id").
+		Where(sq.Eq{"c.deleted_at": nil}).
+		Where(resumeVisibleTo(empID))
+
+	if filter.Text != "" {
+		const (
+			tsVector = "to_tsvector('russian', coalesce(r.title, '') || ' ' || r.description)"
+			tsQuery  = "websearch_to_tsquery('russian', ?)"
+			rank     = "ts_rank(" + tsVector + ", " + tsQuery + ")"
+		)
+		queryBuilder = queryBuilder.Column(sq.Alias(sq.Expr(rank, filter.Text), "rank")).
+			Where(tsVector+" @@ "+tsQuery, filter.Text).OrderBy("rank DESC", "r.id ASC")
+		if after.ID > 0 {
+			queryBuilder = queryBuilder.Where("("+rank+" < ? OR ("+rank+" = ? AND r.id > ?))", filter.Text, after.Rank, filter.Text, after.Rank, after.ID)
+		}
+	} else {
+		queryBuilder = queryBuilder.Column("0 as rank").OrderBy("r.id DESC")
+		if after.ID > 0 {
+			queryBuilder = queryBuilder.Where(sq.Lt{"r.id": after.ID})
+		}
 	}
 
-	_, err = storage.Exec(query, args...)
-	if err != nil {
-		return result, fmt.Errorf("ошибка в маппинге добавленных данных. error: %s", err.Error())
+	if filter.ExpID > 0 {
+		queryBuilder = queryBuilder.Where(sq.Eq{"r.experience_id": filter.ExpID})
+	}
+	if len(filter.SkillIDs) > 0 {
+		// подзапрос собирается через sq, а не psql: плейсхолдеры нумерует внешний запрос
+		skills := sq.Select("count(*)").From("resume_skills rs").
+			Where("rs.resume_id = r.id").Where(sq.Eq{"rs.skill_id": filter.SkillIDs})
+		queryBuilder = queryBuilder.Where(sq.Expr("(?) = ?", skills, len(filter.SkillIDs)))
+	}
+	if filter.City != "" {
+		queryBuilder = queryBuilder.Where("lower(r.city) = lower(?)", filter.City)
+	}
+	// ожидаемая зарплата приводится к рублям по курсу из currency_rates
+	const expectation = "r.salary_expectation * (SELECT cur.rate FROM currency_rates cur WHERE cur.code = r.salary_currency)"
+	if filter.SalaryMin != nil {
+		queryBuilder = queryBuilder.Where(expectation+" >= ?", *filter.SalaryMin)
+	}
+	if filter.SalaryMax != nil {
+		queryBuilder = queryBuilder.Where(expectation+" <= ?", *filter.SalaryMax)
 	}
 
-	result, err = GetCandidateByEmail(storage, req.Email)
+	query, args, err := queryBuilder.Limit(uint64(limit)).ToSql()
 	if err != nil {
-		return result, fmt.Errorf("ошибка в получении добавленных данных. error: %s", err.Error())
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка в маппинге данных резюме! error: %s", err.Error())
+	}
+	for i := range result {
+		if !result[i].ContactsVisible {
+			result[i].Email, result[i].PhoneNumber = nil, nil
+		}
 	}
 	return result, nil
 }
 
-func PostNewResume(storage *sqlx.Tx, req s.RequestResume, userID int) error {
+// ErrContactRequestExists - работодатель уже запрашивал контакты этого соискателя
+var ErrContactRequestExists = errors.New("запрос контактов этому соискателю уже был отправлен")
+
+// GetVisibleResumeCandidate возвращает ID соискателя, если резюме видно работодателю, иначе sql.ErrNoRows
+func GetVisibleResumeCandidate(storage *sqlx.Tx, empID, resumeID int) (int, error) {
+	var candidateID int
 
-	MainQuery, MainArgs, err := psql.Insert("resume").Columns("candidate_id", "experience_id", "description").Values(userID, req.Experience, req.Description).ToSql()
+	query, args, err := psql.Select("r.candidate_id").From("resume r").
+		Join("candidates c ON c.id = r.candidate_id").
+		Where(sq.Eq{"r.id": resumeID, "c.deleted_at": nil}).Where(resumeVisibleTo(empID)).ToSql()
 	if err != nil {
-		return fmt.Errorf("неполучилось сформировать sql скрипты для добавления в БД. error: %s", err.Error())
+		return 0, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	err = storage.Get(&candidateID, query, args...)
+	if err == sql.ErrNoRows {
+		return 0, err
+	} else if err != nil {
+		return 0, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
 	}
+	return candidateID, nil
+}
+
+// PostContactRequest сохраняет запрос контактов соискателя по резюме. Если запрос уже был, то возвращает ErrContactRequestExists
+func PostContactRequest(storage *sqlx.Tx, empID, candidateID, resumeID int) (int, error) {
+	var id int
 
-	_, err = storage.Exec(MainQuery, MainArgs...)
+	query, args, err := psql.Insert("contact_requests").Columns("employer_id", "candidate_id", "resume_id").
+		Values(empID, candidateID, resumeID).Suffix("ON CONFLICT (employer_id, candidate_id) DO NOTHING RETURNING id").ToSql()
 	if err != nil {
-		return fmt.Errorf("неполучилось выполнить добавление в БД. error: %s", err.Error())
+		return 0, fmt.Errorf("ошибка в создании SQL скрипта для добавления данных! error: %s", err.Error())
 	}
-	return nil
+	err = storage.Get(&id, query, args...)
+	if err == sql.ErrNoRows {
+		return 0, ErrContactRequestExists
+	} else if err != nil {
+		return 0, fmt.Errorf("ошибка при выполнении скрипта на добавления данных. error: %s", err.Error())
+	}
+	return id, nil
 }
 
-func GetAllStatus(storage *sqlx.Tx) ([]s.GetStatus, error) {
-	var result []s.GetStatus
-	query, args, err := psql.Select("*").From("status").ToSql()
+func contactRequestsSelect() sq.SelectBuilder {
+	return psql.Select(
+		"ctr.id", "ctr.employer_id", "em.name_organization as employer_name", "ctr.candidate_id", "c.name as candidate_name",
+		"ctr.resume_id", "ctr.status", "ctr.created_at", "ctr.answered_at",
+	).From("contact_requests ctr").
+		Join("employer em ON em.id = ctr.employer_id").
+		Join("candidates c ON c.id = ctr.candidate_id")
+}
+
+// GetContactRequest возвращает запрос контактов по ID, sql.ErrNoRows - если его нет
+func GetContactRequest(storage *sqlx.Tx, id int) (s.ContactRequest, error) {
+	var result s.ContactRequest
+
+	query, args, err := contactRequestsSelect().Where(sq.Eq{"ctr.id": id}).ToSql()
 	if err != nil {
 		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
 	}
-
-	err = storage.Select(&result, query, args...)
-	if err != nil {
+	err = storage.Get(&result, query, args...)
+	if err == sql.ErrNoRows {
+		return result, err
+	} else if err != nil {
 		return result, fmt.Errorf("ошибка в получении и маппинге данных. error: %s", err.Error())
 	}
 	return result, nil
 }
 
-func PostNewStatus(storage *sqlx.Tx, name string) error {
+// GetContactRequests возвращает запросы контактов, где column (ctr.employer_id или ctr.candidate_id) равен id, сначала новые
+func GetContactRequests(storage *sqlx.Tx, column string, id int) ([]s.ContactRequest, error) {
+	var result []s.ContactRequest
 
-	query, args, err := psql.Insert("status").Columns("name").Values(name).ToSql()
+	query, args, err := contactRequestsSelect().Where(sq.Eq{column: id, "em.deleted_at": nil, "c.deleted_at": nil}).
+		OrderBy("ctr.created_at DESC", "ctr.id DESC").ToSql()
 	if err != nil {
-		return fmt.Errorf("ошибка в создании SQL скрипта для добавления данных! error: %s", err.Error())
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
 	}
-	_, err = storage.Exec(query, args...)
-	if err != nil {
-		return fmt.Errorf("ошибка при выполнении скрипта на добавления данных. error: %s", err.Error())
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка в получении и маппинге данных. error: %s", err.Error())
 	}
-	return nil
+	return result, nil
+}
+
+// AnswerContactRequest принимает (accepted) или отклоняет (declined) запрос контактов. Ответить можно только на запрос
+// в состоянии pending, иначе - sql.ErrNoRows
+func AnswerContactRequest(storage *sqlx.Tx, id, candidateID int, status string) error {
+	query, args, err := psql.Update("contact_requests").Set("status", status).Set("answered_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": id, "candidate_id": candidateID, "status": "pending"}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении скрипта на обновление данных. error: %s", err.Error())
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func GetAllCandidates(storage *sqlx.Tx, afterID, limit int) ([]s.InfoCandidate, error) {
+	var result []s.InfoCandidate
+
+	query, args, err := psql.Select(
+		"c.id", "c.name", "c.phone_number", "c.email", "c.created_at", "c.updated_at",
+		"s.id as \"status.id\"", "s.name as \"status.name\"", "s.created_at as \"status.created_at\"",
+	).From("candidates c").Join("status s ON c.status_id = s.id").
+		Where(sq.Eq{"c.deleted_at": nil}).Where(sq.Gt{"c.id": afterID}).OrderBy("c.id ASC").Limit(uint64(limit)).ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+
+	err = storage.Select(&result, query, args...)
+	if err != nil {
+		return result, fmt.Errorf("ошибка в получении и маппинге данных. error: %s", err.Error())
+	}
+	return result, nil
+
+}
+
+func DeleteCandidate(storage *sqlx.Tx, uid int) error {
+	query, args, err := psql.Update("candidates").Set("deleted_at", sq.Expr("now()")).Where(sq.Eq{"id": uid, "deleted_at": nil}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для удаления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка в исполнении SQL скрипта на удаление! error: %s", err.Error())
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("таких записей не было найдено! Перепроверьте данные и попробуйте снова")
+	}
+	return nil
+
+}
+
+func GetDeletedCandidates(storage *sqlx.Tx) ([]s.DeletedEntity, error) {
+	var result []s.DeletedEntity
+
+	query, args, err := psql.Select("id", "name", "email", "deleted_at").From("candidates").
+		Where(sq.NotEq{"deleted_at": nil}).OrderBy("deleted_at DESC").ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	err = storage.Select(&result, query, args...)
+	if err != nil {
+		return result, fmt.Errorf("ошибка в получении и маппинге данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+func RestoreCandidate(storage *sqlx.Tx, uid int) error {
+	query, args, err := psql.Update("candidates").Set("deleted_at", nil).Where(sq.Eq{"id": uid}).Where(sq.NotEq{"deleted_at": nil}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("данные не были восстановлены, так как удалённого соискателя не было найдено! Перепроверьте данные и попробуйте снова")
+	}
+	return nil
+}
+
+func PostNewCandidate(storage *sqlx.Tx, req s.RequestCandidate) (s.InfoCandidate, error) {
+	var result s.InfoCandidate
+
+	query, args, err := psql.Insert("candidates").
+		Columns("name", "phone_number", "email", "password", "status_id").
+		Values(req.Name, req.PhoneNumber, req.Email, req.Password, req.Status_id).
+		ToSql()
+
+	if err != nil {
+		return result, fmt.Errorf("ошибка в формировании запроса на добавление новых данных в таблицу. error: %s", err.Error())
+	}
+
+	_, err = storage.Exec(query, args...)
+	if err != nil {
+		return result, fmt.Errorf("ошибка в маппинге добавленных данных. error: %s", err.Error())
+	}
+
+	result, err = GetCandidateByEmail(storage, req.Email)
+	if err != nil {
+		return result, fmt.Errorf("ошибка в получении добавленных данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+func PostNewResume(storage *sqlx.Tx, req s.RequestResume, userID int) error {
+	var id int
+
+	MainQuery, MainArgs, err := psql.Insert("resume").
+		Columns("candidate_id", "experience_id", "description", "title", "city", "relocation", "salary_expectation", "salary_currency").
+		Values(userID, req.Experience, req.Description, req.Title, req.City, req.Relocation, req.SalaryExpectation, req.SalaryCurrency).
+		Suffix("RETURNING id").ToSql()
+	if err != nil {
+		return fmt.Errorf("неполучилось сформировать sql скрипты для добавления в БД. error: %s", err.Error())
+	}
+
+	err = storage.Get(&id, MainQuery, MainArgs...)
+	if err != nil {
+		return fmt.Errorf("неполучилось выполнить добавление в БД. error: %s", err.Error())
+	}
+	if req.Skills == nil {
+		return nil
+	}
+	return SetResumeSkills(storage, id, *req.Skills)
+}
+
+func GetAllStatus(storage *sqlx.Tx) ([]s.GetStatus, error) {
+	var result []s.GetStatus
+	query, args, err := psql.Select("*").From("status").ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+
+	err = storage.Select(&result, query, args...)
+	if err != nil {
+		return result, fmt.Errorf("ошибка в получении и маппинге данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+func PostNewStatus(storage *sqlx.Tx, name string) error {
+
+	query, args, err := psql.Insert("status").Columns("name").Values(name).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для добавления данных! error: %s", err.Error())
+	}
+	_, err = storage.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении скрипта на добавления данных. error: %s", err.Error())
+	}
+	return nil
 }
 
 func GetExperienceByName(storage *sqlx.Tx, name string) (s.GetStatus, error) {
@@ -1194,13 +2034,14 @@ func PostNewExperience(storage *sqlx.Tx, name string) error {
 	return nil
 }
 
-func GetAllEmployee(storage *sqlx.Tx) ([]s.SuccessEmployer, error) {
+func GetAllEmployee(storage *sqlx.Tx, afterID, limit int) ([]s.SuccessEmployer, error) {
 	var result []s.SuccessEmployer
 
 	query, args, err := psql.Select(
 		"em.id", "em.name_organization", "em.phone_number", "em.password", "em.email", "em.inn", "em.created_at", "em.updated_at",
 		"s.id as \"status.id\"", "s.name as \"status.name\"", "s.created_at as \"status.created_at\"",
-	).From("employer em").Join("status s ON em.status_id = s.id").OrderBy("em.id ASC").ToSql()
+	).From("employer em").Join("status s ON em.status_id = s.id").
+		Where(sq.Eq{"em.deleted_at": nil}).Where(sq.Gt{"em.id": afterID}).OrderBy("em.id ASC").Limit(uint64(limit)).ToSql()
 	if err != nil {
 		return result, fmt.Errorf("ошибка в формировании скрипта запроса. error: %s", err.Error())
 	}
@@ -1214,7 +2055,7 @@ func GetAllEmployee(storage *sqlx.Tx) ([]s.SuccessEmployer, error) {
 }
 
 func DeleteEmployee(storage *sqlx.Tx, uid int) error {
-	query, args, err := psql.Delete("employer").Where(sq.Eq{"id": uid}).ToSql()
+	query, args, err := psql.Update("employer").Set("deleted_at", sq.Expr("now()")).Where(sq.Eq{"id": uid, "deleted_at": nil}).ToSql()
 	if err != nil {
 		return fmt.Errorf("ошибка в создании SQL скрипта для удаления данных! error: %s", err.Error())
 	}
@@ -1229,6 +2070,67 @@ func DeleteEmployee(storage *sqlx.Tx, uid int) error {
 	return nil
 }
 
+func GetDeletedEmployers(storage *sqlx.Tx) ([]s.DeletedEntity, error) {
+	var result []s.DeletedEntity
+
+	query, args, err := psql.Select("id", "name_organization as name", "email", "deleted_at").From("employer").
+		Where(sq.NotEq{"deleted_at": nil}).OrderBy("deleted_at DESC").ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	err = storage.Select(&result, query, args...)
+	if err != nil {
+		return result, fmt.Errorf("ошибка в получении и маппинге данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+func RestoreEmployee(storage *sqlx.Tx, uid int) error {
+	query, args, err := psql.Update("employer").Set("deleted_at", nil).Where(sq.Eq{"id": uid}).Where(sq.NotEq{"deleted_at": nil}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("данные не были восстановлены, так как удалённого работодателя не было найдено! Перепроверьте данные и попробуйте снова")
+	}
+	return nil
+}
+
+// PurgeDeleted окончательно удаляет записи, которые были помечены удалёнными раньше before,
+// вместе со всеми связанными с ними откликами, резюме и уведомлениями
+func PurgeDeleted(storage *sqlx.Tx, before time.Time) (int64, error) {
+	var total int64
+
+	statements := []sq.Sqlizer{
+		psql.Delete("response").Where("vacancy_id IN (SELECT v.id FROM vacancy v JOIN employer em ON v.emp_id = em.id WHERE v.deleted_at < ? OR em.deleted_at < ?)", before, before),
+		psql.Delete("response").Where("candidates_id IN (SELECT id FROM candidates WHERE deleted_at < ?)", before),
+		psql.Delete("resume").Where("candidate_id IN (SELECT id FROM candidates WHERE deleted_at < ?)", before),
+		psql.Delete("vacancy").Where("deleted_at < ? OR emp_id IN (SELECT id FROM employer WHERE deleted_at < ?)", before, before),
+		psql.Delete("notifications").Where("user_role = 'candidate' AND user_id IN (SELECT id FROM candidates WHERE deleted_at < ?)", before),
+		psql.Delete("notifications").Where("user_role = 'employee' AND user_id IN (SELECT id FROM employer WHERE deleted_at < ?)", before),
+		psql.Delete("candidates").Where(sq.Lt{"deleted_at": before}),
+		psql.Delete("employer").Where(sq.Lt{"deleted_at": before}),
+	}
+	for _, statement := range statements {
+		query, args, err := statement.ToSql()
+		if err != nil {
+			return total, fmt.Errorf("ошибка в создании SQL скрипта для удаления данных! error: %s", err.Error())
+		}
+		result, err := storage.Exec(query, args...)
+		if err != nil {
+			return total, fmt.Errorf("ошибка в исполнении SQL скрипта на удаление! error: %s", err.Error())
+		}
+		rows, _ := result.RowsAffected()
+		total += rows
+	}
+	return total, nil
+}
+
 func PostNewEmployer(storage *sqlx.Tx, body s.RequestEmployee) (s.SuccessEmployer, error) {
 	var result s.SuccessEmployer
 
@@ -1249,42 +2151,139 @@ func PostNewEmployer(storage *sqlx.Tx, body s.RequestEmployee) (s.SuccessEmploye
 	return result, nil
 }
 
-func UpdateEmployeeInfo(storage *sqlx.Tx, req s.RequestEmployer, uid int) error {
-	var args []interface{}
-	var query string
-	var err error
-	if len(req.Password) <= 3 {
-		query, args, err = psql.Update("employer").
-			Set("name_organization", req.NameOrganization).
-			Set("phone_number", req.PhoneNumber).
-			Set("email", req.Email).
-			// Set("password", req.Password).
-			Set("status_id", req.Status_id).
-			Where(sq.Eq{"id": uid}).
-			ToSql()
-	} else {
-		query, args, err = psql.Update("employer").
-			Set("name_organization", req.NameOrganization).
-			Set("phone_number", req.PhoneNumber).
-			Set("email", req.Email).
-			Set("password", req.Password).
-			Set("status_id", req.Status_id).
-			Where(sq.Eq{"id": uid}).
-			ToSql()
+func UpdateEmployeeInfo(storage *sqlx.Tx, req s.RequestEmployer, uid, version int) (int, error) {
+
+	builder := psql.Update("employer").
+		Set("name_organization", req.NameOrganization).
+		Set("phone_number", req.PhoneNumber).
+		Set("email", req.Email).
+		Set("status_id", req.Status_id)
+	if len(req.Password) > 3 {
+		builder = builder.Set("password", req.Password)
 	}
 
+	return updateVersioned(storage, builder, "employer", sq.Eq{"id": uid, "deleted_at": nil}, version,
+		"данные не были обновлены, так как работодатель не был найден! Перепроверьте данные и попробуйте снова")
+}
+
+// updateVersioned выполняет обновление записи и увеличивает её версию (она же ETag).
+// Если version > 0, то запись обновится только при совпадении версии, иначе вернётся ErrVersionMismatch.
+// Если записи нет вовсе, то вернётся ошибка, которая оборачивает ErrNotFound
+func updateVersioned(storage *sqlx.Tx, builder sq.UpdateBuilder, table string, where sq.Eq, version int, notFound string) (int, error) {
+	var newVersion int
+
+	builder = builder.
+		Set("version", sq.Expr("version + 1")).
+		Set("updated_at", sq.Expr("now()")).
+		Where(where).
+		Suffix("RETURNING version")
+	if version > 0 {
+		builder = builder.Where(sq.Eq{"version": version})
+	}
+	query, args, err := builder.ToSql()
 	if err != nil {
-		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+		return -1, fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
 	}
-	result, err := storage.Exec(query, args...)
+
+	err = storage.Get(&newVersion, query, args...)
+	if err == sql.ErrNoRows {
+		if version > 0 {
+			var amount int
+			query, args, err = psql.Select("count(id)").From(table).Where(where).ToSql()
+			if err != nil {
+				return -1, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+			}
+			err = storage.Get(&amount, query, args...)
+			if err != nil {
+				return -1, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+			}
+			if amount > 0 {
+				return -1, ErrVersionMismatch
+			}
+		}
+		return -1, fmt.Errorf("%s: %w", notFound, ErrNotFound)
+	} else if err != nil {
+		return -1, err
+	}
+	return newVersion, nil
+}
+
+// EnqueueEmail кладёт письмо в outbox в той же транзакции, что и основное изменение.
+// Если транзакция откатится, то и письмо никуда не уйдёт
+func EnqueueEmail(storage *sqlx.Tx, to, subject, body, htmlBody string) error {
+	query, args, err := psql.Insert("email_outbox").Columns("recipient", "subject", "body", "html_body").Values(to, subject, body, htmlBody).ToSql()
 	if err != nil {
-		return err
+		return fmt.Errorf("ошибка в создании SQL скрипта для добавления письма в очередь! error: %s", err.Error())
 	}
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return fmt.Errorf("данные не были обновлены, так как работодатель не был найден! Перепроверьте данные и попробуйте снова")
+	_, err = storage.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка в добавлении письма в очередь на отправку! error: %s", err.Error())
+	}
+	return nil
+}
+
+// ClaimOutboxEmails забирает до limit писем, которые пора отправить: переводит их в статус 'processing'
+// и арендует на lease. Пока аренда не истекла, другой отправщик эти письма не возьмёт, поэтому транзакцию
+// можно сразу закоммитить и отправлять письма без неё. Письма с истёкшей арендой (отправщик упал) забираются снова
+func ClaimOutboxEmails(storage *sqlx.Tx, limit int, lease time.Duration) ([]s.OutboxEmail, error) {
+	var result []s.OutboxEmail
+
+	query, args, err := psql.Update("email_outbox").
+		Set("status", "processing").
+		Set("locked_until", sq.Expr("now() + ? * interval '1 second'", int(lease.Seconds()))).
+		Where(`id IN (SELECT id FROM email_outbox
+			WHERE (status IN ('pending', 'failed') AND next_attempt_at <= now()) OR (status = 'processing' AND locked_until < now())
+			ORDER BY next_attempt_at ASC, id ASC LIMIT ? FOR UPDATE SKIP LOCKED)`, limit).
+		Suffix("RETURNING id, recipient, subject, body, html_body, attempts").ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения писем! error: %s", err.Error())
+	}
+	err = storage.Select(&result, query, args...)
+	if err != nil {
+		return result, fmt.Errorf("ошибка в получении писем из очереди. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+func MarkOutboxEmailSent(storage *sqlx.Tx, id int) error {
+	query, args, err := psql.Update("email_outbox").
+		Set("status", "sent").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("last_error", nil).
+		Set("locked_until", nil).
+		Set("sent_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": id}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления письма! error: %s", err.Error())
+	}
+	_, err = storage.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка в обновлении статуса письма! error: %s", err.Error())
 	}
+	return nil
+}
 
+// MarkOutboxEmailFailed записывает неудачную попытку отправки, следующая будет не раньше чем через retryIn.
+// Если dead = true, то письмо переводится в статус 'dead' и больше не отправляется автоматически
+func MarkOutboxEmailFailed(storage *sqlx.Tx, id int, sendErr string, retryIn time.Duration, dead bool) error {
+	status := "failed"
+	if dead {
+		status = "dead"
+	}
+	query, args, err := psql.Update("email_outbox").
+		Set("status", status).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("last_error", sendErr).
+		Set("locked_until", nil).
+		Set("next_attempt_at", sq.Expr("now() + ? * interval '1 second'", int(retryIn.Seconds()))).
+		Where(sq.Eq{"id": id}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления письма! error: %s", err.Error())
+	}
+	_, err = storage.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка в обновлении статуса письма! error: %s", err.Error())
+	}
 	return nil
 }
 
@@ -1335,3 +2334,1353 @@ func ParseVerifyToken(tokenString string) (string, error) {
 
 	return "", fmt.Errorf("invalid token")
 }
+
+// AddNotification сохраняет уведомление для пользователя и возвращает его. payload сохраняется как JSON
+func AddNotification(storage *sqlx.Tx, role string, uid int, notificationType string, payload any) (s.Notification, error) {
+	var result s.Notification
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return result, fmt.Errorf("ошибка при сериализации данных уведомления! error: %s", err.Error())
+	}
+	query, args, err := psql.Insert("notifications").Columns("user_role", "user_id", "type", "payload").
+		Values(role, uid, notificationType, sq.Expr("?::jsonb", string(data))).
+		Suffix("RETURNING id, type, payload, is_read, created_at").ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для добавления данных! error: %s", err.Error())
+	}
+	if err := storage.Get(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка при добавлении уведомления! error: %s", err.Error())
+	}
+	return result, nil
+}
+
+// GetNotifications возвращает уведомления пользователя от новых к старым. beforeID - ID последнего уведомления предыдущей страницы (0 - первая страница)
+func GetNotifications(storage *sqlx.Tx, role string, uid, beforeID, limit int, unreadOnly bool) ([]s.Notification, error) {
+	var result []s.Notification
+
+	builder := psql.Select("id", "type", "payload", "is_read", "created_at").From("notifications").
+		Where(sq.Eq{"user_role": role, "user_id": uid})
+	if beforeID > 0 {
+		builder = builder.Where(sq.Lt{"id": beforeID})
+	}
+	if unreadOnly {
+		builder = builder.Where(sq.Eq{"is_read": false})
+	}
+	query, args, err := builder.OrderBy("id DESC").Limit(uint64(limit)).ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+func MarkNotificationRead(storage *sqlx.Tx, role string, uid, id int) error {
+	query, args, err := psql.Update("notifications").Set("is_read", true).
+		Where(sq.Eq{"id": id, "user_role": role, "user_id": uid}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("уведомление не найдено! Перепроверьте данные и попробуйте снова")
+	}
+	return nil
+}
+
+// MarkAllNotificationsRead отмечает прочитанными все уведомления пользователя и возвращает, сколько их было непрочитанных
+func MarkAllNotificationsRead(storage *sqlx.Tx, role string, uid int) (int64, error) {
+	query, args, err := psql.Update("notifications").Set("is_read", true).
+		Where(sq.Eq{"user_role": role, "user_id": uid, "is_read": false}).ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows, nil
+}
+
+func CountUnreadNotifications(storage *sqlx.Tx, role string, uid int) (int, error) {
+	var count int
+
+	query, args, err := psql.Select("count(*)").From("notifications").
+		Where(sq.Eq{"user_role": role, "user_id": uid, "is_read": false}).ToSql()
+	if err != nil {
+		return count, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Get(&count, query, args...); err != nil {
+		return count, fmt.Errorf("ошибка при подсчёте непрочитанных уведомлений! error: %s", err.Error())
+	}
+	return count, nil
+}
+
+// GetVacancyAudience возвращает работодателя вакансии и соискателей, которые на неё откликались.
+// Удалённые вакансии тоже учитываются, чтобы можно было сообщить об удалении
+func GetVacancyAudience(storage *sqlx.Tx, vacID int) (int, []int, error) {
+	var empID int
+	var candidates []int
+
+	query, args, err := psql.Select("emp_id").From("vacancy").Where(sq.Eq{"id": vacID}).ToSql()
+	if err != nil {
+		return empID, candidates, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	err = storage.Get(&empID, query, args...)
+	if err == sql.ErrNoRows {
+		return empID, candidates, fmt.Errorf("вакансия не найдена! Перепроверьте данные и попробуйте снова")
+	} else if err != nil {
+		return empID, candidates, fmt.Errorf("ошибка в маппинге данных! error: %s", err.Error())
+	}
+
+	query, args, err = psql.Select("DISTINCT candidates_id").From("response").Where(sq.Eq{"vacancy_id": vacID}).ToSql()
+	if err != nil {
+		return empID, candidates, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Select(&candidates, query, args...); err != nil {
+		return empID, candidates, fmt.Errorf("ошибка при получении откликнувшихся соискателей! error: %s", err.Error())
+	}
+	return empID, candidates, nil
+}
+
+func CreateWebhook(storage *sqlx.Tx, empID int, url, secret string, events []string) (s.Webhook, error) {
+	var result s.Webhook
+
+	query, args, err := psql.Insert("webhooks").Columns("emp_id", "url", "secret", "events").
+		Values(empID, url, secret, events).
+		Suffix("RETURNING id, url, array_to_json(events) as events, created_at").ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для добавления данных! error: %s", err.Error())
+	}
+	if err := storage.Get(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка при добавлении вебхука! error: %s", err.Error())
+	}
+	return result, nil
+}
+
+func GetWebhooksByEmployer(storage *sqlx.Tx, empID int) ([]s.Webhook, error) {
+	var result []s.Webhook
+
+	query, args, err := psql.Select("id", "url", "array_to_json(events) as events", "created_at").From("webhooks").
+		Where(sq.Eq{"emp_id": empID}).OrderBy("id").ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+func DeleteWebhook(storage *sqlx.Tx, empID, id int) error {
+	query, args, err := psql.Delete("webhooks").Where(sq.Eq{"id": id, "emp_id": empID}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для удаления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка в исполнении SQL скрипта на удаление! error: %s", err.Error())
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("вебхук не найден у данного работодателя! Перепроверьте данные и попробуйте снова")
+	}
+	return nil
+}
+
+// EnqueueWebhookEvent создаёт доставку события для каждого вебхука работодателя, который подписан на это событие
+func EnqueueWebhookEvent(storage *sqlx.Tx, empID int, event string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("ошибка при сериализации данных события! error: %s", err.Error())
+	}
+	query, args, err := psql.Insert("webhook_deliveries").Columns("webhook_id", "event", "payload").
+		Select(psql.Select("id").Column(sq.Expr("?::text", event)).Column(sq.Expr("?::jsonb", string(data))).From("webhooks").
+			Where(sq.Eq{"emp_id": empID}).Where("? = ANY(events)", event)).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для добавления данных! error: %s", err.Error())
+	}
+	if _, err := storage.Exec(query, args...); err != nil {
+		return fmt.Errorf("ошибка при добавлении доставки вебхука! error: %s", err.Error())
+	}
+	return nil
+}
+
+// ClaimWebhookDeliveries забирает до limit доставок, которые пора отправить, так же, как ClaimOutboxEmails:
+// переводит их в статус 'processing' и арендует на lease, после чего транзакцию можно сразу закоммитить
+func ClaimWebhookDeliveries(storage *sqlx.Tx, limit int, lease time.Duration) ([]s.PendingWebhookDelivery, error) {
+	var result []s.PendingWebhookDelivery
+
+	query, args, err := psql.Update("webhook_deliveries d").
+		Set("status", "processing").
+		Set("locked_until", sq.Expr("now() + ? * interval '1 second'", int(lease.Seconds()))).
+		From("webhooks w").
+		Where("d.webhook_id = w.id").
+		Where(`d.id IN (SELECT id FROM webhook_deliveries
+			WHERE (status IN ('pending', 'failed') AND next_attempt_at <= now()) OR (status = 'processing' AND locked_until < now())
+			ORDER BY next_attempt_at ASC, id ASC LIMIT ? FOR UPDATE SKIP LOCKED)`, limit).
+		Suffix("RETURNING d.id, w.url, w.secret, d.event, d.payload, d.attempts, d.created_at").ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения доставок! error: %s", err.Error())
+	}
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка в получении доставок вебхуков из очереди. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+func MarkWebhookDelivered(storage *sqlx.Tx, id, code int) error {
+	query, args, err := psql.Update("webhook_deliveries").
+		Set("status", "sent").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("response_code", code).
+		Set("last_error", nil).
+		Set("locked_until", nil).
+		Set("delivered_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": id}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления доставки! error: %s", err.Error())
+	}
+	if _, err := storage.Exec(query, args...); err != nil {
+		return fmt.Errorf("ошибка в обновлении статуса доставки! error: %s", err.Error())
+	}
+	return nil
+}
+
+// MarkWebhookFailed записывает неудачную попытку доставки. code - HTTP код ответа (0, если ответа не было).
+// Если dead = true, то доставка больше не повторяется автоматически, но её можно отправить заново вручную
+func MarkWebhookFailed(storage *sqlx.Tx, id, code int, sendErr string, retryIn time.Duration, dead bool) error {
+	status := "failed"
+	if dead {
+		status = "dead"
+	}
+	var responseCode any
+	if code != 0 {
+		responseCode = code
+	}
+	query, args, err := psql.Update("webhook_deliveries").
+		Set("status", status).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("response_code", responseCode).
+		Set("last_error", sendErr).
+		Set("locked_until", nil).
+		Set("next_attempt_at", sq.Expr("now() + ? * interval '1 second'", int(retryIn.Seconds()))).
+		Where(sq.Eq{"id": id}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления доставки! error: %s", err.Error())
+	}
+	if _, err := storage.Exec(query, args...); err != nil {
+		return fmt.Errorf("ошибка в обновлении статуса доставки! error: %s", err.Error())
+	}
+	return nil
+}
+
+// GetWebhookDeliveries возвращает журнал доставок вебхуков работодателя от новых к старым.
+// webhookID = 0 - доставки всех вебхуков, beforeID - ID последней доставки предыдущей страницы
+func GetWebhookDeliveries(storage *sqlx.Tx, empID, webhookID, beforeID, limit int) ([]s.WebhookDelivery, error) {
+	var result []s.WebhookDelivery
+
+	builder := psql.Select(
+		"d.id", "d.webhook_id", "d.event", "d.payload", "d.status", "d.attempts", "d.response_code",
+		"d.last_error", "d.next_attempt_at", "d.created_at", "d.delivered_at",
+	).From("webhook_deliveries d").Join("webhooks w ON d.webhook_id = w.id").
+		Where(sq.Eq{"w.emp_id": empID})
+	if webhookID > 0 {
+		builder = builder.Where(sq.Eq{"d.webhook_id": webhookID})
+	}
+	if beforeID > 0 {
+		builder = builder.Where(sq.Lt{"d.id": beforeID})
+	}
+	query, args, err := builder.OrderBy("d.id DESC").Limit(uint64(limit)).ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+// RedeliverWebhook создаёт новую доставку с теми же данными, что и у доставки id. Старая запись остаётся в журнале
+func RedeliverWebhook(storage *sqlx.Tx, empID, id int) (int, error) {
+	var newID int
+
+	query, args, err := psql.Insert("webhook_deliveries").Columns("webhook_id", "event", "payload").
+		Select(psql.Select("d.webhook_id", "d.event", "d.payload").From("webhook_deliveries d").
+			Join("webhooks w ON d.webhook_id = w.id").
+			Where(sq.Eq{"d.id": id, "w.emp_id": empID})).
+		Suffix("RETURNING id").ToSql()
+	if err != nil {
+		return newID, fmt.Errorf("ошибка в создании SQL скрипта для добавления данных! error: %s", err.Error())
+	}
+	err = storage.Get(&newID, query, args...)
+	if err == sql.ErrNoRows {
+		return newID, fmt.Errorf("доставка не найдена у данного работодателя! Перепроверьте данные и попробуйте снова")
+	} else if err != nil {
+		return newID, fmt.Errorf("ошибка при повторной отправке вебхука! error: %s", err.Error())
+	}
+	return newID, nil
+}
+
+const savedSearchColumns = "ss.id, ss.name, ss.exp_id, ss.min_price, ss.max_price, ss.text, ss.frequency, ss.last_run_at, ss.created_at"
+
+// CreateSavedSearch сохраняет поиск соискателя. Вакансии, которые уже опубликованы, в письма по нему не попадут
+func CreateSavedSearch(storage *sqlx.Tx, uid int, req s.RequestSavedSearch, unsubscribeToken string) (s.SavedSearch, error) {
+	var result s.SavedSearch
+
+	query, args, err := psql.Insert("saved_searches AS ss").
+		Columns("candidate_id", "name", "exp_id", "min_price", "max_price", "text", "frequency", "last_published_at", "unsubscribe_token").
+		Values(uid, req.Name, req.ExpID, req.Min, req.Max, req.Text, req.Frequency,
+			sq.Expr("(SELECT COALESCE(max(published_at), now()) FROM vacancy)"), unsubscribeToken).
+		Suffix("RETURNING " + savedSearchColumns).ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для добавления данных! error: %s", err.Error())
+	}
+	if err := storage.Get(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка при добавлении сохранённого поиска! error: %s", err.Error())
+	}
+	return result, nil
+}
+
+func GetSavedSearches(storage *sqlx.Tx, uid int) ([]s.SavedSearch, error) {
+	var result []s.SavedSearch
+
+	query, args, err := psql.Select(savedSearchColumns).From("saved_searches ss").
+		Where(sq.Eq{"ss.candidate_id": uid}).OrderBy("ss.id").ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+func DeleteSavedSearch(storage *sqlx.Tx, uid, id int) error {
+	query, args, err := psql.Delete("saved_searches").Where(sq.Eq{"id": id, "candidate_id": uid}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для удаления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка в исполнении SQL скрипта на удаление! error: %s", err.Error())
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("сохранённый поиск не найден у данного пользователя! Перепроверьте данные и попробуйте снова")
+	}
+	return nil
+}
+
+// UnsubscribeSavedSearch отключает письма по сохранённому поиску по токену из письма. Сам поиск остаётся
+func UnsubscribeSavedSearch(storage *sqlx.Tx, token string) (s.SavedSearch, error) {
+	var result s.SavedSearch
+
+	query, args, err := psql.Update("saved_searches ss").Set("frequency", "off").
+		Where(sq.Eq{"ss.unsubscribe_token": token}).
+		Suffix("RETURNING " + savedSearchColumns).ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	err = storage.Get(&result, query, args...)
+	if err == sql.ErrNoRows {
+		return result, fmt.Errorf("сохранённый поиск не найден! Возможно, он уже был удалён")
+	} else if err != nil {
+		return result, fmt.Errorf("ошибка при отписке от сохранённого поиска! error: %s", err.Error())
+	}
+	return result, nil
+}
+
+// ClaimDueSavedSearches возвращает сохранённые поиски, по которым пора отправить письмо:
+// instant - не чаще раза в минуту, daily - раз в сутки, weekly - раз в неделю.
+// Строки блокируются до конца транзакции, поэтому несколько экземпляров сервера не отправят одно письмо дважды
+func ClaimDueSavedSearches(storage *sqlx.Tx, limit int) ([]s.DueSavedSearch, error) {
+	var result []s.DueSavedSearch
+
+	query, args, err := psql.Select(savedSearchColumns,
+		"ss.last_published_at", "ss.unsubscribe_token", "c.name as candidate_name", "c.email as candidate_email",
+	).From("saved_searches ss").Join("candidates c ON ss.candidate_id = c.id").
+		Where(sq.Eq{"c.deleted_at": nil}).
+		Where(sq.Or{
+			sq.And{sq.Eq{"ss.frequency": "instant"}, sq.Expr("ss.last_run_at <= now() - interval '1 minute'")},
+			sq.And{sq.Eq{"ss.frequency": "daily"}, sq.Expr("ss.last_run_at <= now() - interval '1 day'")},
+			sq.And{sq.Eq{"ss.frequency": "weekly"}, sq.Expr("ss.last_run_at <= now() - interval '7 days'")},
+		}).
+		OrderBy("ss.last_run_at").Limit(uint64(limit)).Suffix("FOR UPDATE OF ss SKIP LOCKED").ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка в получении сохранённых поисков для рассылки! error: %s", err.Error())
+	}
+	return result, nil
+}
+
+// MarkSavedSearchRun запоминает время запуска и время публикации самой новой учтённой вакансии
+func MarkSavedSearchRun(storage *sqlx.Tx, id int, lastPublishedAt time.Time) error {
+	query, args, err := psql.Update("saved_searches").
+		Set("last_run_at", sq.Expr("now()")).
+		Set("last_published_at", lastPublishedAt).
+		Where(sq.Eq{"id": id}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	if _, err := storage.Exec(query, args...); err != nil {
+		return fmt.Errorf("ошибка при отметке запуска сохранённого поиска! error: %s", err.Error())
+	}
+	return nil
+}
+
+// StartJobRun записывает в историю начало запуска фоновой задачи и возвращает ID записи.
+// scheduledAt - время по расписанию (nil у ручного запуска). Если на это время задача уже запускалась,
+// например другим экземпляром сервера, то запись не создаётся и возвращается started = false
+func StartJobRun(storage *sqlx.Tx, job, trigger, status, instance string, scheduledAt *time.Time) (id int, started bool, err error) {
+	query, args, err := psql.Insert("job_runs").Columns("job", "trigger", "status", "instance", "scheduled_at").
+		Values(job, trigger, status, instance, scheduledAt).
+		Suffix("ON CONFLICT (job, scheduled_at) WHERE trigger = 'schedule' DO NOTHING RETURNING id").ToSql()
+	if err != nil {
+		return id, false, fmt.Errorf("ошибка в создании SQL скрипта для добавления данных! error: %s", err.Error())
+	}
+	err = storage.Get(&id, query, args...)
+	if err == sql.ErrNoRows {
+		return id, false, nil
+	}
+	if err != nil {
+		return id, false, fmt.Errorf("ошибка при записи запуска задачи! error: %s", err.Error())
+	}
+	return id, true, nil
+}
+
+// FinishJobRun записывает результат запуска. runErr - текст ошибки, nil если задача завершилась успешно
+func FinishJobRun(storage *sqlx.Tx, id int, status string, runErr *string) error {
+	query, args, err := psql.Update("job_runs").
+		Set("status", status).
+		Set("error", runErr).
+		Set("finished_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": id}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	if _, err := storage.Exec(query, args...); err != nil {
+		return fmt.Errorf("ошибка при записи результата задачи! error: %s", err.Error())
+	}
+	return nil
+}
+
+// AbortJobRuns помечает неудачными запуски в статусе running, advisory lock задачи которых сейчас никто не держит:
+// значит, выполнявший их сервер упал или был перезапущен. Экземпляр сервера не важен - после перезапуска у него
+// другой PID. locks - ключи pg_try_advisory_lock зарегистрированных задач; запуски остальных задач выполнять некому
+func AbortJobRuns(storage *sqlx.Tx, locks map[string]int64) (int64, error) {
+	names := make([]string, 0, len(locks))
+	classIDs := make([]int64, 0, len(locks))
+	objIDs := make([]int64, 0, len(locks))
+	for name, key := range locks {
+		// bigint ключ advisory lock в pg_locks разложен на старшие (classid) и младшие (objid) 32 бита
+		names = append(names, name)
+		classIDs = append(classIDs, int64(uint64(key)>>32))
+		objIDs = append(objIDs, int64(uint32(key)))
+	}
+	const lockHeld = `EXISTS (
+    SELECT 1 FROM unnest(?::text[], ?::bigint[], ?::bigint[]) k(job, classid, objid)
+    JOIN pg_locks l ON l.locktype = 'advisory' AND l.granted AND l.objsubid = 1
+        AND l.database = (SELECT oid FROM pg_database WHERE datname = current_database())
+        AND l.classid::bigint = k.classid AND l.objid::bigint = k.objid
+    WHERE k.job = job_runs.job)`
+	query, args, err := psql.Update("job_runs").
+		Set("status", "failed").
+		Set("error", "сервер был остановлен во время выполнения задачи").
+		Set("finished_at", sq.Expr("now()")).
+		Where(sq.Eq{"status": "running"}).
+		Where("NOT "+lockHeld, names, classIDs, objIDs).ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при обновлении незавершённых запусков! error: %s", err.Error())
+	}
+	return result.RowsAffected()
+}
+
+// GetJobRuns возвращает историю запусков, от новых к старым. Пустой job - запуски всех задач
+func GetJobRuns(storage *sqlx.Tx, job string, beforeID, limit int) ([]s.JobRun, error) {
+	var result []s.JobRun
+
+	builder := psql.Select("id", "job", "trigger", "status", "error", "instance", "scheduled_at", "started_at", "finished_at").
+		From("job_runs")
+	if job != "" {
+		builder = builder.Where(sq.Eq{"job": job})
+	}
+	if beforeID > 0 {
+		builder = builder.Where(sq.Lt{"id": beforeID})
+	}
+	query, args, err := builder.OrderBy("id DESC").Limit(uint64(limit)).ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+// GetLastJobRuns возвращает последний запуск каждой задачи
+func GetLastJobRuns(storage *sqlx.Tx) (map[string]s.JobRun, error) {
+	var runs []s.JobRun
+
+	query, args, err := psql.Select("DISTINCT ON (job) id", "job", "trigger", "status", "error", "instance", "scheduled_at", "started_at", "finished_at").
+		From("job_runs").OrderBy("job", "id DESC").ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Select(&runs, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	result := make(map[string]s.JobRun, len(runs))
+	for _, run := range runs {
+		result[run.Job] = run
+	}
+	return result, nil
+}
+
+// PurgeJobRuns удаляет из истории запуски, начатые раньше before
+func PurgeJobRuns(storage *sqlx.Tx, before time.Time) (int64, error) {
+	query, args, err := psql.Delete("job_runs").Where(sq.Lt{"started_at": before}).ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка в создании SQL скрипта для удаления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при удалении старых запусков задач! error: %s", err.Error())
+	}
+	return result.RowsAffected()
+}
+
+// IsEmployerTrusted проверяет, что статус работодателя позволяет публиковать вакансии без модерации
+func IsEmployerTrusted(storage *sqlx.Tx, empID int) (bool, error) {
+	var trusted bool
+
+	const query = "SELECT EXISTS (SELECT 1 FROM employer em JOIN trusted_statuses t ON t.status_id = em.status_id WHERE em.id = $1)"
+	if err := storage.Get(&trusted, query, empID); err != nil {
+		return trusted, fmt.Errorf("ошибка при проверке статуса работодателя! error: %s", err.Error())
+	}
+	return trusted, nil
+}
+
+func GetTrustedStatuses(storage *sqlx.Tx) ([]s.GetStatus, error) {
+	var result []s.GetStatus
+
+	query, args, err := psql.Select("s.id", "s.name", "s.created_at").From("status s").
+		Join("trusted_statuses t ON t.status_id = s.id").OrderBy("s.id").ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка в получении и маппинге данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+// SetStatusTrusted разрешает (trusted = true) или запрещает работодателям со статусом statusID публиковать вакансии без модерации
+func SetStatusTrusted(storage *sqlx.Tx, statusID int, trusted bool) error {
+	var builder sq.Sqlizer = psql.Delete("trusted_statuses").Where(sq.Eq{"status_id": statusID})
+	if trusted {
+		builder = psql.Insert("trusted_statuses").Columns("status_id").Values(statusID).Suffix("ON CONFLICT (status_id) DO NOTHING")
+	}
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	if _, err := storage.Exec(query, args...); err != nil {
+		return fmt.Errorf("ошибка при обновлении доверенных статусов! error: %s", err.Error())
+	}
+	return nil
+}
+
+// GetModerationQueue возвращает вакансии, которые ждут решения модератора, в порядке поступления
+func GetModerationQueue(storage *sqlx.Tx, afterID, limit int) ([]s.ModerationVacancy, error) {
+	var result []s.ModerationVacancy
+
+	query, args, err := psql.Select(
+		"v.id", "v.name", "v.email", "v.phone_number", "v.location", "v.about_work", "v.state", "v.created_at", "v.updated_at",
+		"v.salary_from as \"salary.from\"", "v.salary_to as \"salary.to\"", "v.salary_currency as \"salary.currency\"", "v.salary_gross as \"salary.gross\"", "v.salary_period as \"salary.period\"",
+		vacancyEmploymentType, vacancySchedule, vacancyWorkFormat, vacancySkills,
+		"v.submitted_at", "v.moderation_reason",
+
+		"e.id as \"experience.id\"", "e.name as \"experience.name\"", "e.created_at as \"experience.created_at\"",
+
+		"em.id as \"employer.id\"", "em.name_organization as \"employer.name_organization\"",
+		"em.phone_number as \"employer.phone_number\"", "em.email as \"employer.email\"",
+		"em.inn as \"employer.inn\"",
+		"em.created_at as \"employer.created_at\"", "em.updated_at as \"employer.updated_at\"",
+
+		"s.id as \"employer.status.id\"", "s.name as \"employer.status.name\"", "s.created_at as \"employer.status.created_at\"",
+	).From("vacancy v").
+		Join("experience e ON v.experience_id = e.id").
+		Join("employer em ON v.emp_id = em.id").
+		Join("status s ON em.status_id = s.id").
+		Where(sq.Eq{"v.state": lifecycle.Moderation, "v.deleted_at": nil, "em.deleted_at": nil}).
+		Where(sq.Gt{"v.id": afterID}).OrderBy("v.id ASC").Limit(uint64(limit)).ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка в маппинге данных вакансий! error: %s", err.Error())
+	}
+	return result, nil
+}
+
+// AddModerationDecision записывает решение модератора в журнал и показывает работодателю причину отказа.
+// reason = nil - причины нет (при одобрении она сбрасывается)
+func AddModerationDecision(storage *sqlx.Tx, vacID, adminID int, decision string, reason *string) error {
+	query, args, err := psql.Insert("vacancy_moderation").Columns("vacancy_id", "decision", "reason", "admin_id").
+		Values(vacID, decision, reason, adminID).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для добавления данных! error: %s", err.Error())
+	}
+	if _, err := storage.Exec(query, args...); err != nil {
+		return fmt.Errorf("ошибка при записи решения модератора! error: %s", err.Error())
+	}
+
+	query, args, err = psql.Update("vacancy").Set("moderation_reason", reason).Where(sq.Eq{"id": vacID}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	if _, err := storage.Exec(query, args...); err != nil {
+		return fmt.Errorf("ошибка при обновлении причины отказа! error: %s", err.Error())
+	}
+	return nil
+}
+
+// AddVacancyRevision сохраняет текущее содержимое вакансии как новую редакцию. Если содержимое не отличается от последней
+// редакции, то новая не создаётся и возвращается 0
+func AddVacancyRevision(storage *sqlx.Tx, vacID int, editorRole string, editorID int) (int, error) {
+	var id int
+
+	const query = `
+WITH last AS (
+    SELECT * FROM vacancy_revisions WHERE vacancy_id = $1 ORDER BY number DESC LIMIT 1
+)
+INSERT INTO vacancy_revisions (vacancy_id, number, name, salary_from, salary_to, salary_currency, salary_gross, salary_period,
+    email, phone_number, location, experience_id, about_work, editor_role, editor_id)
+SELECT v.id, COALESCE(l.number, 0) + 1, v.name, v.salary_from, v.salary_to, v.salary_currency, v.salary_gross, v.salary_period,
+    v.email, v.phone_number, v.location, v.experience_id, v.about_work, $2, $3
+FROM vacancy v LEFT JOIN last l ON true
+WHERE v.id = $1 AND (l.id IS NULL OR
+    (l.name, l.salary_from, l.salary_to, l.salary_currency, l.salary_gross, l.salary_period,
+     l.email, l.phone_number, l.location, l.experience_id, l.about_work) IS DISTINCT FROM
+    (v.name, v.salary_from, v.salary_to, v.salary_currency, v.salary_gross, v.salary_period,
+     v.email, v.phone_number, v.location, v.experience_id, v.about_work))
+RETURNING id`
+	err := storage.Get(&id, query, vacID, editorRole, editorID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("ошибка при сохранении редакции вакансии! error: %s", err.Error())
+	}
+	return id, nil
+}
+
+func vacancyRevisionsSelect() sq.SelectBuilder {
+	return psql.Select(
+		"vr.id", "vr.vacancy_id", "vr.number", "vr.name", "vr.email", "vr.phone_number", "vr.location", "vr.about_work",
+		"vr.editor_role", "vr.editor_id", "vr.created_at",
+		"vr.salary_from as \"salary.from\"", "vr.salary_to as \"salary.to\"", "vr.salary_currency as \"salary.currency\"", "vr.salary_gross as \"salary.gross\"", "vr.salary_period as \"salary.period\"",
+		"e.id as \"experience.id\"", "e.name as \"experience.name\"", "e.created_at as \"experience.created_at\"",
+	).From("vacancy_revisions vr").
+		Join("experience e ON vr.experience_id = e.id")
+}
+
+// GetVacancyRevisions возвращает редакции вакансии от новых к старым
+func GetVacancyRevisions(storage *sqlx.Tx, vacID, beforeID, limit int) ([]s.VacancyRevision, error) {
+	var result []s.VacancyRevision
+
+	builder := vacancyRevisionsSelect().Where(sq.Eq{"vr.vacancy_id": vacID})
+	if beforeID > 0 {
+		builder = builder.Where(sq.Lt{"vr.id": beforeID})
+	}
+	query, args, err := builder.OrderBy("vr.id DESC").Limit(uint64(limit)).ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+func GetVacancyRevision(storage *sqlx.Tx, vacID, revisionID int) (s.VacancyRevision, error) {
+	var result s.VacancyRevision
+
+	query, args, err := vacancyRevisionsSelect().Where(sq.Eq{"vr.vacancy_id": vacID, "vr.id": revisionID}).ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	err = storage.Get(&result, query, args...)
+	if err == sql.ErrNoRows {
+		return result, fmt.Errorf("редакция %d у этой вакансии не найдена", revisionID)
+	} else if err != nil {
+		return result, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+func GetCurrencies(storage *sqlx.Tx) ([]s.Currency, error) {
+	var result []s.Currency
+
+	query, args, err := psql.Select("code", "rate", "updated_at").From("currency_rates").OrderBy("code").ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+func GetCurrency(storage *sqlx.Tx, code string) (s.Currency, error) {
+	var result s.Currency
+
+	query, args, err := psql.Select("code", "rate", "updated_at").From("currency_rates").Where(sq.Eq{"code": code}).ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	err = storage.Get(&result, query, args...)
+	if err == sql.ErrNoRows {
+		return result, fmt.Errorf("валюты %s нету в системе", code)
+	} else if err != nil {
+		return result, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+// PutCurrency добавляет валюту или обновляет её курс
+func PutCurrency(storage *sqlx.Tx, code string, rate float64) error {
+	query, args, err := psql.Insert("currency_rates").Columns("code", "rate").Values(code, rate).
+		Suffix("ON CONFLICT (code) DO UPDATE SET rate = EXCLUDED.rate, updated_at = now()").ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для добавления данных! error: %s", err.Error())
+	}
+	if _, err := storage.Exec(query, args...); err != nil {
+		return fmt.Errorf("ошибка при выполнении скрипта на добавление данных. error: %s", err.Error())
+	}
+	return nil
+}
+
+// DeleteCurrency удаляет валюту. Валюту, в которой указана зарплата хотя бы одной вакансии, удалить нельзя
+func DeleteCurrency(storage *sqlx.Tx, code string) error {
+	query, args, err := psql.Delete("currency_rates").Where(sq.Eq{"code": code}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для удаления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении валюты, возможно она используется в вакансиях! error: %s", err.Error())
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("валюты %s нету в системе", code)
+	}
+	return nil
+}
+
+// Справочники атрибутов вакансии. Устроены так же, как experience: id, name, created_at
+const (
+	EmploymentTypes = "employment_types"
+	Schedules       = "schedules"
+	WorkFormats     = "work_formats"
+)
+
+// Атрибуты вакансии отдаются как JSON, так как их может не быть, а навыков может быть несколько
+const (
+	vacancyEmploymentType = "(SELECT json_build_object('ID', d.id, 'Name', d.name) FROM employment_types d WHERE d.id = v.employment_type_id) as employment_type"
+	vacancySchedule       = "(SELECT json_build_object('ID', d.id, 'Name', d.name) FROM schedules d WHERE d.id = v.schedule_id) as schedule"
+	vacancyWorkFormat     = "(SELECT json_build_object('ID', d.id, 'Name', d.name) FROM work_formats d WHERE d.id = v.work_format_id) as work_format"
+	vacancySkills         = "COALESCE((SELECT json_agg(json_build_object('ID', sk.id, 'Name', sk.name) ORDER BY sk.name) " +
+		"FROM vacancy_skills vs JOIN skills sk ON sk.id = vs.skill_id WHERE vs.vacancy_id = v.id), '[]') as skills"
+	resumeSkills = "COALESCE((SELECT json_agg(json_build_object('ID', sk.id, 'Name', sk.name) ORDER BY rs.sort_order, sk.name) " +
+		"FROM resume_skills rs JOIN skills sk ON sk.id = rs.skill_id WHERE rs.resume_id = r.id), '[]') as skills"
+)
+
+// Разделы резюме отдаются как JSON в порядке sort_order
+const (
+	resumeWork = "COALESCE((SELECT json_agg(json_build_object('ID', w.id, 'SortOrder', w.sort_order, 'Company', w.company, " +
+		"'Position', w.job_position, 'StartedAt', to_char(w.started_at, 'YYYY-MM-DD'), 'EndedAt', to_char(w.ended_at, 'YYYY-MM-DD'), " +
+		"'Achievements', w.achievements) ORDER BY w.sort_order, w.id) FROM resume_work w WHERE w.resume_id = r.id), '[]') as work"
+	resumeEducation = "COALESCE((SELECT json_agg(json_build_object('ID', ed.id, 'SortOrder', ed.sort_order, 'Institution', ed.institution, " +
+		"'Faculty', ed.faculty, 'Specialization', ed.specialization, 'Degree', ed.degree, 'GraduationYear', ed.graduation_year) " +
+		"ORDER BY ed.sort_order, ed.id) FROM resume_education ed WHERE ed.resume_id = r.id), '[]') as education"
+	resumeLanguages = "COALESCE((SELECT json_agg(json_build_object('ID', l.id, 'SortOrder', l.sort_order, 'Language', l.language, " +
+		"'Level', l.level) ORDER BY l.sort_order, l.id) FROM resume_languages l WHERE l.resume_id = r.id), '[]') as languages"
+)
+
+// GetDictionary возвращает все значения справочника table (EmploymentTypes, Schedules или WorkFormats)
+func GetDictionary(storage *sqlx.Tx, table string) ([]s.GetStatus, error) {
+	var result []s.GetStatus
+
+	query, args, err := psql.Select("id", "name", "created_at").From(table).OrderBy("id").ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка в получении и маппинге данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+func PostDictionaryValue(storage *sqlx.Tx, table, name string) error {
+	query, args, err := psql.Insert(table).Columns("name").Values(name).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для добавления данных! error: %s", err.Error())
+	}
+	if _, err := storage.Exec(query, args...); err != nil {
+		return fmt.Errorf("ошибка при выполнении скрипта на добавления данных. error: %s", err.Error())
+	}
+	return nil
+}
+
+// DeleteDictionaryValue удаляет значение справочника. У вакансий с этим значением атрибут становится пустым
+func DeleteDictionaryValue(storage *sqlx.Tx, table, name string) error {
+	query, args, err := psql.Delete(table).Where(sq.Eq{"name": name}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для удаления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка в исполнении SQL скрипта на удаление! error: %s", err.Error())
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("таких записей не было найдено! Перепроверьте данные и попробуйте снова")
+	}
+	return nil
+}
+
+// DictionaryHasID проверяет, что в справочнике table есть значение с таким ID
+func DictionaryHasID(storage *sqlx.Tx, table string, id int) (bool, error) {
+	var exists bool
+
+	if err := storage.Get(&exists, "SELECT EXISTS (SELECT 1 FROM "+table+" WHERE id = $1)", id); err != nil {
+		return false, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	return exists, nil
+}
+
+// SetVacancySkills заменяет навыки вакансии
+func SetVacancySkills(storage *sqlx.Tx, vacID int, names []string) error {
+	return setSkills(storage, "vacancy_skills", "vacancy_id", vacID, names)
+}
+
+// SetResumeSkills заменяет навыки резюме. Порядок навыков - как в names
+func SetResumeSkills(storage *sqlx.Tx, resumeID int, names []string) error {
+	if err := setSkills(storage, "resume_skills", "resume_id", resumeID, names); err != nil || len(names) == 0 {
+		return err
+	}
+	lower := make([]string, len(names))
+	for i, name := range names {
+		lower[i] = strings.ToLower(name)
+	}
+	// навык мог быть указан и названием, и синонимом - тогда остаётся первая позиция
+	const order = `UPDATE resume_skills rs SET sort_order = o.ord
+FROM (
+    SELECT COALESCE(sk.id, ss.skill_id) AS skill_id, min(n.ord) AS ord
+    FROM unnest($2::text[]) WITH ORDINALITY n(name, ord)
+    LEFT JOIN skills sk ON lower(sk.name) = n.name
+    LEFT JOIN skill_synonyms ss ON lower(ss.name) = n.name
+    GROUP BY 1
+) o
+WHERE rs.resume_id = $1 AND rs.skill_id = o.skill_id`
+	if _, err := storage.Exec(order, resumeID, lower); err != nil {
+		return fmt.Errorf("ошибка при сохранении порядка навыков! error: %s", err.Error())
+	}
+	return nil
+}
+
+// ErrUnknownSkill - названия нет ни среди навыков, ни среди синонимов. Новые навыки добавляет администратор через /adm/skills
+var ErrUnknownSkill = errors.New("такого навыка нету в системе, выберите навык из подсказок /skills/suggest")
+
+// UnknownSkills возвращает названия из names, которых нет ни среди навыков, ни среди синонимов
+func UnknownSkills(storage *sqlx.Tx, names []string) ([]string, error) {
+	unknown := []string{}
+	if len(names) == 0 {
+		return unknown, nil
+	}
+	const query = `SELECT n FROM unnest($1::text[]) WITH ORDINALITY u(n, ord)
+WHERE NOT EXISTS (SELECT 1 FROM skills sk WHERE lower(sk.name) = lower(n))
+AND NOT EXISTS (SELECT 1 FROM skill_synonyms ss WHERE lower(ss.name) = lower(n))
+ORDER BY ord`
+	if err := storage.Select(&unknown, query, names); err != nil {
+		return nil, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	return unknown, nil
+}
+
+// setSkills заменяет навыки записи в таблице связей table. Синоним заменяется основным навыком.
+// Если какого-то названия нет ни среди навыков, ни среди синонимов, то возвращает ErrUnknownSkill
+func setSkills(storage *sqlx.Tx, table, column string, id int, names []string) error {
+	unknown, err := UnknownSkills(storage, names)
+	if err != nil {
+		return err
+	}
+	if len(unknown) > 0 {
+		return ErrUnknownSkill
+	}
+	if _, err := storage.Exec("DELETE FROM "+table+" WHERE "+column+" = $1", id); err != nil {
+		return fmt.Errorf("ошибка при удалении навыков! error: %s", err.Error())
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	lower := make([]string, len(names))
+	for i, name := range names {
+		lower[i] = strings.ToLower(name)
+	}
+	linkSkills := "INSERT INTO " + table + " (" + column + `, skill_id)
+SELECT $1::int, id FROM skills
+WHERE lower(name) = ANY($2::text[]) OR id IN (SELECT skill_id FROM skill_synonyms WHERE lower(name) = ANY($2::text[]))
+ON CONFLICT DO NOTHING`
+	if _, err := storage.Exec(linkSkills, id, lower); err != nil {
+		return fmt.Errorf("ошибка при добавлении навыков! error: %s", err.Error())
+	}
+	return nil
+}
+
+// ErrSkillNameTaken - название уже занято другим навыком или синонимом
+var ErrSkillNameTaken = errors.New("навык или синоним с таким названием уже есть в системе")
+
+// skillsSelect - навыки вместе с синонимами и количеством вакансий и резюме, в которых они указаны
+func skillsSelect() sq.SelectBuilder {
+	return psql.Select(
+		"sk.id",
+		"sk.name",
+		"sk.created_at",
+		"COALESCE((SELECT json_agg(json_build_object('ID', ss.id, 'Name', ss.name) ORDER BY ss.name) "+
+			"FROM skill_synonyms ss WHERE ss.skill_id = sk.id), '[]') as synonyms",
+		"(SELECT count(*) FROM vacancy_skills vs WHERE vs.skill_id = sk.id) as vacancies",
+		"(SELECT count(*) FROM resume_skills rs WHERE rs.skill_id = sk.id) as resumes",
+	).From("skills sk")
+}
+
+// GetSkills возвращает навыки по порядку ID, начиная после afterID
+func GetSkills(storage *sqlx.Tx, afterID, limit int) ([]s.Skill, error) {
+	var result []s.Skill
+
+	query, args, err := skillsSelect().Where(sq.Gt{"sk.id": afterID}).OrderBy("sk.id ASC").Limit(uint64(limit)).ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err := storage.Select(&result, query, args...); err != nil {
+		return result, fmt.Errorf("ошибка в получении и маппинге данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+// GetSkill возвращает навык по ID, sql.ErrNoRows - если его нет
+func GetSkill(storage *sqlx.Tx, id int) (s.Skill, error) {
+	var result s.Skill
+
+	query, args, err := skillsSelect().Where(sq.Eq{"sk.id": id}).ToSql()
+	if err != nil {
+		return result, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	err = storage.Get(&result, query, args...)
+	if err == sql.ErrNoRows {
+		return result, err
+	} else if err != nil {
+		return result, fmt.Errorf("ошибка в получении и маппинге данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+// SuggestSkills ищет навыки, название или синоним которых начинается с prefix. Сначала идёт точное совпадение,
+// затем навыки, которые чаще указывают в вакансиях и резюме
+func SuggestSkills(storage *sqlx.Tx, prefix string, limit int) ([]s.SkillSuggestion, error) {
+	var result []s.SkillSuggestion
+
+	const query = `SELECT sk.id, sk.name, m.synonym
+FROM (
+    SELECT DISTINCT ON (skill_id) skill_id, synonym, exact
+    FROM (
+        SELECT id as skill_id, NULL::text as synonym, lower(name) = $1 as exact FROM skills WHERE lower(name) LIKE $2
+        UNION ALL
+        SELECT skill_id, name, lower(name) = $1 FROM skill_synonyms WHERE lower(name) LIKE $2
+    ) found
+    ORDER BY skill_id, exact DESC, synonym NULLS FIRST
+) m
+JOIN skills sk ON sk.id = m.skill_id
+ORDER BY m.exact DESC,
+    (SELECT count(*) FROM vacancy_skills vs WHERE vs.skill_id = sk.id) +
+    (SELECT count(*) FROM resume_skills rs WHERE rs.skill_id = sk.id) DESC,
+    sk.name
+LIMIT $3`
+	prefix = strings.ToLower(prefix)
+	pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix) + "%"
+	if err := storage.Select(&result, query, prefix, pattern, limit); err != nil {
+		return result, fmt.Errorf("ошибка в получении и маппинге данных. error: %s", err.Error())
+	}
+	return result, nil
+}
+
+// skillNameTaken проверяет, занято ли название навыком или синонимом. Навык exceptSkillID не учитывается
+func skillNameTaken(storage *sqlx.Tx, name string, exceptSkillID int) (bool, error) {
+	var taken bool
+
+	const query = `SELECT EXISTS (SELECT 1 FROM skills WHERE lower(name) = lower($1) AND id <> $2)
+    OR EXISTS (SELECT 1 FROM skill_synonyms WHERE lower(name) = lower($1))`
+	if err := storage.Get(&taken, query, name, exceptSkillID); err != nil {
+		return false, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	return taken, nil
+}
+
+// PostSkill добавляет навык и возвращает его ID. Если название занято, то возвращает ErrSkillNameTaken
+func PostSkill(storage *sqlx.Tx, name string) (int, error) {
+	var id int
+
+	if taken, err := skillNameTaken(storage, name, 0); err != nil {
+		return 0, err
+	} else if taken {
+		return 0, ErrSkillNameTaken
+	}
+	query, args, err := psql.Insert("skills").Columns("name").Values(name).Suffix("RETURNING id").ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка в создании SQL скрипта для добавления данных! error: %s", err.Error())
+	}
+	if err := storage.Get(&id, query, args...); err != nil {
+		return 0, fmt.Errorf("ошибка при выполнении скрипта на добавления данных. error: %s", err.Error())
+	}
+	return id, nil
+}
+
+// RenameSkill меняет название навыка. Если название занято, то возвращает ErrSkillNameTaken
+func RenameSkill(storage *sqlx.Tx, id int, name string) error {
+	if taken, err := skillNameTaken(storage, name, id); err != nil {
+		return err
+	} else if taken {
+		return ErrSkillNameTaken
+	}
+	query, args, err := psql.Update("skills").Set("name", name).Where(sq.Eq{"id": id}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении скрипта на обновление данных. error: %s", err.Error())
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteSkill удаляет навык вместе с синонимами. Из вакансий и резюме навык пропадает
+func DeleteSkill(storage *sqlx.Tx, id int) error {
+	query, args, err := psql.Delete("skills").Where(sq.Eq{"id": id}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для удаления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка в исполнении SQL скрипта на удаление! error: %s", err.Error())
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// PostSkillSynonym добавляет синоним навыку и возвращает его ID. Если название занято, то возвращает ErrSkillNameTaken
+func PostSkillSynonym(storage *sqlx.Tx, skillID int, name string) (int, error) {
+	var id int
+
+	if taken, err := skillNameTaken(storage, name, 0); err != nil {
+		return 0, err
+	} else if taken {
+		return 0, ErrSkillNameTaken
+	}
+	query, args, err := psql.Insert("skill_synonyms").Columns("skill_id", "name").Values(skillID, name).Suffix("RETURNING id").ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка в создании SQL скрипта для добавления данных! error: %s", err.Error())
+	}
+	if err := storage.Get(&id, query, args...); err != nil {
+		return 0, fmt.Errorf("ошибка при выполнении скрипта на добавления данных. error: %s", err.Error())
+	}
+	return id, nil
+}
+
+// DeleteSkillSynonym удаляет синоним. Навыки вакансий и резюме не меняются
+func DeleteSkillSynonym(storage *sqlx.Tx, id int) error {
+	query, args, err := psql.Delete("skill_synonyms").Where(sq.Eq{"id": id}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для удаления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка в исполнении SQL скрипта на удаление! error: %s", err.Error())
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MergeSkills объединяет навык fromID с навыком toID: вакансии и резюме переходят на toID, синонимы fromID
+// становятся синонимами toID, а название fromID - ещё одним синонимом. Сам навык fromID удаляется
+func MergeSkills(storage *sqlx.Tx, fromID, toID int) error {
+	moves := []string{
+		"INSERT INTO vacancy_skills (vacancy_id, skill_id) SELECT vacancy_id, $2 FROM vacancy_skills WHERE skill_id = $1 ON CONFLICT DO NOTHING",
+		// навык остаётся на том же месте в резюме
+		"INSERT INTO resume_skills (resume_id, skill_id, sort_order) SELECT resume_id, $2, sort_order FROM resume_skills WHERE skill_id = $1 ON CONFLICT DO NOTHING",
+		"UPDATE skill_synonyms SET skill_id = $2 WHERE skill_id = $1",
+	}
+	for _, query := range moves {
+		if _, err := storage.Exec(query, fromID, toID); err != nil {
+			return fmt.Errorf("ошибка при переносе навыка! error: %s", err.Error())
+		}
+	}
+
+	var name string
+	err := storage.Get(&name, "DELETE FROM skills WHERE id = $1 RETURNING name", fromID)
+	if err == sql.ErrNoRows {
+		return err
+	} else if err != nil {
+		return fmt.Errorf("ошибка в исполнении SQL скрипта на удаление! error: %s", err.Error())
+	}
+	if _, err := storage.Exec("INSERT INTO skill_synonyms (skill_id, name) VALUES ($1, $2)", toID, name); err != nil {
+		return fmt.Errorf("ошибка при добавлении синонима! error: %s", err.Error())
+	}
+	return nil
+}
+
+// Ошибки разделов резюме
+var (
+	ErrResumeLanguageExists = errors.New("этот язык уже указан в резюме")
+	ErrResumeSkillExists    = errors.New("этот навык уже указан в резюме")
+	ErrResumeOrderMismatch  = errors.New("список ID должен содержать все записи раздела резюме ровно по одному разу")
+)
+
+// candidateResumes - резюме соискателя, плейсхолдер - ID соискателя
+const candidateResumes = "resume_id IN (SELECT id FROM resume WHERE candidate_id = ?)"
+
+// ResumeOwnedBy проверяет, что резюме принадлежит соискателю
+func ResumeOwnedBy(storage *sqlx.Tx, resumeID, uid int) (bool, error) {
+	var exists bool
+	query, args, err := psql.Select("count(id) > 0").From("resume").Where(sq.Eq{"id": resumeID, "candidate_id": uid}).ToSql()
+	if err != nil {
+		return false, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err = storage.Get(&exists, query, args...); err != nil {
+		return false, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	return exists, nil
+}
+
+// CountResumeItems возвращает количество записей раздела резюме
+func CountResumeItems(storage *sqlx.Tx, table string, resumeID int) (int, error) {
+	var amount int
+	query, args, err := psql.Select("count(*)").From(table).Where(sq.Eq{"resume_id": resumeID}).ToSql()
+	if err != nil {
+		return -1, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err = storage.Get(&amount, query, args...); err != nil {
+		return -1, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	return amount, nil
+}
+
+// nextSortOrder - позиция для новой записи в конце раздела
+func nextSortOrder(table string, resumeID int) sq.Sqlizer {
+	return sq.Expr("(SELECT COALESCE(max(sort_order), 0) + 1 FROM "+table+" WHERE resume_id = ?)", resumeID)
+}
+
+// insertResumeItem добавляет запись в раздел резюме и возвращает её ID
+func insertResumeItem(storage *sqlx.Tx, builder sq.InsertBuilder) (int, error) {
+	var id int
+	query, args, err := builder.Suffix("RETURNING id").ToSql()
+	if err != nil {
+		return -1, fmt.Errorf("ошибка в создании SQL скрипта для добавления данных! error: %s", err.Error())
+	}
+	if err = storage.Get(&id, query, args...); err != nil {
+		return -1, fmt.Errorf("ошибка при выполнении скрипта на добавление данных. error: %s", err.Error())
+	}
+	return id, nil
+}
+
+// updateResumeItem обновляет запись раздела в одном из резюме соискателя uid
+func updateResumeItem(storage *sqlx.Tx, builder sq.UpdateBuilder, id, uid int) error {
+	query, args, err := builder.Where(sq.Eq{"id": id}).Where(candidateResumes, uid).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для обновления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении скрипта на обновление данных. error: %s", err.Error())
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func AddResumeWork(storage *sqlx.Tx, req s.RequestResumeWork) (int, error) {
+	return insertResumeItem(storage, psql.Insert("resume_work").
+		Columns("resume_id", "sort_order", "company", "job_position", "started_at", "ended_at", "achievements").
+		Values(req.ResumeID, nextSortOrder("resume_work", req.ResumeID), req.Company, req.Position, req.StartedAt, req.EndedAt, req.Achievements))
+}
+
+func UpdateResumeWork(storage *sqlx.Tx, id, uid int, req s.RequestResumeWork) error {
+	return updateResumeItem(storage, psql.Update("resume_work").
+		Set("company", req.Company).
+		Set("job_position", req.Position).
+		Set("started_at", req.StartedAt).
+		Set("ended_at", req.EndedAt).
+		Set("achievements", req.Achievements), id, uid)
+}
+
+func AddResumeEducation(storage *sqlx.Tx, req s.RequestResumeEducation) (int, error) {
+	return insertResumeItem(storage, psql.Insert("resume_education").
+		Columns("resume_id", "sort_order", "institution", "faculty", "specialization", "degree", "graduation_year").
+		Values(req.ResumeID, nextSortOrder("resume_education", req.ResumeID), req.Institution, req.Faculty, req.Specialization, req.Degree, req.GraduationYear))
+}
+
+func UpdateResumeEducation(storage *sqlx.Tx, id, uid int, req s.RequestResumeEducation) error {
+	return updateResumeItem(storage, psql.Update("resume_education").
+		Set("institution", req.Institution).
+		Set("faculty", req.Faculty).
+		Set("specialization", req.Specialization).
+		Set("degree", req.Degree).
+		Set("graduation_year", req.GraduationYear), id, uid)
+}
+
+// resumeLanguageTaken проверяет, указан ли язык в резюме в другой записи
+func resumeLanguageTaken(storage *sqlx.Tx, resumeID int, language string, exceptID int) (bool, error) {
+	var taken bool
+	query, args, err := psql.Select("count(id) > 0").From("resume_languages").
+		Where(sq.Eq{"resume_id": resumeID}).Where("lower(language) = lower(?)", language).Where(sq.NotEq{"id": exceptID}).ToSql()
+	if err != nil {
+		return false, fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err = storage.Get(&taken, query, args...); err != nil {
+		return false, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	return taken, nil
+}
+
+func AddResumeLanguage(storage *sqlx.Tx, req s.RequestResumeLanguage) (int, error) {
+	taken, err := resumeLanguageTaken(storage, req.ResumeID, req.Language, 0)
+	if err != nil {
+		return -1, err
+	}
+	if taken {
+		return -1, ErrResumeLanguageExists
+	}
+	return insertResumeItem(storage, psql.Insert("resume_languages").
+		Columns("resume_id", "sort_order", "language", "level").
+		Values(req.ResumeID, nextSortOrder("resume_languages", req.ResumeID), req.Language, req.Level))
+}
+
+func UpdateResumeLanguage(storage *sqlx.Tx, id, uid int, req s.RequestResumeLanguage) error {
+	var resumeID int
+	query, args, err := psql.Select("resume_id").From("resume_languages").Where(sq.Eq{"id": id}).Where(candidateResumes, uid).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для получения данных! error: %s", err.Error())
+	}
+	if err = storage.Get(&resumeID, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return err
+		}
+		return fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	taken, err := resumeLanguageTaken(storage, resumeID, req.Language, id)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrResumeLanguageExists
+	}
+	return updateResumeItem(storage, psql.Update("resume_languages").
+		Set("language", req.Language).
+		Set("level", req.Level), id, uid)
+}
+
+// DeleteResumeItem удаляет запись раздела table из резюме соискателя uid
+func DeleteResumeItem(storage *sqlx.Tx, table string, id, uid int) error {
+	query, args, err := psql.Delete(table).Where(sq.Eq{"id": id}).Where(candidateResumes, uid).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для удаления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении скрипта на удаление данных. error: %s", err.Error())
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ReorderResumeItems расставляет записи раздела в порядке ids. column - идентификатор записи в таблице раздела,
+// ids должны совпадать с записями раздела полностью
+func ReorderResumeItems(storage *sqlx.Tx, table, column string, resumeID int, ids []int) error {
+	var matched bool
+	check := "SELECT count(*) = $2 AND count(*) FILTER (WHERE " + column + " = ANY($3::int[])) = $2 FROM " + table + " WHERE resume_id = $1"
+	if err := storage.Get(&matched, check, resumeID, len(ids), ids); err != nil {
+		return fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	if !matched {
+		return ErrResumeOrderMismatch
+	}
+	order := "UPDATE " + table + " t SET sort_order = o.ord FROM unnest($2::int[]) WITH ORDINALITY o(id, ord) " +
+		"WHERE t.resume_id = $1 AND t." + column + " = o.id"
+	if _, err := storage.Exec(order, resumeID, ids); err != nil {
+		return fmt.Errorf("ошибка при выполнении скрипта на обновление данных. error: %s", err.Error())
+	}
+	return nil
+}
+
+// AddResumeSkill добавляет навык в конец списка навыков резюме. Синоним заменяется основным навыком,
+// для неизвестного названия возвращает ErrUnknownSkill. Возвращает ID навыка
+func AddResumeSkill(storage *sqlx.Tx, resumeID int, name string) (int, error) {
+	var skillID int
+	const findSkill = `SELECT id FROM skills WHERE lower(name) = lower($1)
+UNION ALL SELECT skill_id FROM skill_synonyms WHERE lower(name) = lower($1)
+LIMIT 1`
+	if err := storage.Get(&skillID, findSkill, name); err == sql.ErrNoRows {
+		return -1, ErrUnknownSkill
+	} else if err != nil {
+		return -1, fmt.Errorf("ошибка при выполнении скрипта на получение данных. error: %s", err.Error())
+	}
+	query, args, err := psql.Insert("resume_skills").Columns("resume_id", "skill_id", "sort_order").
+		Values(resumeID, skillID, nextSortOrder("resume_skills", resumeID)).Suffix("ON CONFLICT DO NOTHING").ToSql()
+	if err != nil {
+		return -1, fmt.Errorf("ошибка в создании SQL скрипта для добавления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return -1, fmt.Errorf("ошибка при выполнении скрипта на добавление данных. error: %s", err.Error())
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return -1, ErrResumeSkillExists
+	}
+	return skillID, nil
+}
+
+func DeleteResumeSkill(storage *sqlx.Tx, resumeID, skillID int) error {
+	query, args, err := psql.Delete("resume_skills").Where(sq.Eq{"resume_id": resumeID, "skill_id": skillID}).ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка в создании SQL скрипта для удаления данных! error: %s", err.Error())
+	}
+	result, err := storage.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении скрипта на удаление данных. error: %s", err.Error())
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
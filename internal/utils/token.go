@@ -12,9 +12,9 @@ import (
 var secretKey = []byte(os.Getenv("JWT_SECRET_KEY_USER")) // Должен быть в конфиге!
 
 type ClaimToRecover struct {
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	expiresAt time.Time `json:expires_at`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	expiresAt time.Time
 	jwt.RegisteredClaims
 }
 
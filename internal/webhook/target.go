@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// resolveTimeout - сколько ждём DNS при проверке адреса вебхука
+const resolveTimeout = 5 * time.Second
+
+// blockedPrefixes - сети, в которые вебхуки не ходят, помимо loopback, частных и link-local адресов:
+// иначе работодатель мог бы через вебхук обращаться к внутренним сервисам
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),         // "эта" сеть
+	netip.MustParsePrefix("100.64.0.0/10"),     // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),      // служебные адреса IETF
+	netip.MustParsePrefix("198.18.0.0/15"),     // сети для тестов производительности
+	netip.MustParsePrefix("240.0.0.0/4"),       // зарезервировано, включая 255.255.255.255
+	netip.MustParsePrefix("64:ff9b::/96"),      // NAT64, через него доступны те же IPv4 адреса
+	netip.MustParsePrefix("fd00:ec2::254/128"), // метаданные облака по IPv6
+}
+
+// ErrForbiddenTarget - адрес вебхука ведёт во внутреннюю сеть
+var ErrForbiddenTarget = fmt.Errorf("адрес вебхука не может вести на локальные, внутренние или служебные адреса")
+
+// PrivateTargetsAllowed - разрешены ли вебхуки на loopback и частные адреса (WEBHOOK_ALLOW_PRIVATE=on).
+// Только для локальной разработки, например чтобы зарегистрировать cmd/webhook-receiver на localhost
+func PrivateTargetsAllowed() bool {
+	switch strings.ToLower(os.Getenv("WEBHOOK_ALLOW_PRIVATE")) {
+	case "on", "true", "1":
+		return true
+	}
+	return false
+}
+
+// AllowedIP проверяет, что на адрес можно отправлять вебхуки. Loopback и частные адреса
+// разрешены только вместе с WEBHOOK_ALLOW_PRIVATE, остальные служебные сети запрещены всегда
+func AllowedIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	if (ip.IsLoopback() || ip.IsPrivate()) && !PrivateTargetsAllowed() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL проверяет адрес вебхука при регистрации: полный http(s) адрес, все IP которого разрешены.
+// DNS может поменяться после проверки, поэтому адрес ещё раз проверяется при подключении (см. NewClient)
+func CheckURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("URL должен быть полным адресом http:// или https://")
+	}
+	if u.User != nil {
+		return fmt.Errorf("URL не должен содержать логин и пароль")
+	}
+	if ip, err := netip.ParseAddr(u.Hostname()); err == nil {
+		if !AllowedIP(ip) {
+			return ErrForbiddenTarget
+		}
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("не удалось найти адрес %s", u.Hostname())
+	}
+	for _, ip := range addrs {
+		if !AllowedIP(ip) {
+			return ErrForbiddenTarget
+		}
+	}
+	return nil
+}
+
+// NewClient - HTTP клиент для доставки вебхуков. Запрещённые адреса проверяются в момент подключения,
+// уже после DNS, поэтому подмена DNS после регистрации вебхука не помогает. Редиректы не выполняются,
+// прокси из окружения не используется
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !AllowedIP(addr.Addr()) {
+				return ErrForbiddenTarget
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 2,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
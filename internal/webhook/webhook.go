@@ -0,0 +1,275 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	s "main.go/internal/api/Struct"
+	"main.go/internal/retry"
+	sqlp "main.go/internal/storage/postSQL"
+)
+
+// События, на которые работодатель может подписать вебхук
+const (
+	EventResponseCreated     = "response_created"
+	EventResponseWithdrawn   = "response_withdrawn"
+	EventVacancyStateChanged = "vacancy_state_changed"
+)
+
+var Events = []string{EventResponseCreated, EventResponseWithdrawn, EventVacancyStateChanged}
+
+// Заголовки запроса вебхука.
+// SignatureHeader имеет вид "t=<unix время>,v1=<hex HMAC-SHA256>", подписывается строка "<unix время>.<тело запроса>"
+const (
+	SignatureHeader = "X-Workall-Signature"
+	EventHeader     = "X-Workall-Event"
+	DeliveryHeader  = "X-Workall-Delivery"
+)
+
+const (
+	batchSize = 20
+	pollEvery = 5 * time.Second
+	// lease - на сколько доставки пачки закрепляются за диспетчером. Должно хватать на отправку всей пачки
+	lease = 5 * time.Minute
+	// sendTimeout - сколько ждём ответа получателя
+	sendTimeout = 10 * time.Second
+	// drainLimit - сколько байт ответа дочитываем, чтобы соединение можно было переиспользовать
+	drainLimit = 4096
+)
+
+// Payload - тело запроса, которое получает адрес вебхука
+type Payload struct {
+	ID        int             `json:"ID"`
+	Event     string          `json:"Event"`
+	CreatedAt time.Time       `json:"CreatedAt"`
+	Data      json.RawMessage `json:"Data"`
+}
+
+// IsEvent проверяет, что на событие можно подписаться
+func IsEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSecret генерирует ключ для подписи запросов вебхука
+func NewSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+// Sign возвращает значение заголовка SignatureHeader для тела запроса
+func Sign(secret string, timestamp int64, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, signature(secret, timestamp, body))
+}
+
+func signature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет заголовок SignatureHeader. Подписи старше tolerance не принимаются, чтобы запрос нельзя было повторить
+func Verify(secret, header string, body []byte, tolerance time.Duration) error {
+	var timestamp int64
+	var received string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp, _ = strconv.ParseInt(value, 10, 64)
+		case "v1":
+			received = value
+		}
+	}
+	if timestamp == 0 || received == "" {
+		return fmt.Errorf("неверный формат подписи: %s", header)
+	}
+	if tolerance > 0 && time.Since(time.Unix(timestamp, 0)) > tolerance {
+		return fmt.Errorf("подпись устарела")
+	}
+	if !hmac.Equal([]byte(signature(secret, timestamp, body)), []byte(received)) {
+		return fmt.Errorf("подпись не совпадает")
+	}
+	return nil
+}
+
+// Enqueue создаёт доставки события для всех вебхуков работодателя, подписанных на него.
+// Вызывается в транзакции запроса, поэтому событие уйдёт, только если изменение сохранится
+func Enqueue(tx *sqlx.Tx, empID int, event string, data any) error {
+	return sqlp.EnqueueWebhookEvent(tx, empID, event, data)
+}
+
+// Dispatcher разбирает таблицу webhook_deliveries и отправляет события на адреса вебхуков.
+// Ответ 2xx считается успешной доставкой, остальное повторяется с экспоненциальной задержкой,
+// после maxAttempts попыток доставка уходит в статус 'dead'
+type Dispatcher struct {
+	storage     *sqlx.DB
+	client      *http.Client
+	maxAttempts int
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+func NewDispatcher(storage *sqlx.DB, client *http.Client, maxAttempts int) *Dispatcher {
+	if client == nil {
+		client = NewClient(sendTimeout)
+	}
+	return &Dispatcher{
+		storage:     storage,
+		client:      client,
+		maxAttempts: maxAttempts,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Run опрашивает журнал доставок, пока не будет вызван Close
+func (d *Dispatcher) Run() {
+	defer close(d.done)
+	ticker := time.NewTicker(pollEvery)
+	defer ticker.Stop()
+
+	for {
+		for {
+			sent, err := d.dispatch()
+			if err != nil {
+				log.Printf("failed to dispatch webhooks: %v", err)
+				break
+			}
+			if sent < batchSize {
+				break
+			}
+		}
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close останавливает опрос и дожидается окончания текущей пачки доставок
+func (d *Dispatcher) Close() {
+	close(d.stop)
+	<-d.done
+}
+
+type result struct {
+	code int
+	err  error
+}
+
+// dispatch забирает пачку доставок короткой транзакцией, отправляет их без открытой транзакции
+// и записывает результат каждой доставки отдельной транзакцией
+func (d *Dispatcher) dispatch() (int, error) {
+	var deliveries []s.PendingWebhookDelivery
+	err := d.inTx(func(tx *sqlx.Tx) error {
+		var err error
+		deliveries, err = sqlp.ClaimWebhookDeliveries(tx, batchSize, lease)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	results := make([]result, len(deliveries))
+	var wg sync.WaitGroup
+	for i, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			code, err := d.send(delivery)
+			results[i] = result{code: code, err: err}
+		}()
+	}
+	wg.Wait()
+
+	for i, delivery := range deliveries {
+		// если записать результат не удалось, доставка вернётся в очередь, когда истечёт аренда
+		if err := d.inTx(func(tx *sqlx.Tx) error { return d.finish(tx, delivery, results[i]) }); err != nil {
+			return 0, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) finish(tx *sqlx.Tx, delivery s.PendingWebhookDelivery, res result) error {
+	if res.err == nil {
+		return sqlp.MarkWebhookDelivered(tx, delivery.ID, res.code)
+	}
+	attempts := delivery.Attempts + 1
+	dead := attempts >= d.maxAttempts
+	if dead {
+		log.Printf("webhook delivery %d to %s moved to dead letters after %d attempts: %v", delivery.ID, delivery.URL, attempts, res.err)
+	} else {
+		log.Printf("failed to deliver webhook %d to %s (attempt %d): %v", delivery.ID, delivery.URL, attempts, res.err)
+	}
+	return sqlp.MarkWebhookFailed(tx, delivery.ID, res.code, res.err.Error(), retry.Backoff(attempts), dead)
+}
+
+func (d *Dispatcher) inTx(fn func(tx *sqlx.Tx) error) error {
+	tx, err := d.storage.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// send отправляет одну доставку и возвращает HTTP код ответа (0, если ответа не было)
+func (d *Dispatcher) send(delivery s.PendingWebhookDelivery) (int, error) {
+	body, err := json.Marshal(Payload{
+		ID:        delivery.ID,
+		Event:     delivery.Event,
+		CreatedAt: delivery.CreatedAt,
+		Data:      delivery.Payload,
+	})
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "WorkAll-Webhooks/1.0")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, strconv.Itoa(delivery.ID))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, time.Now().Unix(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, drainLimit))
+	// тело ответа в журнал не попадает: журнал виден работодателю, а ответить мог чужой сервис
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("получатель ответил кодом %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	const secret = "whsec_test"
+	body := []byte(`{"Event":"response.created"}`)
+	now := time.Now().Unix()
+	old := time.Now().Add(-time.Hour).Unix()
+
+	tests := []struct {
+		name      string
+		secret    string
+		header    string
+		body      []byte
+		tolerance time.Duration
+		wantErr   bool
+	}{
+		{name: "верная подпись", secret: secret, header: Sign(secret, now, body), body: body, tolerance: 5 * time.Minute},
+		{name: "пробелы в заголовке", secret: secret, header: fmt.Sprintf("t=%d, v1=%s", now, signature(secret, now, body)), body: body, tolerance: 5 * time.Minute},
+		{name: "старая подпись без ограничения срока", secret: secret, header: Sign(secret, old, body), body: body},
+		{name: "подпись устарела", secret: secret, header: Sign(secret, old, body), body: body, tolerance: 5 * time.Minute, wantErr: true},
+		{name: "другой ключ", secret: "whsec_other", header: Sign(secret, now, body), body: body, wantErr: true},
+		{name: "тело изменено", secret: secret, header: Sign(secret, now, body), body: []byte(`{}`), wantErr: true},
+		{name: "подменено время", secret: secret, header: fmt.Sprintf("t=%d,v1=%s", now+1, signature(secret, now, body)), body: body, wantErr: true},
+		{name: "нет подписи", secret: secret, header: fmt.Sprintf("t=%d", now), body: body, wantErr: true},
+		{name: "нет времени", secret: secret, header: "v1=" + signature(secret, now, body), body: body, wantErr: true},
+		{name: "пустой заголовок", secret: secret, header: "", body: body, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.header, tt.body, tt.tolerance)
+			if tt.wantErr != (err != nil) {
+				t.Errorf("Verify(%q) = %v", tt.header, err)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	got := Sign("secret", 1700000000, []byte("body"))
+	want := "t=1700000000,v1=" + signature("secret", 1700000000, []byte("body"))
+	if got != want {
+		t.Errorf("Sign = %q, ожидалось %q", got, want)
+	}
+	if Sign("secret", 1700000000, []byte("body")) != got {
+		t.Error("Sign для одних и тех же данных вернул разные подписи")
+	}
+}